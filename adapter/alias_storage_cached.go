@@ -0,0 +1,71 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+
+	"github.com/truewebber/link-shortener/domain/alias"
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+type cachedAliasStorage struct {
+	storage alias.Storage
+	lookups gokitmetrics.Counter
+	aliases *lruCache[string, *alias.Alias]
+	options CacheOptions
+}
+
+// NewCachedAliasStorage caches aliases by value, unknown values are cached for NegativeTTL.
+func NewCachedAliasStorage(storage alias.Storage, options CacheOptions, lookups gokitmetrics.Counter) alias.Storage {
+	return &cachedAliasStorage{
+		storage: storage,
+		lookups: lookups,
+		aliases: newLRUCache[string, *alias.Alias](options.Size),
+		options: options,
+	}
+}
+
+func (s *cachedAliasStorage) CreateWithLink(ctx context.Context, l *link.Link, a *alias.Alias) error {
+	if err := s.storage.CreateWithLink(ctx, l, a); err != nil {
+		return fmt.Errorf("create alias with link in storage: %w", err)
+	}
+
+	s.aliases.Remove(a.Value)
+
+	return nil
+}
+
+func (s *cachedAliasStorage) ByValue(ctx context.Context, value string) (*alias.Alias, error) {
+	if cached, ok := s.aliases.Get(value); ok {
+		s.lookups.With(cacheResultLabel, cacheResultHit).Add(1)
+
+		if cached == nil {
+			return nil, alias.ErrNotFound
+		}
+
+		a := *cached
+
+		return &a, nil
+	}
+
+	s.lookups.With(cacheResultLabel, cacheResultMiss).Add(1)
+
+	a, err := s.storage.ByValue(ctx, value)
+	if errors.Is(err, alias.ErrNotFound) {
+		s.aliases.Add(value, nil, s.options.NegativeTTL)
+
+		return nil, alias.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get alias from storage: %w", err)
+	}
+
+	cached := *a
+	s.aliases.Add(value, &cached, s.options.TTL)
+
+	return a, nil
+}
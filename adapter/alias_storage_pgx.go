@@ -0,0 +1,82 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pgxpkg "github.com/truewebber/gopkg/pgx"
+
+	"github.com/truewebber/link-shortener/domain/alias"
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+type aliasStoragePgx struct {
+	pool  *pgxpool.Pool
+	links *linkStoragePGX
+}
+
+func NewAliasStoragePgx(pool *pgxpool.Pool) alias.Storage {
+	return &aliasStoragePgx{
+		pool:  pool,
+		links: &linkStoragePGX{pool: pool},
+	}
+}
+
+const insertAliasRow = `INSERT INTO public.url_aliases (url_id, alias, created_at, deleted)
+VALUES ($1, $2, CURRENT_TIMESTAMP, FALSE)
+RETURNING id, created_at;`
+
+func (s *aliasStoragePgx) CreateWithLink(ctx context.Context, l *link.Link, a *alias.Alias) error {
+	txOpts := &pgx.TxOptions{
+		IsoLevel: pgx.Serializable,
+	}
+
+	doErr := pgxpkg.DoAtomicWithOptions(ctx, s.pool, txOpts, func(doCtx context.Context, tx pgx.Tx) error {
+		if err := s.links.insertLink(doCtx, tx, l); err != nil {
+			return fmt.Errorf("insert link: %w", err)
+		}
+
+		a.LinkID = l.ID
+
+		err := tx.QueryRow(doCtx, insertAliasRow, a.LinkID, a.Value).Scan(&a.ID, &a.CreatedAt)
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolationCode {
+			return alias.ErrAlreadyExists
+		}
+
+		if err != nil {
+			return fmt.Errorf("insert alias row: %w", err)
+		}
+
+		return nil
+	})
+	if doErr != nil {
+		return fmt.Errorf("create alias with link on tx: %w", doErr)
+	}
+
+	return nil
+}
+
+const selectAliasByValue = `SELECT id, url_id, alias, created_at
+FROM public.url_aliases
+WHERE alias = $1 AND NOT deleted;`
+
+func (s *aliasStoragePgx) ByValue(ctx context.Context, value string) (*alias.Alias, error) {
+	a := &alias.Alias{}
+
+	err := s.pool.QueryRow(ctx, selectAliasByValue, value).Scan(&a.ID, &a.LinkID, &a.Value, &a.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, alias.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("select alias by value: %w", err)
+	}
+
+	return a, nil
+}
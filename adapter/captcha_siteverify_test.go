@@ -0,0 +1,154 @@
+package adapter_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/adapter"
+	"github.com/truewebber/link-shortener/domain/captcha"
+)
+
+func TestSiteVerifyValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		newValidator func(verifyURL string) captcha.Validator
+		body         map[string]any
+		wantErr      error
+		name         string
+		response     string
+		status       int
+		wantFail     bool
+	}{
+		{
+			name: "Accept a human reCAPTCHA response",
+			newValidator: func(verifyURL string) captcha.Validator {
+				return adapter.NewGoogleCaptchaV3Validator(verifyURL, "secret", []string{"report_link"}, 0.5, log.NewLogger())
+			},
+			body: map[string]any{
+				"success":      true,
+				"challenge_ts": "2026-10-17T10:00:00.123Z",
+				"action":       "report_link",
+				"score":        0.9,
+			},
+			response: "widget-response",
+			status:   http.StatusOK,
+		},
+		{
+			name: "Return error if reCAPTCHA scores the response below the threshold",
+			newValidator: func(verifyURL string) captcha.Validator {
+				return adapter.NewGoogleCaptchaV3Validator(verifyURL, "secret", []string{"report_link"}, 0.5, log.NewLogger())
+			},
+			body:     map[string]any{"success": true, "action": "report_link", "score": 0.1},
+			response: "widget-response",
+			status:   http.StatusOK,
+			wantErr:  captcha.ErrNotHuman,
+		},
+		{
+			name: "Return error if reCAPTCHA reports an action that is not allowed",
+			newValidator: func(verifyURL string) captcha.Validator {
+				return adapter.NewGoogleCaptchaV3Validator(verifyURL, "secret", []string{"report_link"}, 0.5, log.NewLogger())
+			},
+			body:     map[string]any{"success": true, "action": "login", "score": 0.9},
+			response: "widget-response",
+			status:   http.StatusOK,
+			wantErr:  captcha.ErrActionInvalid,
+		},
+		{
+			name: "Accept a solved hCaptcha response",
+			newValidator: func(verifyURL string) captcha.Validator {
+				return adapter.NewHCaptchaValidator(verifyURL, "secret", log.NewLogger())
+			},
+			body: map[string]any{
+				"success":      true,
+				"challenge_ts": "2026-10-17T10:00:00Z",
+				"hostname":     "short.example",
+			},
+			response: "widget-response",
+			status:   http.StatusOK,
+		},
+		{
+			name: "Return error if hCaptcha reports the challenge failed",
+			newValidator: func(verifyURL string) captcha.Validator {
+				return adapter.NewHCaptchaValidator(verifyURL, "secret", log.NewLogger())
+			},
+			body:     map[string]any{"success": false, "error-codes": []string{"timeout-or-duplicate"}},
+			response: "widget-response",
+			status:   http.StatusOK,
+			wantErr:  captcha.ErrUnsuccessful,
+		},
+		{
+			name: "Accept a solved Turnstile response",
+			newValidator: func(verifyURL string) captcha.Validator {
+				return adapter.NewTurnstileValidator(verifyURL, "secret", []string{"report_link"}, log.NewLogger())
+			},
+			body:     map[string]any{"success": true, "action": "report_link"},
+			response: "widget-response",
+			status:   http.StatusOK,
+		},
+		{
+			name: "Return error if Turnstile reports an action that is not allowed",
+			newValidator: func(verifyURL string) captcha.Validator {
+				return adapter.NewTurnstileValidator(verifyURL, "secret", []string{"report_link"}, log.NewLogger())
+			},
+			body:     map[string]any{"success": true, "action": "login"},
+			response: "widget-response",
+			status:   http.StatusOK,
+			wantErr:  captcha.ErrActionInvalid,
+		},
+		{
+			name: "Accept any Turnstile action when no actions are allowed explicitly",
+			newValidator: func(verifyURL string) captcha.Validator {
+				return adapter.NewTurnstileValidator(verifyURL, "secret", nil, log.NewLogger())
+			},
+			body:     map[string]any{"success": true, "action": "login"},
+			response: "widget-response",
+			status:   http.StatusOK,
+		},
+		{
+			name: "Return error if the response is not the one the widget issued",
+			newValidator: func(verifyURL string) captcha.Validator {
+				return adapter.NewTurnstileValidator(verifyURL, "secret", nil, log.NewLogger())
+			},
+			body:     map[string]any{"success": true, "action": "report_link"},
+			response: "forged-response",
+			status:   http.StatusOK,
+			wantErr:  captcha.ErrUnsuccessful,
+		},
+		{
+			name: "Return error if siteverify does not answer 200",
+			newValidator: func(verifyURL string) captcha.Validator {
+				return adapter.NewHCaptchaValidator(verifyURL, "secret", log.NewLogger())
+			},
+			response: "widget-response",
+			status:   http.StatusServiceUnavailable,
+			wantFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newSiteVerifyServer(t, "secret", "widget-response", tt.status, tt.body)
+
+			err := tt.newValidator(server.URL).Validate(context.Background(), tt.response)
+
+			if tt.wantFail {
+				if err == nil {
+					t.Fatal("Validate() error = nil, want an error")
+				}
+
+				return
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
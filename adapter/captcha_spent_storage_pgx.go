@@ -0,0 +1,56 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/truewebber/link-shortener/domain/captcha"
+)
+
+type captchaSpentStoragePgx struct {
+	pool *pgxpool.Pool
+}
+
+func NewCaptchaSpentStoragePgx(pool *pgxpool.Pool) captcha.SpentStorage {
+	return &captchaSpentStoragePgx{
+		pool: pool,
+	}
+}
+
+const insertSpentChallenge = `INSERT INTO captcha_challenges (challenge, expires_at) VALUES ($1, $2)
+		ON CONFLICT (challenge) DO NOTHING;`
+
+func (s *captchaSpentStoragePgx) Spend(ctx context.Context, challenge string, expiresAt time.Time) error {
+	cmd, err := s.pool.Exec(ctx, insertSpentChallenge, challenge, expiresAt)
+	if err != nil {
+		return fmt.Errorf("insert spent challenge: %w", err)
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return captcha.ErrAlreadySpent
+	}
+
+	return nil
+}
+
+const deleteExpiredChallengesBatch = `
+		DELETE FROM captcha_challenges
+		WHERE challenge IN (
+			SELECT challenge FROM captcha_challenges
+			WHERE expires_at <= CURRENT_TIMESTAMP
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		);`
+
+func (s *captchaSpentStoragePgx) PurgeExpired(ctx context.Context, limit uint32) (uint32, error) {
+	cmd, err := s.pool.Exec(ctx, deleteExpiredChallengesBatch, limit)
+	if err != nil {
+		return 0, fmt.Errorf("exec delete expired challenges: %w", err)
+	}
+
+	//nolint:gosec // rows affected is bounded by limit
+	return uint32(cmd.RowsAffected()), nil
+}
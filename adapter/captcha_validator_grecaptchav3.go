@@ -2,15 +2,8 @@ package adapter
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
 	"slices"
-	"strings"
-	"time"
 
 	"github.com/truewebber/gopkg/log"
 
@@ -18,30 +11,30 @@ import (
 )
 
 type googleCaptchaV3 struct {
-	logger         log.Logger
-	httpClient     *http.Client
-	secret         string
+	verifier       *siteVerifier
 	allowedActions []string
 	threshold      float32
 }
 
+const GoogleCaptchaV3VerifyURL = "https://www.google.com/recaptcha/api/siteverify"
+
+// NewGoogleCaptchaV3Validator sends tokens to GoogleCaptchaV3VerifyURL when verifyURL is empty.
 func NewGoogleCaptchaV3Validator(
+	verifyURL string,
 	secret string,
 	allowedActions []string,
 	threshold float32,
 	logger log.Logger,
 ) captcha.Validator {
 	return &googleCaptchaV3{
-		secret:         secret,
+		verifier:       newSiteVerifier(verifyURL, GoogleCaptchaV3VerifyURL, secret, logger),
 		allowedActions: allowedActions,
 		threshold:      threshold,
-		httpClient:     &http.Client{},
-		logger:         logger,
 	}
 }
 
 func (v *googleCaptchaV3) Validate(ctx context.Context, response string) error {
-	siteVerify, err := v.requestValidate(ctx, response)
+	siteVerify, err := v.verifier.verify(ctx, response)
 	if err != nil {
 		return fmt.Errorf("validate site verify: %w", err)
 	}
@@ -50,7 +43,7 @@ func (v *googleCaptchaV3) Validate(ctx context.Context, response string) error {
 		return captcha.ErrUnsuccessful
 	}
 
-	if slices.Contains(v.allowedActions, response) {
+	if !slices.Contains(v.allowedActions, siteVerify.Action) {
 		return captcha.ErrActionInvalid
 	}
 
@@ -60,59 +53,3 @@ func (v *googleCaptchaV3) Validate(ctx context.Context, response string) error {
 
 	return nil
 }
-
-type siteVerifyResponse struct {
-	ChallengedAt time.Time     `json:"challenge_ts"`
-	Action       string        `json:"action"`
-	Hostname     string        `json:"hostname"`
-	ErrorCodes   []interface{} `json:"error-codes"`
-	Score        float32       `json:"score"`
-	Success      bool          `json:"success"`
-}
-
-const verifyURL = "https://www.google.com/recaptcha/api/siteverify"
-
-var errInvalidStatusCode = errors.New("invalid status code")
-
-func (v *googleCaptchaV3) requestValidate(ctx context.Context, response string) (*siteVerifyResponse, error) {
-	values := url.Values{}
-	values.Set("secret", v.secret)
-	values.Set("response", response)
-
-	requestBody := strings.NewReader(values.Encode())
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, verifyURL, requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("new http request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := v.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("do http request: %w", err)
-	}
-
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			v.logger.Error("close http response body", "error", closeErr)
-		}
-	}()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("read http response body: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%w: %s, body: %s", errInvalidStatusCode, resp.Status, string(body))
-	}
-
-	siteVerify := &siteVerifyResponse{}
-
-	if unmarshalErr := json.Unmarshal(body, siteVerify); unmarshalErr != nil {
-		return nil, fmt.Errorf("unmarshal http response body: %w", unmarshalErr)
-	}
-
-	return siteVerify, nil
-}
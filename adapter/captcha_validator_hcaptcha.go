@@ -0,0 +1,36 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/domain/captcha"
+)
+
+type hCaptcha struct {
+	verifier *siteVerifier
+}
+
+const HCaptchaVerifyURL = "https://api.hcaptcha.com/siteverify"
+
+// NewHCaptchaValidator sends tokens to HCaptchaVerifyURL when verifyURL is empty.
+func NewHCaptchaValidator(verifyURL, secret string, logger log.Logger) captcha.Validator {
+	return &hCaptcha{
+		verifier: newSiteVerifier(verifyURL, HCaptchaVerifyURL, secret, logger),
+	}
+}
+
+func (v *hCaptcha) Validate(ctx context.Context, response string) error {
+	siteVerify, err := v.verifier.verify(ctx, response)
+	if err != nil {
+		return fmt.Errorf("validate site verify: %w", err)
+	}
+
+	if !siteVerify.Success {
+		return captcha.ErrUnsuccessful
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package adapter
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/truewebber/link-shortener/domain/captcha"
+)
+
+type PowCaptchaOptions struct {
+	Secret        []byte
+	TTL           time.Duration
+	MinDifficulty uint64
+	MaxDifficulty uint64
+	// LoadStep is the number of challenges solved within a minute that doubles the difficulty,
+	// issuing is free for anyone, so only solutions that cost the work count.
+	LoadStep uint64
+}
+
+// powCaptcha issues ALTCHA compatible challenges and validates their solutions.
+type powCaptcha struct {
+	windowStart time.Time
+	spent       captcha.SpentStorage
+	options     PowCaptchaOptions
+	solved      uint64
+	lastSolved  uint64
+	mu          sync.Mutex
+}
+
+// NewPowCaptcha signs challenges with the secret, so nothing is stored until a solution comes back,
+// the salt carries the expiry and the spent storage turns a solution away the second time.
+func NewPowCaptcha(options PowCaptchaOptions, spent captcha.SpentStorage) captcha.ChallengeValidator {
+	return &powCaptcha{
+		windowStart: time.Now(),
+		spent:       spent,
+		options:     options,
+	}
+}
+
+const (
+	powAlgorithm      = "SHA-256"
+	powSaltBytes      = 12
+	powSaltParamsSign = "?"
+	powExpiresParam   = "expires"
+	powLoadWindow     = time.Minute
+	powNumberBase     = 10
+	powExpiresBitSize = 64
+)
+
+func (c *powCaptcha) Issue(_ context.Context) (*captcha.Challenge, error) {
+	maxNumber := c.difficulty()
+
+	secretNumber, err := rand.Int(rand.Reader, new(big.Int).SetUint64(maxNumber+1))
+	if err != nil {
+		return nil, fmt.Errorf("generate secret number: %w", err)
+	}
+
+	saltBytes := make([]byte, powSaltBytes)
+	if _, err := rand.Read(saltBytes); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set(powExpiresParam, strconv.FormatInt(time.Now().Add(c.options.TTL).Unix(), powNumberBase))
+
+	salt := hex.EncodeToString(saltBytes) + powSaltParamsSign + params.Encode()
+	challenge := powHash(salt, secretNumber.Uint64())
+
+	return &captcha.Challenge{
+		Algorithm: powAlgorithm,
+		Salt:      salt,
+		Challenge: challenge,
+		Signature: c.sign(challenge),
+		MaxNumber: maxNumber,
+	}, nil
+}
+
+// difficulty grows with the number of challenges solved during the previous minute.
+func (c *powCaptcha) difficulty() uint64 {
+	c.mu.Lock()
+	c.rollWindowLocked()
+	solved := max(c.solved, c.lastSolved)
+	c.mu.Unlock()
+
+	if c.options.LoadStep == 0 {
+		return c.options.MinDifficulty
+	}
+
+	difficulty := c.options.MinDifficulty
+
+	for range solved / c.options.LoadStep {
+		if difficulty > c.options.MaxDifficulty/2 {
+			return c.options.MaxDifficulty
+		}
+
+		difficulty *= 2
+	}
+
+	return min(difficulty, c.options.MaxDifficulty)
+}
+
+func (c *powCaptcha) recordSolved() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rollWindowLocked()
+	c.solved++
+}
+
+func (c *powCaptcha) rollWindowLocked() {
+	elapsed := time.Since(c.windowStart)
+	if elapsed < powLoadWindow {
+		return
+	}
+
+	c.lastSolved = c.solved
+	if elapsed >= 2*powLoadWindow {
+		c.lastSolved = 0
+	}
+
+	c.solved = 0
+	c.windowStart = time.Now()
+}
+
+type powSolution struct {
+	Algorithm string `json:"algorithm"`
+	Challenge string `json:"challenge"`
+	Salt      string `json:"salt"`
+	Signature string `json:"signature"`
+	Number    uint64 `json:"number"`
+}
+
+func (c *powCaptcha) Validate(ctx context.Context, response string) error {
+	solution, err := decodePowSolution(response)
+	if err != nil {
+		return captcha.ErrUnsuccessful
+	}
+
+	if solution.Algorithm != powAlgorithm ||
+		!hmac.Equal([]byte(solution.Signature), []byte(c.sign(solution.Challenge))) ||
+		!hmac.Equal([]byte(solution.Challenge), []byte(powHash(solution.Salt, solution.Number))) {
+		return captcha.ErrUnsuccessful
+	}
+
+	expiresAt, err := powExpiresAt(solution.Salt)
+	if err != nil {
+		return captcha.ErrUnsuccessful
+	}
+
+	if !time.Now().Before(expiresAt) {
+		return captcha.ErrExpired
+	}
+
+	if err := c.spent.Spend(ctx, solution.Challenge, expiresAt); err != nil {
+		return fmt.Errorf("spend challenge: %w", err)
+	}
+
+	c.recordSolved()
+
+	return nil
+}
+
+func (c *powCaptcha) sign(challenge string) string {
+	mac := hmac.New(sha256.New, c.options.Secret)
+	mac.Write([]byte(challenge))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func powHash(salt string, number uint64) string {
+	sum := sha256.Sum256([]byte(salt + strconv.FormatUint(number, powNumberBase)))
+
+	return hex.EncodeToString(sum[:])
+}
+
+var errInvalidPowSolution = errors.New("invalid proof-of-work solution")
+
+// decodePowSolution accepts the base64 encoded JSON the ALTCHA widget submits.
+func decodePowSolution(response string) (*powSolution, error) {
+	raw, err := base64.StdEncoding.DecodeString(response)
+	if err != nil {
+		return nil, fmt.Errorf("decode base64: %w", err)
+	}
+
+	solution := &powSolution{}
+	if unmarshalErr := json.Unmarshal(raw, solution); unmarshalErr != nil {
+		return nil, fmt.Errorf("unmarshal solution: %w", unmarshalErr)
+	}
+
+	if solution.Challenge == "" || solution.Salt == "" {
+		return nil, errInvalidPowSolution
+	}
+
+	return solution, nil
+}
+
+func powExpiresAt(salt string) (time.Time, error) {
+	_, rawParams, found := strings.Cut(salt, powSaltParamsSign)
+	if !found {
+		return time.Time{}, errInvalidPowSolution
+	}
+
+	params, err := url.ParseQuery(rawParams)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse salt params: %w", err)
+	}
+
+	expires, err := strconv.ParseInt(params.Get(powExpiresParam), powNumberBase, powExpiresBitSize)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse expires: %w", err)
+	}
+
+	return time.Unix(expires, 0), nil
+}
@@ -0,0 +1,177 @@
+package adapter_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/truewebber/link-shortener/adapter"
+	"github.com/truewebber/link-shortener/domain/captcha"
+)
+
+func TestPowCaptchaValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr         error
+		solve           func(t *testing.T, challenge *captcha.Challenge) string
+		name            string
+		ttl             time.Duration
+		submittedBefore bool
+	}{
+		{
+			name:  "Accept a solved challenge",
+			solve: solvePow,
+			ttl:   time.Minute,
+		},
+		{
+			name: "Return error if the challenge is not signed by the server",
+			solve: func(t *testing.T, challenge *captcha.Challenge) string {
+				t.Helper()
+
+				challenge.Signature = hex.EncodeToString(make([]byte, sha256.Size))
+
+				return solvePow(t, challenge)
+			},
+			ttl:     time.Minute,
+			wantErr: captcha.ErrUnsuccessful,
+		},
+		{
+			name: "Return error if the number does not solve the challenge",
+			solve: func(t *testing.T, challenge *captcha.Challenge) string {
+				t.Helper()
+
+				return encodePowSolution(t, challenge, challenge.MaxNumber+1)
+			},
+			ttl:     time.Minute,
+			wantErr: captcha.ErrUnsuccessful,
+		},
+		{
+			name:    "Return error if the challenge expired",
+			solve:   solvePow,
+			ttl:     -time.Second,
+			wantErr: captcha.ErrExpired,
+		},
+		{
+			name:            "Return error if the solution is submitted again",
+			solve:           solvePow,
+			ttl:             time.Minute,
+			submittedBefore: true,
+			wantErr:         captcha.ErrAlreadySpent,
+		},
+		{
+			name: "Return error if the response is not a solution",
+			solve: func(*testing.T, *captcha.Challenge) string {
+				return "not base64 json"
+			},
+			ttl:     time.Minute,
+			wantErr: captcha.ErrUnsuccessful,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pow := adapter.NewPowCaptcha(adapter.PowCaptchaOptions{
+				Secret:        []byte("pow-secret"),
+				TTL:           tt.ttl,
+				MinDifficulty: 1000,
+				MaxDifficulty: 8000,
+				LoadStep:      2,
+			}, &fakeSpentStorage{spent: make(map[string]bool)})
+
+			challenge, err := pow.Issue(context.Background())
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+
+			response := tt.solve(t, challenge)
+
+			if tt.submittedBefore {
+				if err = pow.Validate(context.Background(), response); err != nil {
+					t.Fatalf("Validate() of the first submission error = %v", err)
+				}
+			}
+
+			if err = pow.Validate(context.Background(), response); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPowCaptchaDifficulty(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		issued        int
+		solved        int
+		wantMaxNumber uint64
+	}{
+		{
+			name:          "Keep the minimum difficulty while challenges are only issued",
+			issued:        20,
+			wantMaxNumber: 1000,
+		},
+		{
+			name:          "Keep the minimum difficulty below a load step of solved challenges",
+			solved:        1,
+			wantMaxNumber: 1000,
+		},
+		{
+			name:          "Double the difficulty for every load step of solved challenges",
+			solved:        4,
+			wantMaxNumber: 4000,
+		},
+		{
+			name:          "Cap the difficulty at the maximum",
+			solved:        10,
+			wantMaxNumber: 8000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pow := adapter.NewPowCaptcha(adapter.PowCaptchaOptions{
+				Secret:        []byte("pow-secret"),
+				TTL:           time.Minute,
+				MinDifficulty: 1000,
+				MaxDifficulty: 8000,
+				LoadStep:      2,
+			}, &fakeSpentStorage{spent: make(map[string]bool)})
+
+			for range tt.issued {
+				if _, err := pow.Issue(context.Background()); err != nil {
+					t.Fatalf("Issue() error = %v", err)
+				}
+			}
+
+			for range tt.solved {
+				challenge, err := pow.Issue(context.Background())
+				if err != nil {
+					t.Fatalf("Issue() error = %v", err)
+				}
+
+				if err = pow.Validate(context.Background(), solvePow(t, challenge)); err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+			}
+
+			challenge, err := pow.Issue(context.Background())
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+
+			if challenge.MaxNumber != tt.wantMaxNumber {
+				t.Errorf("MaxNumber = %d, want %d", challenge.MaxNumber, tt.wantMaxNumber)
+			}
+		})
+	}
+}
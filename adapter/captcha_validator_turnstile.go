@@ -0,0 +1,44 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/domain/captcha"
+)
+
+type turnstile struct {
+	verifier       *siteVerifier
+	allowedActions []string
+}
+
+const TurnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+
+// NewTurnstileValidator sends tokens to TurnstileVerifyURL when verifyURL is empty,
+// the action the widget was rendered with is checked only when allowedActions is not empty.
+func NewTurnstileValidator(verifyURL, secret string, allowedActions []string, logger log.Logger) captcha.Validator {
+	return &turnstile{
+		verifier:       newSiteVerifier(verifyURL, TurnstileVerifyURL, secret, logger),
+		allowedActions: allowedActions,
+	}
+}
+
+func (v *turnstile) Validate(ctx context.Context, response string) error {
+	siteVerify, err := v.verifier.verify(ctx, response)
+	if err != nil {
+		return fmt.Errorf("validate site verify: %w", err)
+	}
+
+	if !siteVerify.Success {
+		return captcha.ErrUnsuccessful
+	}
+
+	if len(v.allowedActions) > 0 && !slices.Contains(v.allowedActions, siteVerify.Action) {
+		return captcha.ErrActionInvalid
+	}
+
+	return nil
+}
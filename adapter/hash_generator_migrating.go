@@ -0,0 +1,54 @@
+package adapter
+
+import (
+	"fmt"
+
+	"github.com/truewebber/link-shortener/domain/hash"
+)
+
+type migratingHashGenerator struct {
+	current     hash.Generator
+	legacy      hash.Generator
+	legacyMaxID uint64
+}
+
+// NewMigratingHashGenerator keeps links up to legacyMaxID on the legacy codes,
+// so already shared short URLs never change, while newer links use the current generator.
+func NewMigratingHashGenerator(current, legacy hash.Generator, legacyMaxID uint64) hash.Generator {
+	return &migratingHashGenerator{
+		current:     current,
+		legacy:      legacy,
+		legacyMaxID: legacyMaxID,
+	}
+}
+
+func (g *migratingHashGenerator) ToHash(id uint64) (string, error) {
+	generator := g.current
+	if id <= g.legacyMaxID {
+		generator = g.legacy
+	}
+
+	h, err := generator.ToHash(id)
+	if err != nil {
+		return "", fmt.Errorf("to hash: %w", err)
+	}
+
+	return h, nil
+}
+
+func (g *migratingHashGenerator) FromHash(hash string) (uint64, error) {
+	if id, err := g.current.FromHash(hash); err == nil && id > g.legacyMaxID {
+		return id, nil
+	}
+
+	id, err := g.legacy.FromHash(hash)
+	if err != nil {
+		return 0, fmt.Errorf("from legacy hash: %w", err)
+	}
+
+	if id > g.legacyMaxID {
+		return 0, fmt.Errorf("%w: %s", errInvalidHash, hash)
+	}
+
+	return id, nil
+}
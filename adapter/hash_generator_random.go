@@ -0,0 +1,58 @@
+package adapter
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/truewebber/link-shortener/domain/hash"
+)
+
+type randomCodeGenerator struct {
+	length int
+}
+
+const (
+	randomCodeAlphabet  = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	minRandomCodeLength = 6
+	maxRandomCodeLength = 32
+)
+
+var errInvalidCodeLength = errors.New("invalid random code length")
+
+func NewRandomCodeGenerator(length int) (hash.CodeGenerator, error) {
+	if length < minRandomCodeLength || length > maxRandomCodeLength {
+		return nil, fmt.Errorf("%w: %d not in [%d, %d]",
+			errInvalidCodeLength, length, minRandomCodeLength, maxRandomCodeLength)
+	}
+
+	return &randomCodeGenerator{
+		length: length,
+	}, nil
+}
+
+func MustNewRandomCodeGenerator(length int) hash.CodeGenerator {
+	generator, err := NewRandomCodeGenerator(length)
+	if err != nil {
+		panic(err)
+	}
+
+	return generator
+}
+
+func (g *randomCodeGenerator) Generate() (string, error) {
+	alphabetSize := big.NewInt(int64(len(randomCodeAlphabet)))
+	code := make([]byte, g.length)
+
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", fmt.Errorf("read random: %w", err)
+		}
+
+		code[i] = randomCodeAlphabet[n.Int64()]
+	}
+
+	return string(code), nil
+}
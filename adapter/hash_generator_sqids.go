@@ -3,6 +3,7 @@ package adapter
 import (
 	"errors"
 	"fmt"
+	"regexp"
 
 	"github.com/sqids/sqids-go"
 
@@ -10,26 +11,65 @@ import (
 )
 
 type wrapper struct {
-	sqids *sqids.Sqids
+	sqids   *sqids.Sqids
+	lenient bool
 }
 
-func NewHashGenerator() (hash.Generator, error) {
+// HashOptions configure sqids. Unless Lenient, only the code sqids would issue for an id now is accepted back,
+// so a word added to Blocklist breaks the codes already issued with that word in them.
+type HashOptions struct {
+	Alphabet  string
+	Blocklist []string
+	MinLength uint8
+	// Lenient accepts every hash decoding into a single id, not only the one sqids would issue now.
+	Lenient bool
+}
+
+// LegacyHashOptions are the options every short code was generated with before the alphabet became configurable.
+// They are lenient, the default blocklist those codes were checked against may change with sqids releases.
+func LegacyHashOptions() HashOptions {
 	const minLength = 6
 
-	s, err := sqids.New(sqids.Options{
+	return HashOptions{
 		MinLength: minLength,
+		Lenient:   true,
+	}
+}
+
+const minSecretAlphabetLength = 16
+
+var (
+	alphabetRegexp      = regexp.MustCompile(`^[0-9a-zA-Z]*$`)
+	errInvalidAlphabet  = errors.New("alphabet must contain only latin letters and digits")
+	errAlphabetTooShort = errors.New("alphabet is too short")
+)
+
+func NewHashGenerator(options HashOptions) (hash.Generator, error) {
+	if !alphabetRegexp.MatchString(options.Alphabet) {
+		return nil, errInvalidAlphabet
+	}
+
+	if options.Alphabet != "" && len(options.Alphabet) < minSecretAlphabetLength {
+		return nil, fmt.Errorf("%w: %d < %d", errAlphabetTooShort, len(options.Alphabet), minSecretAlphabetLength)
+	}
+
+	s, err := sqids.New(sqids.Options{
+		Alphabet:  options.Alphabet,
+		MinLength: options.MinLength,
+		Blocklist: options.Blocklist,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("new sqids: %w", err)
 	}
 
 	return &wrapper{
-		sqids: s,
+		sqids:   s,
+		lenient: options.Lenient,
 	}, nil
 }
 
-func MustNewHashGenerator() hash.Generator {
-	generator, err := NewHashGenerator()
+func MustNewHashGenerator(options HashOptions) hash.Generator {
+	generator, err := NewHashGenerator(options)
 	if err != nil {
 		panic(err)
 	}
@@ -55,5 +95,15 @@ func (g *wrapper) FromHash(hash string) (uint64, error) {
 		return 0, fmt.Errorf("%w: %s", errInvalidHash, hash)
 	}
 
+	if g.lenient {
+		return ids[0], nil
+	}
+
+	// several strings decode into the same id, only the one sqids would generate is accepted
+	canonical, err := g.ToHash(ids[0])
+	if err != nil || canonical != hash {
+		return 0, fmt.Errorf("%w: %s", errInvalidHash, hash)
+	}
+
 	return ids[0], nil
 }
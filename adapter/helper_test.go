@@ -0,0 +1,231 @@
+package adapter_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/truewebber/link-shortener/adapter"
+	"github.com/truewebber/link-shortener/domain/captcha"
+)
+
+// fakeIssuer serves discovery, the key set and a token endpoint that accepts one code with its PKCE verifier.
+type fakeIssuer struct {
+	server            *httptest.Server
+	key               *rsa.PrivateKey
+	claims            jwt.MapClaims
+	discoveryIssuer   string
+	signingKeyID      string
+	code              string
+	codeVerifier      string
+	discoveryRequests atomic.Int32
+}
+
+func newFakeIssuer(t *testing.T, clientID, code, codeVerifier string) *fakeIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	issuer := &fakeIssuer{key: key, signingKeyID: "key-1", code: code, codeVerifier: codeVerifier}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.serveDiscovery)
+	mux.HandleFunc("/jwks", issuer.serveKeys)
+	mux.HandleFunc("/token", issuer.serveToken)
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	issuer.discoveryIssuer = issuer.server.URL
+	issuer.claims = jwt.MapClaims{
+		"iss":   issuer.server.URL,
+		"sub":   "subject-1",
+		"aud":   clientID,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"email": "user@example.com",
+		"name":  "Test User",
+	}
+
+	return issuer
+}
+
+func (i *fakeIssuer) serveDiscovery(w http.ResponseWriter, _ *http.Request) {
+	i.discoveryRequests.Add(1)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                i.discoveryIssuer,
+		"authorization_endpoint":                i.server.URL + "/authorize",
+		"token_endpoint":                        i.server.URL + "/token",
+		"jwks_uri":                              i.server.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (i *fakeIssuer) serveKeys(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(i.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(i.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (i *fakeIssuer) serveToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad form", http.StatusBadRequest)
+
+		return
+	}
+
+	if r.PostForm.Get("code") != i.code || r.PostForm.Get("code_verifier") != i.codeVerifier {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, i.claims)
+	token.Header["kid"] = i.signingKeyID
+
+	idToken, err := token.SignedString(i.key)
+	if err != nil {
+		http.Error(w, "sign", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+type fakeSpentStorage struct {
+	spent map[string]bool
+	mu    sync.Mutex
+}
+
+func (s *fakeSpentStorage) Spend(_ context.Context, challenge string, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.spent[challenge] {
+		return captcha.ErrAlreadySpent
+	}
+
+	s.spent[challenge] = true
+
+	return nil
+}
+
+func (s *fakeSpentStorage) PurgeExpired(context.Context, uint32) (uint32, error) {
+	return 0, nil
+}
+
+// solvePow does the work the ALTCHA widget does and encodes the solution the way the widget submits it.
+func solvePow(t *testing.T, challenge *captcha.Challenge) string {
+	t.Helper()
+
+	for number := range challenge.MaxNumber + 1 {
+		sum := sha256.Sum256([]byte(challenge.Salt + strconv.FormatUint(number, 10)))
+		if hex.EncodeToString(sum[:]) == challenge.Challenge {
+			return encodePowSolution(t, challenge, number)
+		}
+	}
+
+	t.Fatalf("no number up to %d solves the challenge", challenge.MaxNumber)
+
+	return ""
+}
+
+func encodePowSolution(t *testing.T, challenge *captcha.Challenge, number uint64) string {
+	t.Helper()
+
+	raw, err := json.Marshal(map[string]any{
+		"algorithm": challenge.Algorithm,
+		"challenge": challenge.Challenge,
+		"salt":      challenge.Salt,
+		"signature": challenge.Signature,
+		"number":    number,
+	})
+	if err != nil {
+		t.Fatalf("marshal solution: %v", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+// newSiteVerifyServer answers like a captcha vendor: with body when the form carries the secret and the response
+// of the widget, with a failure otherwise, or with status when it is not 200.
+func newSiteVerifyServer(t *testing.T, secret, response string, status int, body map[string]any) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			http.Error(w, "unavailable", status)
+
+			return
+		}
+
+		if err := r.ParseForm(); err != nil ||
+			r.PostForm.Get("secret") != secret || r.PostForm.Get("response") != response {
+			writeJSON(w, http.StatusOK, map[string]any{"success": false, "error-codes": []string{"invalid-input-response"}})
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// newTestPool connects to the database TEST_POSTGRES_CONNECTION_STRING names, migrated up like docker-compose does,
+// and skips the test without one.
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	connString := os.Getenv("TEST_POSTGRES_CONNECTION_STRING")
+	if connString == "" {
+		t.Skip("TEST_POSTGRES_CONNECTION_STRING is not set")
+	}
+
+	pool, err := adapter.NewPgxPool(context.Background(), connString)
+	if err != nil {
+		t.Fatalf("connect to postgres: %v", err)
+	}
+
+	t.Cleanup(pool.Close)
+
+	return pool
+}
@@ -0,0 +1,82 @@
+package adapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/truewebber/link-shortener/domain/identity"
+)
+
+type identityLinkRequestStoragePgx struct {
+	pool *pgxpool.Pool
+}
+
+func NewIdentityLinkRequestStoragePgx(pool *pgxpool.Pool) identity.LinkRequestStorage {
+	return &identityLinkRequestStoragePgx{
+		pool: pool,
+	}
+}
+
+// hashLinkNonce keeps nonces out of the database, they are random enough to need no key.
+func hashLinkNonce(nonce string) string {
+	sum := sha256.Sum256([]byte(nonce))
+
+	return hex.EncodeToString(sum[:])
+}
+
+const insertLinkRequest = `INSERT INTO identity_link_requests (nonce_hash, user_id, expires_at) VALUES ($1, $2, $3);`
+
+func (s *identityLinkRequestStoragePgx) Create(ctx context.Context, request *identity.LinkRequest) error {
+	if _, err := s.pool.Exec(
+		ctx, insertLinkRequest, hashLinkNonce(request.Nonce), request.UserID, request.ExpiresAt,
+	); err != nil {
+		return fmt.Errorf("insert link request: %w", err)
+	}
+
+	return nil
+}
+
+const consumeLinkRequest = `
+		DELETE FROM identity_link_requests
+		WHERE nonce_hash = $1 AND expires_at > CURRENT_TIMESTAMP
+		RETURNING user_id;`
+
+func (s *identityLinkRequestStoragePgx) Consume(ctx context.Context, nonce string) (uint64, error) {
+	var userID uint64
+
+	err := s.pool.QueryRow(ctx, consumeLinkRequest, hashLinkNonce(nonce)).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, identity.ErrLinkRequestNotFound
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("delete link request: %w", err)
+	}
+
+	return userID, nil
+}
+
+const deleteExpiredLinkRequestsBatch = `
+		DELETE FROM identity_link_requests
+		WHERE nonce_hash IN (
+			SELECT nonce_hash FROM identity_link_requests
+			WHERE expires_at <= CURRENT_TIMESTAMP
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		);`
+
+func (s *identityLinkRequestStoragePgx) PurgeExpired(ctx context.Context, limit uint32) (uint32, error) {
+	cmd, err := s.pool.Exec(ctx, deleteExpiredLinkRequestsBatch, limit)
+	if err != nil {
+		return 0, fmt.Errorf("exec delete expired link requests: %w", err)
+	}
+
+	//nolint:gosec // rows affected is bounded by limit
+	return uint32(cmd.RowsAffected()), nil
+}
@@ -0,0 +1,209 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pgxpkg "github.com/truewebber/gopkg/pgx"
+
+	"github.com/truewebber/link-shortener/domain/identity"
+	userdomain "github.com/truewebber/link-shortener/domain/user"
+)
+
+type identityStoragePgx struct {
+	db *pgxpool.Pool
+}
+
+func NewIdentityStoragePgx(db *pgxpool.Pool) identity.Storage {
+	return &identityStoragePgx{
+		db: db,
+	}
+}
+
+//nolint:dupword // CURRENT_TIMESTAMP used twice for two different fields.
+const insertIdentityQuery = `
+		INSERT INTO identities (
+			user_id, provider_type, provider_user_id, email, name, avatar_url, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING id, created_at, updated_at;`
+
+func (s *identityStoragePgx) Create(ctx context.Context, i *identity.Identity) error {
+	providerType, err := providerTypeToPGX(i.Provider)
+	if err != nil {
+		return fmt.Errorf("build provider type pgx: %w", err)
+	}
+
+	err = s.db.QueryRow(
+		ctx,
+		insertIdentityQuery,
+		i.UserID,
+		providerType,
+		i.ProviderID,
+		i.Email,
+		i.Name,
+		i.AvatarURL,
+	).Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolationCode {
+		return identity.ErrAlreadyExists
+	}
+
+	if err != nil {
+		return fmt.Errorf("insert identity: %w", err)
+	}
+
+	return nil
+}
+
+const selectIdentityByProviderIDQuery = `
+		SELECT i.id, i.user_id, i.provider_type, i.provider_user_id, i.email, i.name, i.avatar_url,
+			i.created_at, i.updated_at
+		FROM identities i
+		JOIN users u ON u.id = i.user_id
+		WHERE i.provider_type = $1 AND i.provider_user_id = $2 AND NOT u.deleted;`
+
+func (s *identityStoragePgx) ByProviderID(
+	ctx context.Context, provider userdomain.Provider, providerID string,
+) (*identity.Identity, error) {
+	providerType, err := providerTypeToPGX(provider)
+	if err != nil {
+		return nil, fmt.Errorf("build provider type pgx: %w", err)
+	}
+
+	i, err := scanIdentity(s.db.QueryRow(ctx, selectIdentityByProviderIDQuery, providerType, providerID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, identity.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("select identity by provider id: %w", err)
+	}
+
+	return i, nil
+}
+
+const selectIdentitiesByUserIDQuery = `
+		SELECT id, user_id, provider_type, provider_user_id, email, name, avatar_url, created_at, updated_at
+		FROM identities
+		WHERE user_id = $1
+		ORDER BY created_at;`
+
+func (s *identityStoragePgx) ByUserID(ctx context.Context, userID uint64) ([]identity.Identity, error) {
+	rows, err := s.db.Query(ctx, selectIdentitiesByUserIDQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("select identities by user id: %w", err)
+	}
+
+	defer rows.Close()
+
+	var identities []identity.Identity
+
+	for rows.Next() {
+		i, scanErr := scanIdentity(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("scan identity: %w", scanErr)
+		}
+
+		identities = append(identities, *i)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("rows: %w", rowsErr)
+	}
+
+	return identities, nil
+}
+
+func scanIdentity(row pgx.Row) (*identity.Identity, error) {
+	var (
+		i            identity.Identity
+		providerType uint8
+	)
+
+	if err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&providerType,
+		&i.ProviderID,
+		&i.Email,
+		&i.Name,
+		&i.AvatarURL,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("scan row: %w", err)
+	}
+
+	provider, err := providerTypeFromPGX(providerType)
+	if err != nil {
+		return nil, fmt.Errorf("build provider type domain: %w", err)
+	}
+
+	i.Provider = provider
+
+	return &i, nil
+}
+
+const updateIdentityQuery = `
+		UPDATE identities
+		SET email = $2, name = $3, avatar_url = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING updated_at;`
+
+func (s *identityStoragePgx) Update(ctx context.Context, i *identity.Identity) error {
+	err := s.db.QueryRow(ctx, updateIdentityQuery, i.ID, i.Email, i.Name, i.AvatarURL).Scan(&i.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return identity.ErrNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("update identity: %w", err)
+	}
+
+	return nil
+}
+
+const (
+	// lockUserIdentitiesQuery serializes deletes of one user, so two of them can not remove the last identity.
+	lockUserIdentitiesQuery = "SELECT id FROM users WHERE id = $1 FOR UPDATE;"
+	countUserIdentities     = "SELECT count(*) FROM identities WHERE user_id = $1;"
+	deleteIdentityQuery     = "DELETE FROM identities WHERE id = $1 AND user_id = $2;"
+)
+
+func (s *identityStoragePgx) Delete(ctx context.Context, id, userID uint64) error {
+	doErr := pgxpkg.DoAtomic(ctx, s.db, func(doCtx context.Context, tx pgx.Tx) error {
+		if _, err := tx.Exec(doCtx, lockUserIdentitiesQuery, userID); err != nil {
+			return fmt.Errorf("lock user: %w", err)
+		}
+
+		var count int64
+		if err := tx.QueryRow(doCtx, countUserIdentities, userID).Scan(&count); err != nil {
+			return fmt.Errorf("count identities: %w", err)
+		}
+
+		cmd, err := tx.Exec(doCtx, deleteIdentityQuery, id, userID)
+		if err != nil {
+			return fmt.Errorf("delete identity: %w", err)
+		}
+
+		if cmd.RowsAffected() == 0 {
+			return identity.ErrNotFound
+		}
+
+		if count <= 1 {
+			return identity.ErrLastIdentity
+		}
+
+		return nil
+	})
+	if doErr != nil {
+		return fmt.Errorf("delete identity on tx: %w", doErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+
+	"github.com/truewebber/link-shortener/domain/link"
+	"github.com/truewebber/link-shortener/domain/moderation"
+)
+
+type CacheOptions struct {
+	Size        int
+	TTL         time.Duration
+	NegativeTTL time.Duration
+}
+
+const (
+	cacheResultLabel = "result"
+	cacheResultHit   = "hit"
+	cacheResultMiss  = "miss"
+)
+
+type cachedLinkStorage struct {
+	link.Storage
+	lookups gokitmetrics.Counter
+	links   *lruCache[uint64, *link.Link]
+	codes   *lruCache[string, uint64]
+	options CacheOptions
+}
+
+// NewCachedLinkStorage caches links by id and ids by code, unknown ones are cached for NegativeTTL.
+// Entries never outlive the link expiry, changes made through this storage drop them right away.
+func NewCachedLinkStorage(storage link.Storage, options CacheOptions, lookups gokitmetrics.Counter) link.Storage {
+	return &cachedLinkStorage{
+		Storage: storage,
+		lookups: lookups,
+		links:   newLRUCache[uint64, *link.Link](options.Size),
+		codes:   newLRUCache[string, uint64](options.Size),
+		options: options,
+	}
+}
+
+func (s *cachedLinkStorage) ByID(ctx context.Context, id uint64) (*link.Link, error) {
+	if cached, ok := s.links.Get(id); ok {
+		s.lookups.With(cacheResultLabel, cacheResultHit).Add(1)
+
+		if cached == nil {
+			return nil, link.ErrNotFound
+		}
+
+		l := *cached
+
+		return &l, nil
+	}
+
+	s.lookups.With(cacheResultLabel, cacheResultMiss).Add(1)
+
+	l, err := s.Storage.ByID(ctx, id)
+	if errors.Is(err, link.ErrNotFound) {
+		s.links.Add(id, nil, s.options.NegativeTTL)
+
+		return nil, link.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get link from storage: %w", err)
+	}
+
+	cached := *l
+	s.links.Add(id, &cached, s.linkTTL(l))
+
+	return l, nil
+}
+
+func (s *cachedLinkStorage) linkTTL(l *link.Link) time.Duration {
+	if l.ExpiresAt == nil {
+		return s.options.TTL
+	}
+
+	return min(s.options.TTL, time.Until(*l.ExpiresAt))
+}
+
+// notFoundID marks codes cached as unknown, real link ids start from 1.
+const notFoundID = 0
+
+func (s *cachedLinkStorage) IDByCode(ctx context.Context, code string) (uint64, error) {
+	if id, ok := s.codes.Get(code); ok {
+		s.lookups.With(cacheResultLabel, cacheResultHit).Add(1)
+
+		if id == notFoundID {
+			return 0, link.ErrNotFound
+		}
+
+		return id, nil
+	}
+
+	s.lookups.With(cacheResultLabel, cacheResultMiss).Add(1)
+
+	id, err := s.Storage.IDByCode(ctx, code)
+	if errors.Is(err, link.ErrNotFound) {
+		s.codes.Add(code, notFoundID, s.options.NegativeTTL)
+
+		return 0, link.ErrNotFound
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("get link id by code from storage: %w", err)
+	}
+
+	s.codes.Add(code, id, s.options.TTL)
+
+	return id, nil
+}
+
+func (s *cachedLinkStorage) Create(ctx context.Context, l *link.Link) error {
+	if err := s.Storage.Create(ctx, l); err != nil {
+		return fmt.Errorf("create link in storage: %w", err)
+	}
+
+	s.links.Remove(l.ID)
+
+	if l.Code != "" {
+		s.codes.Remove(l.Code)
+	}
+
+	return nil
+}
+
+func (s *cachedLinkStorage) Update(ctx context.Context, l *link.Link) error {
+	if err := s.Storage.Update(ctx, l); err != nil {
+		return fmt.Errorf("update link in storage: %w", err)
+	}
+
+	s.links.Remove(l.ID)
+
+	return nil
+}
+
+func (s *cachedLinkStorage) Delete(ctx context.Context, id uint64) error {
+	if err := s.Storage.Delete(ctx, id); err != nil {
+		return fmt.Errorf("delete link in storage: %w", err)
+	}
+
+	s.links.Remove(id)
+
+	return nil
+}
+
+func (s *cachedLinkStorage) DeleteByUserID(ctx context.Context, userID uint64) ([]uint64, error) {
+	ids, err := s.Storage.DeleteByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("delete links by user id in storage: %w", err)
+	}
+
+	for _, id := range ids {
+		s.links.Remove(id)
+	}
+
+	return ids, nil
+}
+
+func (s *cachedLinkStorage) Block(ctx context.Context, id uint64, reason string) error {
+	if err := s.Storage.Block(ctx, id, reason); err != nil {
+		return fmt.Errorf("block link in storage: %w", err)
+	}
+
+	s.links.Remove(id)
+
+	return nil
+}
+
+func (s *cachedLinkStorage) Disable(ctx context.Context, action *moderation.Action) error {
+	if err := s.Storage.Disable(ctx, action); err != nil {
+		return fmt.Errorf("disable link in storage: %w", err)
+	}
+
+	s.links.Remove(action.TargetID)
+
+	return nil
+}
+
+func (s *cachedLinkStorage) Enable(ctx context.Context, action *moderation.Action) error {
+	if err := s.Storage.Enable(ctx, action); err != nil {
+		return fmt.Errorf("enable link in storage: %w", err)
+	}
+
+	s.links.Remove(action.TargetID)
+
+	return nil
+}
+
+func (s *cachedLinkStorage) Restore(ctx context.Context, id uint64) error {
+	if err := s.Storage.Restore(ctx, id); err != nil {
+		return fmt.Errorf("restore link in storage: %w", err)
+	}
+
+	s.links.Remove(id)
+
+	return nil
+}
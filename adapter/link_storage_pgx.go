@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	pgxpkg "github.com/truewebber/gopkg/pgx"
 
 	"github.com/truewebber/link-shortener/domain/link"
+	"github.com/truewebber/link-shortener/domain/moderation"
 )
 
 type linkStoragePGX struct {
@@ -25,13 +28,17 @@ func NewLinkStoragePgx(pool *pgxpool.Pool) link.Storage {
 const (
 	//nolint:dupword // false positive, query is correct
 	insertLinkRow = `INSERT INTO public.urls 
-    (user_id, redirect_url, expires_type, expires_at, created_at, updated_at, deleted)
-VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, FALSE)
+    (user_id, redirect_url, expires_type, expires_at, code, created_at, updated_at, deleted)
+VALUES ($1, $2, $3, $4, NULLIF($5, ''), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, FALSE)
 ON CONFLICT (user_id, md5(redirect_url)) WHERE deleted = false
                                          DO NOTHING
                                          RETURNING id;`
 
-	selectLinkRow = `SELECT id FROM public.urls 
+	urlsCodeIndex = "urls__code__udx"
+
+	urlAliasesAliasIndex = "url_aliases__alias__udx"
+
+	selectLinkRow = `SELECT id, COALESCE(code, '') FROM public.urls 
                   WHERE user_id = $1 AND md5(redirect_url) = md5($2) AND deleted = false;`
 )
 
@@ -41,80 +48,137 @@ func (s *linkStoragePGX) Create(ctx context.Context, l *link.Link) error {
 	}
 
 	doErr := pgxpkg.DoAtomicWithOptions(ctx, s.pool, txOpts, func(doCtx context.Context, tx pgx.Tx) error {
-		expiresType, castErr := s.expiresTypeToPGX(l.ExpiresType)
-		if castErr != nil {
-			return fmt.Errorf("expires type to pgx: %w", castErr)
-		}
-
-		err := tx.QueryRow(doCtx, insertLinkRow, l.UserID, l.RedirectURL, expiresType, l.ExpiresAt).
-			Scan(&l.ID)
-		if err == nil {
-			return nil
-		}
+		return s.insertLink(doCtx, tx, l)
+	})
+	if doErr != nil {
+		return fmt.Errorf("create link on tx: %w", doErr)
+	}
 
-		if !errors.Is(err, pgx.ErrNoRows) {
-			return fmt.Errorf("insert link row: %w", err)
-		}
+	return nil
+}
 
-		err = tx.QueryRow(doCtx, selectLinkRow, l.UserID, l.RedirectURL).Scan(&l.ID)
-		if err != nil {
-			return fmt.Errorf("select existing link row: %w", err)
-		}
+// insertLink falls back to the live link the user already has for the same URL.
+func (s *linkStoragePGX) insertLink(ctx context.Context, tx pgx.Tx, l *link.Link) error {
+	expiresType, err := s.expiresTypeToPGX(l.ExpiresType)
+	if err != nil {
+		return fmt.Errorf("expires type to pgx: %w", err)
+	}
 
+	err = tx.QueryRow(ctx, insertLinkRow, l.UserID, l.RedirectURL, expiresType, l.ExpiresAt, l.Code).Scan(&l.ID)
+	if err == nil {
 		return nil
-	})
-	if doErr != nil {
-		return fmt.Errorf("create link on tx: %w", doErr)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolationCode && pgErr.ConstraintName == urlsCodeIndex {
+		return link.ErrCodeAlreadyExists
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("insert link row: %w", err)
+	}
+
+	if err = tx.QueryRow(ctx, selectLinkRow, l.UserID, l.RedirectURL).Scan(&l.ID, &l.Code); err != nil {
+		return fmt.Errorf("select existing link row: %w", err)
 	}
 
 	return nil
 }
 
-const selectLinkByID = `SELECT id, user_id, redirect_url, expires_type, expires_at, created_at, updated_at
+const selectLinkByID = `SELECT id, user_id, redirect_url, COALESCE(code, ''),
+       expires_type, expires_at, created_at, updated_at, blocked_at, COALESCE(blocked_reason, '')
 FROM public.urls
 WHERE id = $1 AND NOT deleted AND (expires_type='never' OR expires_at > CURRENT_TIMESTAMP);`
 
 func (s *linkStoragePGX) ByID(ctx context.Context, id uint64) (*link.Link, error) {
+	l, err := s.scanLink(s.pool.QueryRow(ctx, selectLinkByID, id))
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, link.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link: %w", err)
+	}
+
+	return l, nil
+}
+
+// scanLink reads the columns selectLinkByID lists, in that order.
+func (s *linkStoragePGX) scanLink(row pgx.Row) (*link.Link, error) {
 	var (
 		l           link.Link
 		expiresType string
 	)
 
-	err := s.pool.QueryRow(ctx, selectLinkByID, id).Scan(
+	err := row.Scan(
 		&l.ID,
 		&l.UserID,
 		&l.RedirectURL,
+		&l.Code,
 		&expiresType,
 		&l.ExpiresAt,
 		&l.CreatedAt,
 		&l.UpdatedAt,
+		&l.BlockedAt,
+		&l.BlockedReason,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("scan link: %w", err)
+	}
+
+	l.ExpiresType, err = s.expiresTypeFromPGX(expiresType)
+	if err != nil {
+		return nil, fmt.Errorf("expires type from pgx: %w", err)
+	}
+
+	return &l, nil
+}
+
+const selectLinkIDByCode = `SELECT id FROM public.urls WHERE code = $1;`
 
+func (s *linkStoragePGX) IDByCode(ctx context.Context, code string) (uint64, error) {
+	var id uint64
+
+	err := s.pool.QueryRow(ctx, selectLinkIDByCode, code).Scan(&id)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, link.ErrNotFound
+		return 0, link.ErrNotFound
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get link: %w", err)
+		return 0, fmt.Errorf("failed to get link id by code: %w", err)
+	}
+
+	return id, nil
+}
+
+const selectLinkCodeByID = `SELECT COALESCE(code, '') FROM public.urls WHERE id = $1;`
+
+func (s *linkStoragePGX) CodeByID(ctx context.Context, id uint64) (string, error) {
+	var code string
+
+	err := s.pool.QueryRow(ctx, selectLinkCodeByID, id).Scan(&code)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", link.ErrNotFound
 	}
 
-	l.ExpiresType, err = s.expiresTypeFromPGX(expiresType)
 	if err != nil {
-		return nil, fmt.Errorf("expires type from pgx: %w", err)
+		return "", fmt.Errorf("failed to get link code by id: %w", err)
 	}
 
-	return &l, nil
+	return code, nil
 }
 
 const (
-	selectLinksByUserID = `SELECT id, user_id, redirect_url, expires_type, expires_at, created_at, updated_at
+	selectLinksByUserID = `SELECT id, user_id, redirect_url, COALESCE(code, ''),
+       expires_type, expires_at, created_at, updated_at, blocked_at, COALESCE(blocked_reason, '')
 FROM urls
-WHERE user_id = $1 AND NOT deleted
+WHERE user_id = $1 AND NOT deleted AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;`
 
 	selectCountLinksByUserID = `SELECT count(*) FROM urls
-                WHERE user_id = $1 AND NOT deleted AND expires_at > CURRENT_TIMESTAMP;`
+                WHERE user_id = $1 AND NOT deleted AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP);`
 )
 
 func (s *linkStoragePGX) ByUserID(ctx context.Context, userID uint64, limit, offset uint32) (link.List, error) {
@@ -129,32 +193,12 @@ func (s *linkStoragePGX) ByUserID(ctx context.Context, userID uint64, limit, off
 		defer rows.Close()
 
 		for rows.Next() {
-			var (
-				l           link.Link
-				expiresType string
-			)
-
-			scanErr := rows.Scan(
-				&l.ID,
-				&l.UserID,
-				&l.RedirectURL,
-				&expiresType,
-				&l.ExpiresAt,
-				&l.CreatedAt,
-				&l.UpdatedAt,
-			)
+			l, scanErr := s.scanLink(rows)
 			if scanErr != nil {
 				return fmt.Errorf("failed to scan link: %w", scanErr)
 			}
 
-			var castErr error
-
-			l.ExpiresType, castErr = s.expiresTypeFromPGX(expiresType)
-			if castErr != nil {
-				return fmt.Errorf("expires type from pgx: %w", castErr)
-			}
-
-			list.Links = append(list.Links, l)
+			list.Links = append(list.Links, *l)
 		}
 
 		if rowsErr := rows.Err(); rowsErr != nil {
@@ -189,8 +233,20 @@ func (s *linkStoragePGX) Update(ctx context.Context, l *link.Link) error {
 	return nil
 }
 
-const updateLinkSetDeleted = `UPDATE urls SET deleted = true, updated_at = CURRENT_TIMESTAMP
-            WHERE id = $1 AND NOT deleted;`
+// freeDeletedURLAliases releases the aliases of the links in deleted_urls, so they can be taken again.
+const freeDeletedURLAliases = `freed_aliases AS (
+			UPDATE url_aliases SET deleted = true
+			WHERE NOT deleted AND url_id IN (SELECT id FROM deleted_urls)
+		)`
+
+//nolint:dupword // CURRENT_TIMESTAMP used twice for two different fields.
+const updateLinkSetDeleted = `WITH deleted_urls AS (
+			UPDATE urls
+			SET deleted = true, deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND NOT deleted
+			RETURNING id
+		), ` + freeDeletedURLAliases + `
+		SELECT count(*) FROM deleted_urls;`
 
 func (s *linkStoragePGX) Delete(ctx context.Context, id uint64) error {
 	if _, err := s.pool.Exec(ctx, updateLinkSetDeleted, id); err != nil {
@@ -200,17 +256,340 @@ func (s *linkStoragePGX) Delete(ctx context.Context, id uint64) error {
 	return nil
 }
 
-const setDeletedExpiredURLs = `UPDATE urls SET deleted = true, updated_at = CURRENT_TIMESTAMP
-		WHERE NOT deleted AND expires_at IS NOT NULL AND expires_at <= CURRENT_TIMESTAMP;`
+const selectDeletedLinkByID = `SELECT id, user_id, redirect_url, COALESCE(code, ''),
+       expires_type, expires_at, created_at, updated_at, deleted_at
+FROM urls
+WHERE id = $1 AND deleted;`
+
+func (s *linkStoragePGX) DeletedByID(ctx context.Context, id uint64) (*link.Link, error) {
+	var (
+		l           link.Link
+		expiresType string
+	)
+
+	err := s.pool.QueryRow(ctx, selectDeletedLinkByID, id).Scan(
+		&l.ID,
+		&l.UserID,
+		&l.RedirectURL,
+		&l.Code,
+		&expiresType,
+		&l.ExpiresAt,
+		&l.CreatedAt,
+		&l.UpdatedAt,
+		&l.DeletedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, link.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted link: %w", err)
+	}
+
+	l.ExpiresType, err = s.expiresTypeFromPGX(expiresType)
+	if err != nil {
+		return nil, fmt.Errorf("expires type from pgx: %w", err)
+	}
+
+	return &l, nil
+}
+
+// updateLinkSetRestored takes back the aliases Delete freed, a taken alias fails the whole statement.
+const updateLinkSetRestored = `WITH restored_urls AS (
+			UPDATE urls SET deleted = false, deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND deleted
+			RETURNING id
+		), restored_aliases AS (
+			UPDATE url_aliases SET deleted = false
+			WHERE deleted AND url_id IN (SELECT id FROM restored_urls)
+		)
+		SELECT count(*) FROM restored_urls;`
+
+func (s *linkStoragePGX) Restore(ctx context.Context, id uint64) error {
+	var restored uint32
+
+	err := s.pool.QueryRow(ctx, updateLinkSetRestored, id).Scan(&restored)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolationCode {
+		if pgErr.ConstraintName == urlAliasesAliasIndex {
+			return link.ErrAliasAlreadyExists
+		}
+
+		return link.ErrAlreadyExists
+	}
+
+	if err != nil {
+		return fmt.Errorf("set link restored by id: %w", err)
+	}
+
+	if restored == 0 {
+		return link.ErrNotFound
+	}
+
+	return nil
+}
+
+//nolint:dupword // CURRENT_TIMESTAMP used twice for two different fields.
+const setDeletedExpiredURLsBatch = `WITH deleted_urls AS (
+			UPDATE urls
+			SET deleted = true, deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE id IN (
+				SELECT id FROM urls
+				WHERE NOT deleted AND expires_at IS NOT NULL AND expires_at <= CURRENT_TIMESTAMP
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id
+		), ` + freeDeletedURLAliases + `
+		SELECT count(*) FROM deleted_urls;`
+
+func (s *linkStoragePGX) DeleteExpired(ctx context.Context, limit uint32) (uint32, error) {
+	var deleted uint32
+
+	if err := s.pool.QueryRow(ctx, setDeletedExpiredURLsBatch, limit).Scan(&deleted); err != nil {
+		return 0, fmt.Errorf("set expired links deleted: %w", err)
+	}
+
+	return deleted, nil
+}
+
+const reassignLinksByUserID = `UPDATE urls u
+		SET user_id = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE u.user_id = $1 AND NOT u.deleted AND NOT EXISTS (
+			SELECT 1 FROM urls o
+			WHERE o.user_id = $2 AND NOT o.deleted AND md5(o.redirect_url) = md5(u.redirect_url)
+		);`
+
+func (s *linkStoragePGX) ReassignByUserID(ctx context.Context, fromUserID, toUserID uint64) error {
+	if _, err := s.pool.Exec(ctx, reassignLinksByUserID, fromUserID, toUserID); err != nil {
+		return fmt.Errorf("reassign links by user id: %w", err)
+	}
+
+	return nil
+}
+
+//nolint:dupword // CURRENT_TIMESTAMP used twice for two different fields.
+const setDeletedURLsByUserID = `WITH deleted_urls AS (
+			UPDATE urls
+			SET deleted = true, deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE user_id = $1 AND NOT deleted
+			RETURNING id
+		), ` + freeDeletedURLAliases + `
+		SELECT id FROM deleted_urls;`
+
+func (s *linkStoragePGX) DeleteByUserID(ctx context.Context, userID uint64) ([]uint64, error) {
+	rows, err := s.pool.Query(ctx, setDeletedURLsByUserID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("set links deleted by user id: %w", err)
+	}
+
+	defer rows.Close()
+
+	var ids []uint64
+
+	for rows.Next() {
+		var id uint64
+
+		if scanErr := rows.Scan(&id); scanErr != nil {
+			return nil, fmt.Errorf("scan deleted link id: %w", scanErr)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("rows: %w", rowsErr)
+	}
+
+	return ids, nil
+}
+
+const selectLiveLinksAfterID = `SELECT id, user_id, redirect_url, COALESCE(code, ''),
+       expires_type, expires_at, created_at, updated_at, blocked_at, COALESCE(blocked_reason, '')
+FROM urls
+WHERE id > $1 AND NOT deleted AND blocked_at IS NULL AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+ORDER BY id
+LIMIT $2;`
+
+func (s *linkStoragePGX) Live(ctx context.Context, afterID uint64, limit uint32) ([]link.Link, error) {
+	links, err := s.selectLinkPage(ctx, limit, selectLiveLinksAfterID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("select live links: %w", err)
+	}
+
+	return links, nil
+}
+
+const selectLinksByUserIDAfterID = `SELECT id, user_id, redirect_url, COALESCE(code, ''),
+       expires_type, expires_at, created_at, updated_at, blocked_at, COALESCE(blocked_reason, '')
+FROM urls
+WHERE user_id = $1 AND id > $2 AND NOT deleted AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+ORDER BY id
+LIMIT $3;`
+
+func (s *linkStoragePGX) ByUserIDAfter(
+	ctx context.Context, userID, afterID uint64, limit uint32,
+) ([]link.Link, error) {
+	links, err := s.selectLinkPage(ctx, limit, selectLinksByUserIDAfterID, userID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("select links by user id: %w", err)
+	}
+
+	return links, nil
+}
+
+func (s *linkStoragePGX) selectLinkPage(
+	ctx context.Context, limit uint32, query string, args ...any,
+) ([]link.Link, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+
+	links := make([]link.Link, 0, limit)
+
+	for rows.Next() {
+		l, scanErr := s.scanLink(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to scan link: %w", scanErr)
+		}
+
+		links = append(links, *l)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("rows: %w", rowsErr)
+	}
+
+	return links, nil
+}
+
+//nolint:dupword // CURRENT_TIMESTAMP used twice for two different fields.
+const updateLinkSetBlocked = `UPDATE urls
+		SET blocked_at = CURRENT_TIMESTAMP, blocked_reason = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND NOT deleted AND blocked_at IS NULL;`
+
+func (s *linkStoragePGX) Block(ctx context.Context, id uint64, reason string) error {
+	cmd, err := s.pool.Exec(ctx, updateLinkSetBlocked, id, reason)
+	if err != nil {
+		return fmt.Errorf("set link blocked by id: %w", err)
+	}
 
-func (s *linkStoragePGX) DeleteAllExpired(ctx context.Context) error {
-	if _, err := s.pool.Exec(ctx, setDeletedExpiredURLs); err != nil {
-		return fmt.Errorf("set expired links deleted: %w", err)
+	if cmd.RowsAffected() == 0 {
+		return link.ErrNotFound
 	}
 
 	return nil
 }
 
+//nolint:dupword // CURRENT_TIMESTAMP used twice for two different fields.
+const updateLinkSetUnblocked = `UPDATE urls
+		SET blocked_at = NULL, blocked_reason = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND NOT deleted AND blocked_at IS NOT NULL;`
+
+func (s *linkStoragePGX) Disable(ctx context.Context, action *moderation.Action) error {
+	return s.moderate(ctx, action, updateLinkSetBlocked, action.TargetID, action.Reason)
+}
+
+func (s *linkStoragePGX) Enable(ctx context.Context, action *moderation.Action) error {
+	return s.moderate(ctx, action, updateLinkSetUnblocked, action.TargetID)
+}
+
+// moderate appends the action only when the query changed the link.
+func (s *linkStoragePGX) moderate(ctx context.Context, action *moderation.Action, query string, args ...any) error {
+	doErr := pgxpkg.DoAtomic(ctx, s.pool, func(doCtx context.Context, tx pgx.Tx) error {
+		cmd, err := tx.Exec(doCtx, query, args...)
+		if err != nil {
+			return fmt.Errorf("set link blocked state by id: %w", err)
+		}
+
+		if cmd.RowsAffected() == 0 {
+			return link.ErrNotFound
+		}
+
+		return insertModerationAction(doCtx, tx, action)
+	})
+	if doErr != nil {
+		return fmt.Errorf("moderate link on tx: %w", doErr)
+	}
+
+	return nil
+}
+
+// searchLinksFrom pulls the host out of the destination, skipping the userinfo and the port.
+const (
+	searchLinksFrom = `FROM (
+	SELECT *, lower(substring(redirect_url FROM '^[^:/?#]+://(?:[^@/?#]*@)?([^:/?#]+)')) AS host
+	FROM urls
+	WHERE NOT deleted
+) u
+WHERE ($1 = '' OR host = $1 OR right(host, length($1) + 1) = '.' || $1)
+  AND ($2 = 0 OR user_id = $2)
+  AND ($3::timestamp IS NULL OR created_at >= $3)
+  AND ($4::timestamp IS NULL OR created_at < $4)`
+
+	searchLinks = `SELECT id, user_id, redirect_url, COALESCE(code, ''),
+       expires_type, expires_at, created_at, updated_at, blocked_at, COALESCE(blocked_reason, '')
+` + searchLinksFrom + `
+ORDER BY created_at DESC, id DESC
+LIMIT $5 OFFSET $6;`
+
+	searchCountLinks = `SELECT count(*) ` + searchLinksFrom + `;`
+)
+
+func (s *linkStoragePGX) Search(
+	ctx context.Context, filter link.SearchFilter, limit, offset uint32,
+) (link.List, error) {
+	list := link.List{}
+	args := []any{filter.Domain, filter.UserID, optionalTime(filter.CreatedFrom), optionalTime(filter.CreatedTo)}
+
+	doErr := pgxpkg.DoAtomic(ctx, s.pool, func(doCtx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(doCtx, searchLinks, append(args, limit, offset)...)
+		if err != nil {
+			return fmt.Errorf("search links: %w", err)
+		}
+
+		defer rows.Close()
+
+		for rows.Next() {
+			l, scanErr := s.scanLink(rows)
+			if scanErr != nil {
+				return fmt.Errorf("failed to scan link: %w", scanErr)
+			}
+
+			list.Links = append(list.Links, *l)
+		}
+
+		if rowsErr := rows.Err(); rowsErr != nil {
+			return fmt.Errorf("rows: %w", rowsErr)
+		}
+
+		if err := tx.QueryRow(doCtx, searchCountLinks, args...).Scan(&list.Count); err != nil {
+			return fmt.Errorf("search count links: %w", err)
+		}
+
+		return nil
+	})
+	if doErr != nil {
+		return link.List{}, fmt.Errorf("search links on tx: %w", doErr)
+	}
+
+	return list, nil
+}
+
+// optionalTime turns the zero time into NULL.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
 const (
 	expiresType3Months  = "3months"
 	expiresType6Months  = "6months"
@@ -0,0 +1,96 @@
+package adapter_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/truewebber/link-shortener/adapter"
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/alias"
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+func TestLinkStorageRestoreAlias(t *testing.T) {
+	t.Parallel()
+
+	pool := newTestPool(t)
+	linkStorage := adapter.NewLinkStoragePgx(pool)
+	aliasStorage := adapter.NewAliasStoragePgx(pool)
+	ctx := context.Background()
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	tests := []struct {
+		wantErr    error
+		name       string
+		aliasTaken bool
+	}{
+		{
+			name: "Resolve the alias of a restored link again",
+		},
+		{
+			name:       "Return error if another link took the alias of the deleted link",
+			aliasTaken: true,
+			wantErr:    link.ErrAliasAlreadyExists,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := "restored-" + strconv.Itoa(i) + "-" + suffix
+
+			deleted := createAliasedLink(t, linkStorage, aliasStorage, value, "https://example.com/deleted/"+value)
+
+			if err := linkStorage.Delete(ctx, deleted.ID); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+
+			if _, err := aliasStorage.ByValue(ctx, value); !errors.Is(err, alias.ErrNotFound) {
+				t.Fatalf("ByValue() of a deleted link error = %v, want %v", err, alias.ErrNotFound)
+			}
+
+			wantLinkID := deleted.ID
+
+			if tt.aliasTaken {
+				wantLinkID = createAliasedLink(t, linkStorage, aliasStorage, value, "https://example.com/taker/"+value).ID
+			}
+
+			if err := linkStorage.Restore(ctx, deleted.ID); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Restore() error = %v, want %v", err, tt.wantErr)
+			}
+
+			a, err := aliasStorage.ByValue(ctx, value)
+			if err != nil {
+				t.Fatalf("ByValue() error = %v", err)
+			}
+
+			if a.LinkID != wantLinkID {
+				t.Errorf("alias resolves to link %d, want %d", a.LinkID, wantLinkID)
+			}
+		})
+	}
+}
+
+func createAliasedLink(
+	t *testing.T, linkStorage link.Storage, aliasStorage alias.Storage, value, redirectURL string,
+) *link.Link {
+	t.Helper()
+
+	l, err := link.New(types.AnonymousUser().ID, redirectURL, link.ExpiresTypeNever)
+	if err != nil {
+		t.Fatalf("new link: %v", err)
+	}
+
+	a, err := alias.New(value)
+	if err != nil {
+		t.Fatalf("new alias: %v", err)
+	}
+
+	if err = aliasStorage.CreateWithLink(context.Background(), l, a); err != nil {
+		t.Fatalf("CreateWithLink() error = %v", err)
+	}
+
+	return l
+}
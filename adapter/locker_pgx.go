@@ -0,0 +1,71 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/truewebber/link-shortener/domain/lock"
+)
+
+type advisoryLockerPgx struct {
+	pool *pgxpool.Pool
+}
+
+func NewAdvisoryLockerPgx(pool *pgxpool.Pool) lock.Locker {
+	return &advisoryLockerPgx{
+		pool: pool,
+	}
+}
+
+const tryAdvisoryLock = `SELECT pg_try_advisory_lock(hashtext($1));`
+
+func (l *advisoryLockerPgx) TryAcquire(ctx context.Context, key string) (lock.Lock, error) {
+	// session level advisory lock lives as long as the connection, so the connection is held until release
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire connection: %w", err)
+	}
+
+	acquired := false
+
+	if queryErr := conn.QueryRow(ctx, tryAdvisoryLock, key).Scan(&acquired); queryErr != nil {
+		conn.Release()
+
+		return nil, fmt.Errorf("try advisory lock: %w", queryErr)
+	}
+
+	if !acquired {
+		conn.Release()
+
+		return nil, lock.ErrNotAcquired
+	}
+
+	return &advisoryLockPgx{
+		conn: conn,
+		key:  key,
+	}, nil
+}
+
+type advisoryLockPgx struct {
+	conn *pgxpool.Conn
+	key  string
+}
+
+const advisoryUnlock = `SELECT pg_advisory_unlock(hashtext($1));`
+
+func (l *advisoryLockPgx) Release(ctx context.Context) error {
+	defer l.conn.Release()
+
+	if _, err := l.conn.Exec(ctx, advisoryUnlock, l.key); err != nil {
+		// the lock can't outlive a broken session, so closing the connection releases it anyway
+		if closeErr := l.conn.Conn().Close(ctx); closeErr != nil {
+			return fmt.Errorf("%w: close connection: %w", err, closeErr)
+		}
+
+		return fmt.Errorf("advisory unlock: %w", err)
+	}
+
+	return nil
+}
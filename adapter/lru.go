@@ -0,0 +1,99 @@
+package adapter
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type lruCache[K comparable, V any] struct {
+	items map[K]*list.Element
+	order *list.List
+	mu    sync.Mutex
+	size  int
+}
+
+type lruEntry[K comparable, V any] struct {
+	expiresAt time.Time
+	value     V
+	key       K
+}
+
+func newLRUCache[K comparable, V any](size int) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		items: make(map[K]*list.Element, size),
+		order: list.New(),
+		size:  size,
+	}
+}
+
+func (c *lruCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+
+	element, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	//nolint:forcetypeassert // only lruEntry values are pushed to the list
+	entry := element.Value.(*lruEntry[K, V])
+	if !time.Now().Before(entry.expiresAt) {
+		c.removeElement(element)
+
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return entry.value, true
+}
+
+func (c *lruCache[K, V]) Add(key K, value V, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+
+	if element, ok := c.items[key]; ok {
+		//nolint:forcetypeassert // only lruEntry values are pushed to the list
+		entry := element.Value.(*lruEntry[K, V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{
+		expiresAt: expiresAt,
+		value:     value,
+		key:       key,
+	})
+
+	if c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *lruCache[K, V]) Remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+func (c *lruCache[K, V]) removeElement(element *list.Element) {
+	c.order.Remove(element)
+
+	//nolint:forcetypeassert // only lruEntry values are pushed to the list
+	delete(c.items, element.Value.(*lruEntry[K, V]).key)
+}
@@ -0,0 +1,102 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pgxpkg "github.com/truewebber/gopkg/pgx"
+
+	"github.com/truewebber/link-shortener/domain/moderation"
+)
+
+type moderationStoragePgx struct {
+	db *pgxpool.Pool
+}
+
+func NewModerationStoragePgx(db *pgxpool.Pool) moderation.Storage {
+	return &moderationStoragePgx{db: db}
+}
+
+const insertModerationActionQuery = `
+		INSERT INTO moderation_actions (moderator_id, action, target_id, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id;`
+
+func (s *moderationStoragePgx) Create(ctx context.Context, action *moderation.Action) error {
+	return insertModerationAction(ctx, s.db, action)
+}
+
+// insertModerationAction lets the link and user storages append the action in the transaction that applies it.
+func insertModerationAction(ctx context.Context, db rowQuerier, action *moderation.Action) error {
+	if err := db.QueryRow(
+		ctx,
+		insertModerationActionQuery,
+		action.ModeratorID,
+		string(action.Kind),
+		action.TargetID,
+		action.Reason,
+		action.CreatedAt,
+	).Scan(&action.ID); err != nil {
+		return fmt.Errorf("insert moderation action: %w", err)
+	}
+
+	return nil
+}
+
+const (
+	selectModerationActionsQuery = `
+		SELECT id, moderator_id, action, target_id, reason, created_at
+		FROM moderation_actions
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1 OFFSET $2;`
+	selectCountModerationActionsQuery = "SELECT count(*) FROM moderation_actions;"
+)
+
+func (s *moderationStoragePgx) List(ctx context.Context, limit, offset uint32) (moderation.List, error) {
+	list := moderation.List{}
+
+	doErr := pgxpkg.DoAtomic(ctx, s.db, func(doCtx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(doCtx, selectModerationActionsQuery, limit, offset)
+		if err != nil {
+			return fmt.Errorf("select moderation actions: %w", err)
+		}
+
+		defer rows.Close()
+
+		for rows.Next() {
+			action := moderation.Action{}
+			kind := ""
+
+			if scanErr := rows.Scan(
+				&action.ID,
+				&action.ModeratorID,
+				&kind,
+				&action.TargetID,
+				&action.Reason,
+				&action.CreatedAt,
+			); scanErr != nil {
+				return fmt.Errorf("scan moderation action: %w", scanErr)
+			}
+
+			action.Kind = moderation.Kind(kind)
+			list.Actions = append(list.Actions, action)
+		}
+
+		if rowsErr := rows.Err(); rowsErr != nil {
+			return fmt.Errorf("rows: %w", rowsErr)
+		}
+
+		if err := tx.QueryRow(doCtx, selectCountModerationActionsQuery).Scan(&list.Count); err != nil {
+			return fmt.Errorf("select count moderation actions: %w", err)
+		}
+
+		return nil
+	})
+	if doErr != nil {
+		return moderation.List{}, fmt.Errorf("select moderation actions on tx: %w", doErr)
+	}
+
+	return list, nil
+}
@@ -53,7 +53,7 @@ func NewAppleOAuthProvider(
 	}
 }
 
-func (p *appleOAuthProvider) GetAuthURL(state string) (string, error) {
+func (p *appleOAuthProvider) GetAuthURL(_ context.Context, state, _ string) (string, error) {
 	clientSecret, err := p.getClientSecret()
 	if err != nil {
 		return "", fmt.Errorf("get client secret: %w", err)
@@ -72,7 +72,7 @@ func (p *appleOAuthProvider) GetAuthURL(state string) (string, error) {
 
 var errVerifyWebTokenGotError = errors.New("error verifying WebToken")
 
-func (p *appleOAuthProvider) ExchangeCode(ctx context.Context, code string) (*user.OAuthInfo, error) {
+func (p *appleOAuthProvider) ExchangeCode(ctx context.Context, code, _ string) (*user.OAuthInfo, error) {
 	clientSecret, err := p.getClientSecret()
 	if err != nil {
 		return nil, fmt.Errorf("get client secret: %w", err)
@@ -41,11 +41,11 @@ func NewGitHubOAuthProvider(
 	}
 }
 
-func (p *gitHubOAuthProvider) GetAuthURL(state string) (string, error) {
+func (p *gitHubOAuthProvider) GetAuthURL(_ context.Context, state, _ string) (string, error) {
 	return p.config.AuthCodeURL(state), nil
 }
 
-func (p *gitHubOAuthProvider) ExchangeCode(ctx context.Context, code string) (*user.OAuthInfo, error) {
+func (p *gitHubOAuthProvider) ExchangeCode(ctx context.Context, code, _ string) (*user.OAuthInfo, error) {
 	token, err := p.config.Exchange(ctx, code)
 	if err != nil {
 		return nil, fmt.Errorf("exchange code: %w", err)
@@ -39,11 +39,11 @@ func NewGoogleOAuthProvider(clientID, clientSecret, redirectURL string, logger l
 	}
 }
 
-func (p *googleOAuthProvider) GetAuthURL(state string) (string, error) {
+func (p *googleOAuthProvider) GetAuthURL(_ context.Context, state, _ string) (string, error) {
 	return p.config.AuthCodeURL(state), nil
 }
 
-func (p *googleOAuthProvider) ExchangeCode(ctx context.Context, code string) (*user.OAuthInfo, error) {
+func (p *googleOAuthProvider) ExchangeCode(ctx context.Context, code, _ string) (*user.OAuthInfo, error) {
 	token, err := p.config.Exchange(ctx, code)
 	if err != nil {
 		return nil, fmt.Errorf("exchange code: %w", err)
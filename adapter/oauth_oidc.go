@@ -0,0 +1,556 @@
+package adapter
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/truewebber/gopkg/log"
+	"golang.org/x/oauth2"
+
+	"github.com/truewebber/link-shortener/domain/user"
+)
+
+// OIDCOptions configures an OpenID Connect provider found by discovery under IssuerURL.
+// HTTPClient is used for every call to the issuer, nil means a default client.
+// Provider is returned with the user info, it tells the configured OpenID Connect providers apart.
+type OIDCOptions struct {
+	HTTPClient   *http.Client
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	Provider     user.Provider
+}
+
+// oidcOAuthProvider guards its caches with mu, the issuer is never called while mu is held.
+type oidcOAuthProvider struct {
+	keysFetchedAt      time.Time
+	discoveryFetchedAt time.Time
+	httpClient         *http.Client
+	logger             log.Logger
+	discovery          *oidcDiscovery
+	keys               map[string]any
+	options            OIDCOptions
+	mu                 sync.Mutex
+}
+
+const oidcHTTPTimeout = 10 * time.Second
+
+// NewOIDCOAuthProvider discovers the issuer lazily, so the API starts while the issuer is unavailable.
+func NewOIDCOAuthProvider(options OIDCOptions, logger log.Logger) user.OAuthProvider {
+	httpClient := options.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: oidcHTTPTimeout}
+	}
+
+	return &oidcOAuthProvider{
+		httpClient: httpClient,
+		logger:     logger,
+		options:    options,
+	}
+}
+
+func (p *oidcOAuthProvider) GetAuthURL(ctx context.Context, state, codeVerifier string) (string, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get discovery: %w", err)
+	}
+
+	return p.buildConfig(discovery).AuthCodeURL(
+		state,
+		oauth2.S256ChallengeOption(codeVerifier),
+		oauth2.SetAuthURLParam("nonce", oidcNonce(codeVerifier)),
+	), nil
+}
+
+var errNoIDToken = errors.New("token response has no id_token")
+
+func (p *oidcOAuthProvider) ExchangeCode(ctx context.Context, code, codeVerifier string) (*user.OAuthInfo, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get discovery: %w", err)
+	}
+
+	exchangeCtx := context.WithValue(ctx, oauth2.HTTPClient, p.httpClient)
+
+	token, err := p.buildConfig(discovery).Exchange(exchangeCtx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errNoIDToken
+	}
+
+	claims, err := p.verifyIDToken(ctx, discovery, rawIDToken, oidcNonce(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("verify id token: %w", err)
+	}
+
+	if (claims.Email == "" || claims.displayName() == "") && discovery.UserinfoEndpoint != "" {
+		p.mixInUserInfo(ctx, discovery, token.AccessToken, claims)
+	}
+
+	return &user.OAuthInfo{
+		Provider:   p.options.Provider,
+		ProviderID: claims.Subject,
+		Email:      claims.Email,
+		Name:       claims.displayName(),
+		AvatarURL:  claims.Picture,
+	}, nil
+}
+
+func (p *oidcOAuthProvider) buildConfig(discovery *oidcDiscovery) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.options.ClientID,
+		ClientSecret: p.options.ClientSecret,
+		RedirectURL:  p.options.RedirectURL,
+		Scopes:       p.options.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+	}
+}
+
+// oidcNonce binds the ID token to the login the code verifier belongs to.
+func oidcNonce(codeVerifier string) string {
+	sum := sha256.Sum256([]byte("nonce:" + codeVerifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+type oidcDiscovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	UserinfoEndpoint      string   `json:"userinfo_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	SigningAlgs           []string `json:"id_token_signing_alg_values_supported"`
+}
+
+var errInvalidDiscovery = errors.New("invalid discovery document")
+
+// oidcDiscoveryTTL bounds how long endpoint and key set changes of the issuer go unnoticed.
+const oidcDiscoveryTTL = time.Hour
+
+// getDiscovery keeps serving the cached document when refreshing it fails.
+func (p *oidcOAuthProvider) getDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	cached, fetchedAt := p.discovery, p.discoveryFetchedAt
+	p.mu.Unlock()
+
+	if cached != nil && time.Since(fetchedAt) < oidcDiscoveryTTL {
+		return cached, nil
+	}
+
+	discovery, err := p.fetchDiscovery(ctx)
+	if err != nil && cached != nil {
+		p.logger.Error("failed to refresh oidc discovery, using the cached one", "error", err)
+
+		return cached, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("fetch discovery: %w", err)
+	}
+
+	p.mu.Lock()
+	p.discovery = discovery
+	p.discoveryFetchedAt = time.Now()
+	p.mu.Unlock()
+
+	return discovery, nil
+}
+
+func (p *oidcOAuthProvider) fetchDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	issuer := strings.TrimSuffix(p.options.IssuerURL, "/")
+	discovery := &oidcDiscovery{}
+
+	if err := p.getJSON(ctx, issuer+"/.well-known/openid-configuration", discovery); err != nil {
+		return nil, fmt.Errorf("get discovery document: %w", err)
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("%w: issuer %q does not match %q", errInvalidDiscovery, discovery.Issuer, issuer)
+	}
+
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: missing endpoints", errInvalidDiscovery)
+	}
+
+	return discovery, nil
+}
+
+type oidcClaims struct {
+	Nonce             string `json:"nonce"`
+	AuthorizedParty   string `json:"azp"`
+	Email             string `json:"email"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Picture           string `json:"picture"`
+	jwt.RegisteredClaims
+}
+
+func (c *oidcClaims) displayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+
+	return c.PreferredUsername
+}
+
+var (
+	errInvalidNonce           = errors.New("invalid nonce")
+	errInvalidAuthorizedParty = errors.New("invalid authorized party")
+	errNoSubject              = errors.New("no subject")
+)
+
+const idTokenLeeway = time.Minute
+
+func (p *oidcOAuthProvider) verifyIDToken(
+	ctx context.Context, discovery *oidcDiscovery, rawIDToken, nonce string,
+) (*oidcClaims, error) {
+	claims := &oidcClaims{}
+
+	_, err := jwt.ParseWithClaims(
+		rawIDToken,
+		claims,
+		func(token *jwt.Token) (any, error) {
+			return p.getKey(ctx, discovery, tokenKeyID(token))
+		},
+		jwt.WithValidMethods(oidcSigningAlgs(discovery)),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.options.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(idTokenLeeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("parse id token: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, errInvalidNonce
+	}
+
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.options.ClientID {
+		return nil, errInvalidAuthorizedParty
+	}
+
+	if claims.Subject == "" {
+		return nil, errNoSubject
+	}
+
+	return claims, nil
+}
+
+// tokenKeyID returns an empty key id for a token whose header has none.
+func tokenKeyID(token *jwt.Token) string {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return ""
+	}
+
+	return kid
+}
+
+// oidcSupportedAlgs lists asymmetric algorithms only, a client secret never verifies an ID token here.
+var oidcSupportedAlgs = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+func oidcSigningAlgs(discovery *oidcDiscovery) []string {
+	if len(discovery.SigningAlgs) == 0 {
+		return []string{"RS256"}
+	}
+
+	algs := make([]string, 0, len(discovery.SigningAlgs))
+
+	for _, alg := range discovery.SigningAlgs {
+		if slices.Contains(oidcSupportedAlgs, alg) {
+			algs = append(algs, alg)
+		}
+	}
+
+	return algs
+}
+
+var errUnknownKey = errors.New("unknown signing key")
+
+// jwksRefreshInterval limits refetching the key set when tokens carry unknown key ids.
+const jwksRefreshInterval = time.Minute
+
+func (p *oidcOAuthProvider) getKey(ctx context.Context, discovery *oidcDiscovery, kid string) (any, error) {
+	key, refresh := p.cachedKey(kid)
+	if key != nil {
+		return key, nil
+	}
+
+	if !refresh {
+		return nil, fmt.Errorf("%w: %q", errUnknownKey, kid)
+	}
+
+	keys, err := p.fetchKeys(ctx, discovery.JWKSURI)
+	if err != nil {
+		return nil, fmt.Errorf("fetch keys: %w", err)
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	key, found := p.lookupKey(kid)
+	p.mu.Unlock()
+
+	if !found {
+		return nil, fmt.Errorf("%w: %q", errUnknownKey, kid)
+	}
+
+	return key, nil
+}
+
+// cachedKey reports whether the key set is due for a refetch when kid is unknown, the caller then fetches it.
+func (p *oidcOAuthProvider) cachedKey(kid string) (any, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, false
+	}
+
+	if p.keys != nil && time.Since(p.keysFetchedAt) < jwksRefreshInterval {
+		return nil, false
+	}
+
+	p.keysFetchedAt = time.Now()
+
+	return nil, true
+}
+
+// lookupKey accepts a token without kid only when the issuer publishes a single key.
+func (p *oidcOAuthProvider) lookupKey(kid string) (any, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[kid]
+
+	return key, ok
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+func (p *oidcOAuthProvider) fetchKeys(ctx context.Context, jwksURI string) (map[string]any, error) {
+	set := &jsonWebKeySet{}
+
+	if err := p.getJSON(ctx, jwksURI, set); err != nil {
+		return nil, fmt.Errorf("get key set: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+
+	for i := range set.Keys {
+		jwk := &set.Keys[i]
+
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			p.logger.Error("skipping unsupported json web key", "kid", jwk.Kid, "kty", jwk.Kty, "error", err)
+
+			continue
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	return keys, nil
+}
+
+var errUnsupportedKey = errors.New("unsupported key")
+
+func (k *jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("decode modulus: %w", err)
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("decode exponent: %w", err)
+		}
+
+		if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+			return nil, fmt.Errorf("%w: exponent out of range", errUnsupportedKey)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		return k.ecdsaPublicKey()
+	}
+
+	return nil, fmt.Errorf("%w: kty %q", errUnsupportedKey, k.Kty)
+}
+
+func (k *jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("%w: crv %q", errUnsupportedKey, k.Crv)
+	}
+
+	x, err := decodeBigInt(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("decode x: %w", err)
+	}
+
+	y, err := decodeBigInt(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("decode y: %w", err)
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("decode base64: %w", err)
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+type oidcUserInfo struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Picture           string `json:"picture"`
+}
+
+// mixInUserInfo fills profile claims the ID token left out, the verified ID token stays enough to log in.
+func (p *oidcOAuthProvider) mixInUserInfo(
+	ctx context.Context, discovery *oidcDiscovery, accessToken string, claims *oidcClaims,
+) {
+	info := &oidcUserInfo{}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discovery.UserinfoEndpoint, http.NoBody)
+	if err != nil {
+		p.logger.Error("failed to create user info request", "error", err)
+
+		return
+	}
+
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+
+	if err := p.doJSON(req, info); err != nil {
+		p.logger.Error("failed to get oidc user info", "error", err)
+
+		return
+	}
+
+	if info.Subject != claims.Subject {
+		p.logger.Error("oidc user info subject mismatch", "sub", claims.Subject, "user_info_sub", info.Subject)
+
+		return
+	}
+
+	if claims.Email == "" {
+		claims.Email = info.Email
+	}
+
+	if claims.Name == "" {
+		claims.Name = info.Name
+	}
+
+	if claims.PreferredUsername == "" {
+		claims.PreferredUsername = info.PreferredUsername
+	}
+
+	if claims.Picture == "" {
+		claims.Picture = info.Picture
+	}
+}
+
+func (p *oidcOAuthProvider) getJSON(ctx context.Context, url string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	return p.doJSON(req, dst)
+}
+
+const maxOIDCResponseSize = 1 << 20
+
+func (p *oidcOAuthProvider) doJSON(req *http.Request, dst any) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			p.logger.Error("failed to close response body", "err", closeErr)
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOIDCResponseSize))
+	if err != nil {
+		return fmt.Errorf("read body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w %d, body: %s", errNonOKStatusCode, resp.StatusCode, string(body))
+	}
+
+	if err := json.Unmarshal(body, dst); err != nil {
+		return fmt.Errorf("unmarshal body: %w", err)
+	}
+
+	return nil
+}
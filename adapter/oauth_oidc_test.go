@@ -0,0 +1,170 @@
+package adapter_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"maps"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/adapter"
+	"github.com/truewebber/link-shortener/domain/user"
+)
+
+func TestOIDCExchangeCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		claims       jwt.MapClaims
+		name         string
+		signingKeyID string
+		codeVerifier string
+		wantErr      bool
+	}{
+		{
+			name:         "Return the subject of a verified id token",
+			codeVerifier: "code-verifier-of-the-login-that-is-long-enough",
+		},
+		{
+			name:         "Return error if the code verifier does not match the challenge",
+			codeVerifier: "code-verifier-of-another-login",
+			wantErr:      true,
+		},
+		{
+			name:         "Return error if the id token nonce belongs to another login",
+			claims:       jwt.MapClaims{"nonce": "nonce-of-another-login"},
+			codeVerifier: "code-verifier-of-the-login-that-is-long-enough",
+			wantErr:      true,
+		},
+		{
+			name:         "Return error if the id token is issued to another client",
+			claims:       jwt.MapClaims{"aud": "another-client"},
+			codeVerifier: "code-verifier-of-the-login-that-is-long-enough",
+			wantErr:      true,
+		},
+		{
+			name:         "Return error if the id token expired",
+			claims:       jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()},
+			codeVerifier: "code-verifier-of-the-login-that-is-long-enough",
+			wantErr:      true,
+		},
+		{
+			name:         "Return error if the id token names a key missing from the key set",
+			signingKeyID: "key-2",
+			codeVerifier: "code-verifier-of-the-login-that-is-long-enough",
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			issuer := newFakeIssuer(t, "client-id", "authorization-code", "code-verifier-of-the-login-that-is-long-enough")
+			provider := adapter.NewOIDCOAuthProvider(adapter.OIDCOptions{
+				HTTPClient:  issuer.server.Client(),
+				IssuerURL:   issuer.server.URL,
+				ClientID:    "client-id",
+				RedirectURL: "https://short.example/api/auth/oidc/callback",
+				Scopes:      []string{"openid", "email"},
+				Provider:    user.OIDCProvider(1),
+			}, log.NewLogger())
+
+			authURL, err := provider.GetAuthURL(context.Background(), "state", "code-verifier-of-the-login-that-is-long-enough")
+			if err != nil {
+				t.Fatalf("GetAuthURL() error = %v", err)
+			}
+
+			parsed, err := url.Parse(authURL)
+			if err != nil {
+				t.Fatalf("parse auth url: %v", err)
+			}
+
+			issuer.claims["nonce"] = parsed.Query().Get("nonce")
+			maps.Copy(issuer.claims, tt.claims)
+
+			if tt.signingKeyID != "" {
+				issuer.signingKeyID = tt.signingKeyID
+			}
+
+			info, err := provider.ExchangeCode(context.Background(), "authorization-code", tt.codeVerifier)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExchangeCode() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if info.ProviderID != "subject-1" || info.Provider != user.OIDCProvider(1) {
+				t.Errorf("ExchangeCode() = %q of %d, want %q of %d",
+					info.ProviderID, info.Provider, "subject-1", user.OIDCProvider(1))
+			}
+		})
+	}
+}
+
+func TestOIDCGetAuthURL(t *testing.T) {
+	t.Parallel()
+
+	codeVerifier := "code-verifier-of-the-login-that-is-long-enough"
+	issuer := newFakeIssuer(t, "client-id", "authorization-code", codeVerifier)
+	provider := adapter.NewOIDCOAuthProvider(adapter.OIDCOptions{
+		HTTPClient:  issuer.server.Client(),
+		IssuerURL:   issuer.server.URL,
+		ClientID:    "client-id",
+		RedirectURL: "https://short.example/api/auth/oidc/callback",
+	}, log.NewLogger())
+
+	authURL, err := provider.GetAuthURL(context.Background(), "state", codeVerifier)
+	if err != nil {
+		t.Fatalf("GetAuthURL() error = %v", err)
+	}
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	query := parsed.Query()
+
+	if query.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(challenge[:]) ||
+		query.Get("code_challenge_method") != "S256" {
+		t.Errorf("auth url %q does not carry the S256 challenge of the verifier", authURL)
+	}
+
+	if query.Get("nonce") == "" || query.Get("nonce") == codeVerifier {
+		t.Errorf("auth url %q does not carry a nonce derived from the verifier", authURL)
+	}
+
+	if _, err = provider.GetAuthURL(context.Background(), "state", codeVerifier); err != nil {
+		t.Fatalf("GetAuthURL() error = %v", err)
+	}
+
+	if requests := issuer.discoveryRequests.Load(); requests != 1 {
+		t.Errorf("discovery fetched %d times, want it cached after the first login", requests)
+	}
+}
+
+func TestOIDCGetAuthURLIssuerMismatch(t *testing.T) {
+	t.Parallel()
+
+	issuer := newFakeIssuer(t, "client-id", "authorization-code", "code-verifier-of-the-login-that-is-long-enough")
+	issuer.discoveryIssuer = "https://another-issuer.example"
+
+	provider := adapter.NewOIDCOAuthProvider(adapter.OIDCOptions{
+		HTTPClient: issuer.server.Client(),
+		IssuerURL:  issuer.server.URL,
+		ClientID:   "client-id",
+	}, log.NewLogger())
+
+	if _, err := provider.GetAuthURL(context.Background(), "state", "code-verifier"); err == nil {
+		t.Fatal("GetAuthURL() error = nil, want an error for a discovery document of another issuer")
+	}
+}
@@ -0,0 +1,190 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/truewebber/link-shortener/domain/pat"
+)
+
+type patStoragePgx struct {
+	db *pgxpool.Pool
+}
+
+func NewPersonalTokenStoragePgx(db *pgxpool.Pool) pat.Storage {
+	return &patStoragePgx{db: db}
+}
+
+const insertPersonalTokenQuery = `
+			INSERT INTO personal_tokens (user_id, name, secret_hash, scopes, expires_at, created_at, deleted)
+			VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, false)
+			RETURNING id, created_at;`
+
+func (s *patStoragePgx) Create(ctx context.Context, token *pat.Token) error {
+	if err := s.db.QueryRow(
+		ctx,
+		insertPersonalTokenQuery,
+		token.UserID,
+		token.Name,
+		token.SecretHash,
+		scopesToPgx(token.Scopes),
+		token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt); err != nil {
+		return fmt.Errorf("insert personal token: %w", err)
+	}
+
+	return nil
+}
+
+const selectPersonalTokenByIDQuery = `
+		SELECT id, user_id, name, secret_hash, scopes, expires_at, last_used_at, created_at
+		FROM personal_tokens
+		WHERE id = $1 AND NOT deleted;`
+
+func (s *patStoragePgx) ByID(ctx context.Context, id uint64) (*pat.Token, error) {
+	t, err := s.selectToken(ctx, selectPersonalTokenByIDQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("select personal token by id: %w", err)
+	}
+
+	return t, nil
+}
+
+//nolint:gosec // false positive, the query holds no secret
+const selectPersonalTokenBySecretHashQuery = `
+		SELECT id, user_id, name, secret_hash, scopes, expires_at, last_used_at, created_at
+		FROM personal_tokens
+		WHERE secret_hash = $1 AND NOT deleted;`
+
+func (s *patStoragePgx) BySecretHash(ctx context.Context, secretHash string) (*pat.Token, error) {
+	t, err := s.selectToken(ctx, selectPersonalTokenBySecretHashQuery, secretHash)
+	if err != nil {
+		return nil, fmt.Errorf("select personal token by secret hash: %w", err)
+	}
+
+	return t, nil
+}
+
+func (s *patStoragePgx) selectToken(ctx context.Context, sql string, arg any) (*pat.Token, error) {
+	t, err := scanPersonalToken(s.db.QueryRow(ctx, sql, arg))
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, pat.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("select personal token: %w", err)
+	}
+
+	return t, nil
+}
+
+const selectPersonalTokensByUserIDQuery = `
+		SELECT id, user_id, name, secret_hash, scopes, expires_at, last_used_at, created_at
+		FROM personal_tokens
+		WHERE user_id = $1 AND NOT deleted
+		ORDER BY created_at DESC;`
+
+func (s *patStoragePgx) ByUserID(ctx context.Context, userID uint64) ([]pat.Token, error) {
+	rows, err := s.db.Query(ctx, selectPersonalTokensByUserIDQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("select personal tokens by user id: %w", err)
+	}
+
+	defer rows.Close()
+
+	var tokens []pat.Token
+
+	for rows.Next() {
+		t, scanErr := scanPersonalToken(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("scan personal token: %w", scanErr)
+		}
+
+		tokens = append(tokens, *t)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("rows: %w", rowsErr)
+	}
+
+	return tokens, nil
+}
+
+func scanPersonalToken(row pgx.Row) (*pat.Token, error) {
+	var (
+		t      pat.Token
+		scopes []string
+	)
+
+	if err := row.Scan(
+		&t.ID,
+		&t.UserID,
+		&t.Name,
+		&t.SecretHash,
+		&scopes,
+		&t.ExpiresAt,
+		&t.LastUsedAt,
+		&t.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("scan row: %w", err)
+	}
+
+	t.Scopes = make([]pat.Scope, 0, len(scopes))
+	for _, scope := range scopes {
+		t.Scopes = append(t.Scopes, pat.Scope(scope))
+	}
+
+	return &t, nil
+}
+
+func scopesToPgx(scopes []pat.Scope) []string {
+	values := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		values = append(values, string(scope))
+	}
+
+	return values
+}
+
+const setPersonalTokenDeletedByIDQuery = "UPDATE personal_tokens SET deleted = true WHERE id = $1 AND NOT deleted;"
+
+func (s *patStoragePgx) Delete(ctx context.Context, id uint64) error {
+	cmd, err := s.db.Exec(ctx, setPersonalTokenDeletedByIDQuery, id)
+	if err != nil {
+		return fmt.Errorf("exec update set personal token deleted by id: %w", err)
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return pat.ErrNotFound
+	}
+
+	return nil
+}
+
+const setPersonalTokensDeletedByUserIDQuery = `
+		UPDATE personal_tokens SET deleted = true
+		WHERE user_id = $1 AND NOT deleted;`
+
+func (s *patStoragePgx) DeleteByUserID(ctx context.Context, userID uint64) error {
+	if _, err := s.db.Exec(ctx, setPersonalTokensDeletedByUserIDQuery, userID); err != nil {
+		return fmt.Errorf("exec update set personal tokens deleted by user id: %w", err)
+	}
+
+	return nil
+}
+
+const updatePersonalTokenLastUsedAtQuery = "UPDATE personal_tokens SET last_used_at = $2 WHERE id = $1;"
+
+func (s *patStoragePgx) Touch(ctx context.Context, id uint64, usedAt time.Time) error {
+	if _, err := s.db.Exec(ctx, updatePersonalTokenLastUsedAtQuery, id, usedAt); err != nil {
+		return fmt.Errorf("exec update personal token last used at: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package adapter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/truewebber/link-shortener/domain/ratelimit"
+)
+
+type memoryRateLimitStore struct {
+	buckets   map[string]time.Time
+	lastPurge time.Time
+	mu        sync.Mutex
+}
+
+// NewMemoryRateLimitStore keeps buckets of this process only, full buckets are dropped about once a minute.
+func NewMemoryRateLimitStore() ratelimit.Store {
+	return &memoryRateLimitStore{
+		buckets:   make(map[string]time.Time),
+		lastPurge: time.Now(),
+	}
+}
+
+const memoryRateLimitPurgeInterval = time.Minute
+
+func (s *memoryRateLimitStore) Take(_ context.Context, key string, limit ratelimit.Limit) (ratelimit.Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if now.Sub(s.lastPurge) >= memoryRateLimitPurgeInterval {
+		s.purge(now, 0)
+		s.lastPurge = now
+	}
+
+	fullAt, decision := ratelimit.Allow(now, s.buckets[key], limit)
+	if decision.Allowed {
+		s.buckets[key] = fullAt
+	}
+
+	return decision, nil
+}
+
+func (s *memoryRateLimitStore) PurgeExpired(_ context.Context, limit uint32) (uint32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.purge(time.Now(), limit), nil
+}
+
+// purge drops full buckets, zero limit drops all of them.
+func (s *memoryRateLimitStore) purge(now time.Time, limit uint32) uint32 {
+	purged := uint32(0)
+
+	for key, fullAt := range s.buckets {
+		if limit != 0 && purged == limit {
+			break
+		}
+
+		if !fullAt.After(now) {
+			delete(s.buckets, key)
+			purged++
+		}
+	}
+
+	return purged
+}
@@ -0,0 +1,82 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/truewebber/link-shortener/domain/ratelimit"
+)
+
+type rateLimitStorePgx struct {
+	pool *pgxpool.Pool
+}
+
+// NewRateLimitStorePgx shares buckets between replicas, every request costs one round trip.
+func NewRateLimitStorePgx(pool *pgxpool.Pool) ratelimit.Store {
+	return &rateLimitStorePgx{
+		pool: pool,
+	}
+}
+
+// takeRateLimitToken moves full_at one interval ahead unless that puts it more than a period away.
+const takeRateLimitToken = `
+		INSERT INTO rate_limits AS r (key, full_at)
+		VALUES ($1, CURRENT_TIMESTAMP + $2::bigint * interval '1 microsecond')
+		ON CONFLICT (key) DO UPDATE
+		SET full_at = GREATEST(r.full_at, CURRENT_TIMESTAMP) + $2::bigint * interval '1 microsecond'
+		WHERE GREATEST(r.full_at, CURRENT_TIMESTAMP) + $2::bigint * interval '1 microsecond'
+			<= CURRENT_TIMESTAMP + $3::bigint * interval '1 microsecond'
+		RETURNING full_at;`
+
+const selectRateLimitWait = `SELECT EXTRACT(EPOCH FROM (full_at - CURRENT_TIMESTAMP)) FROM rate_limits WHERE key = $1;`
+
+func (s *rateLimitStorePgx) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Decision, error) {
+	interval := limit.Interval()
+
+	var fullAt time.Time
+
+	err := s.pool.QueryRow(
+		ctx, takeRateLimitToken, key, interval.Microseconds(), limit.Period.Microseconds(),
+	).Scan(&fullAt)
+	if err == nil {
+		return ratelimit.Decision{Allowed: true}, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return ratelimit.Decision{}, fmt.Errorf("take rate limit token: %w", err)
+	}
+
+	var waitSeconds float64
+
+	if err := s.pool.QueryRow(ctx, selectRateLimitWait, key).Scan(&waitSeconds); err != nil {
+		return ratelimit.Decision{}, fmt.Errorf("select rate limit wait: %w", err)
+	}
+
+	wait := time.Duration(waitSeconds * float64(time.Second))
+
+	return ratelimit.Decision{RetryAfter: max(wait+interval-limit.Period, 0)}, nil
+}
+
+const deleteFullRateLimitsBatch = `
+		DELETE FROM rate_limits
+		WHERE key IN (
+			SELECT key FROM rate_limits
+			WHERE full_at <= CURRENT_TIMESTAMP
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		);`
+
+func (s *rateLimitStorePgx) PurgeExpired(ctx context.Context, limit uint32) (uint32, error) {
+	cmd, err := s.pool.Exec(ctx, deleteFullRateLimitsBatch, limit)
+	if err != nil {
+		return 0, fmt.Errorf("exec delete full rate limits: %w", err)
+	}
+
+	//nolint:gosec // rows affected is bounded by limit
+	return uint32(cmd.RowsAffected()), nil
+}
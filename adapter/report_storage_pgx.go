@@ -0,0 +1,190 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pgxpkg "github.com/truewebber/gopkg/pgx"
+
+	"github.com/truewebber/link-shortener/domain/report"
+)
+
+type reportStoragePgx struct {
+	db *pgxpool.Pool
+}
+
+func NewReportStoragePgx(db *pgxpool.Pool) report.Storage {
+	return &reportStoragePgx{db: db}
+}
+
+const insertReportQuery = `
+		INSERT INTO reports (link_id, reporter_id, reason, contact, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id;`
+
+func (s *reportStoragePgx) Create(ctx context.Context, r *report.Report) error {
+	err := s.db.QueryRow(
+		ctx,
+		insertReportQuery,
+		r.LinkID,
+		r.ReporterID,
+		r.Reason,
+		r.Contact,
+		r.CreatedAt,
+	).Scan(&r.ID)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolationCode {
+		return report.ErrAlreadyReported
+	}
+
+	if err != nil {
+		return fmt.Errorf("insert report: %w", err)
+	}
+
+	return nil
+}
+
+const selectOpenReportersByLinkIDQuery = "SELECT count(*) FROM reports WHERE link_id = $1 AND resolved_at IS NULL;"
+
+func (s *reportStoragePgx) Reporters(ctx context.Context, linkID uint64) (uint32, error) {
+	var reporters uint32
+
+	if err := s.db.QueryRow(ctx, selectOpenReportersByLinkIDQuery, linkID).Scan(&reporters); err != nil {
+		return 0, fmt.Errorf("select open reporters by link id: %w", err)
+	}
+
+	return reporters, nil
+}
+
+// selectOpenReportsQuery puts the links most reporters agree on first, with a few of their newest reports.
+const (
+	selectOpenReportsQuery = `
+		SELECT q.link_id, u.redirect_url, u.blocked_at, q.reporters, q.first_reported_at, q.last_reported_at,
+		       latest.reports
+		FROM (
+			SELECT link_id, count(*) AS reporters, min(created_at) AS first_reported_at,
+			       max(created_at) AS last_reported_at
+			FROM reports
+			WHERE resolved_at IS NULL
+			GROUP BY link_id
+		) q
+		JOIN urls u ON u.id = q.link_id AND NOT u.deleted
+		CROSS JOIN LATERAL (
+			SELECT json_agg(json_build_object(
+				'id', r.id,
+				'reason', r.reason,
+				'contact', r.contact,
+				'created_at_ms', (extract(EPOCH FROM r.created_at) * 1000)::bigint
+			) ORDER BY r.created_at DESC) AS reports
+			FROM (
+				SELECT id, reason, contact, created_at
+				FROM reports
+				WHERE link_id = q.link_id AND resolved_at IS NULL
+				ORDER BY created_at DESC
+				LIMIT $3
+			) r
+		) latest
+		ORDER BY q.reporters DESC, q.last_reported_at DESC
+		LIMIT $1 OFFSET $2;`
+
+	selectCountOpenReportsQuery = `
+		SELECT count(DISTINCT r.link_id)
+		FROM reports r
+		JOIN urls u ON u.id = r.link_id AND NOT u.deleted
+		WHERE r.resolved_at IS NULL;`
+
+	latestReportsPerLink = 5
+)
+
+type latestReportPgx struct {
+	Reason      string `json:"reason"`
+	Contact     string `json:"contact"`
+	ID          uint64 `json:"id"`
+	CreatedAtMS int64  `json:"created_at_ms"`
+}
+
+func (s *reportStoragePgx) Open(ctx context.Context, limit, offset uint32) (report.Queue, error) {
+	queue := report.Queue{}
+
+	doErr := pgxpkg.DoAtomic(ctx, s.db, func(doCtx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(doCtx, selectOpenReportsQuery, limit, offset, latestReportsPerLink)
+		if err != nil {
+			return fmt.Errorf("select open reports: %w", err)
+		}
+
+		defer rows.Close()
+
+		for rows.Next() {
+			linkReports, scanErr := scanLinkReports(rows)
+			if scanErr != nil {
+				return fmt.Errorf("scan link reports: %w", scanErr)
+			}
+
+			queue.Links = append(queue.Links, *linkReports)
+		}
+
+		if rowsErr := rows.Err(); rowsErr != nil {
+			return fmt.Errorf("rows: %w", rowsErr)
+		}
+
+		if err := tx.QueryRow(doCtx, selectCountOpenReportsQuery).Scan(&queue.Count); err != nil {
+			return fmt.Errorf("select count open reports: %w", err)
+		}
+
+		return nil
+	})
+	if doErr != nil {
+		return report.Queue{}, fmt.Errorf("select open reports on tx: %w", doErr)
+	}
+
+	return queue, nil
+}
+
+func scanLinkReports(row pgx.Row) (*report.LinkReports, error) {
+	linkReports := &report.LinkReports{}
+
+	var latest []latestReportPgx
+
+	if err := row.Scan(
+		&linkReports.LinkID,
+		&linkReports.RedirectURL,
+		&linkReports.BlockedAt,
+		&linkReports.Reporters,
+		&linkReports.FirstReportedAt,
+		&linkReports.LastReportedAt,
+		&latest,
+	); err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	linkReports.Latest = make([]report.Report, 0, len(latest))
+
+	for _, l := range latest {
+		linkReports.Latest = append(linkReports.Latest, report.Report{
+			CreatedAt: time.UnixMilli(l.CreatedAtMS).UTC(),
+			Reason:    l.Reason,
+			Contact:   l.Contact,
+			ID:        l.ID,
+			LinkID:    linkReports.LinkID,
+		})
+	}
+
+	return linkReports, nil
+}
+
+const resolveReportsByLinkIDQuery = `
+		UPDATE reports SET resolved_at = CURRENT_TIMESTAMP WHERE link_id = $1 AND resolved_at IS NULL;`
+
+func (s *reportStoragePgx) Resolve(ctx context.Context, linkID uint64) error {
+	if _, err := s.db.Exec(ctx, resolveReportsByLinkIDQuery, linkID); err != nil {
+		return fmt.Errorf("resolve reports by link id: %w", err)
+	}
+
+	return nil
+}
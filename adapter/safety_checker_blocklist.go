@@ -0,0 +1,349 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/truewebber/gopkg/log"
+	"github.com/truewebber/gopkg/starter"
+
+	"github.com/truewebber/link-shortener/domain/safety"
+)
+
+type SafetyCheckerServer interface {
+	safety.Checker
+	starter.Server
+}
+
+// BlocklistOptions leaves a list out when its file is empty, the files are reread when they change.
+type BlocklistOptions struct {
+	DomainsFile    string
+	URLPrefixFile  string
+	PatternsFile   string
+	ReloadInterval time.Duration
+}
+
+// blocklistFiles are the domains, url prefixes and patterns files.
+const blocklistFiles = 3
+
+type blocklist struct {
+	domains  map[string]struct{}
+	prefixes []string
+	patterns []*regexp.Regexp
+	modTimes [blocklistFiles]time.Time
+}
+
+type blocklistChecker struct {
+	list     atomic.Pointer[blocklist]
+	logger   log.Logger
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+	options  BlocklistOptions
+}
+
+// MustNewBlocklistChecker panics when a configured list can not be read at start,
+// a list that breaks later is logged and the previous one is kept.
+func MustNewBlocklistChecker(options BlocklistOptions, logger log.Logger) SafetyCheckerServer {
+	list, err := loadBlocklist(options)
+	if err != nil {
+		panic(fmt.Errorf("load blocklist: %w", err))
+	}
+
+	checker := &blocklistChecker{
+		logger:  logger,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		options: options,
+	}
+	checker.list.Store(list)
+
+	return checker
+}
+
+func (c *blocklistChecker) Check(_ context.Context, rawURL string) (safety.Verdict, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return safety.Unsafe("malformed url"), nil //nolint:nilerr // a url nobody can parse is not safe to send to
+	}
+
+	if verdict := checkTarget(u); !verdict.Safe {
+		return verdict, nil
+	}
+
+	return c.list.Load().check(u, rawURL), nil
+}
+
+// checkTarget turns away what no blocklist is needed for: scripts, inline data and hosts on private networks.
+func checkTarget(u *url.URL) safety.Verdict {
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return safety.Unsafe("scheme " + scheme + " is not allowed")
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") ||
+		strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal") {
+		return safety.Unsafe("private network host")
+	}
+
+	addr, ok := parseHostAddr(host)
+	if !ok {
+		return safety.Safe()
+	}
+
+	if addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsUnspecified() || addr.IsMulticast() || sharedAddressSpace.Contains(addr) {
+		return safety.Unsafe("private network address")
+	}
+
+	return safety.Safe()
+}
+
+// net4Len is the number of bytes of an IPv4 address.
+const net4Len = 4
+
+// parseHostAddr also reads the IPv4 shorthands browsers resolve the way inet_aton does,
+// like 2130706433, 0x7f.1 or 0177.0.0.1.
+func parseHostAddr(host string) (netip.Addr, bool) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addr.Unmap(), true
+	}
+
+	parts := strings.Split(host, ".")
+	if len(parts) > net4Len {
+		return netip.Addr{}, false
+	}
+
+	var ip uint32
+
+	for i, part := range parts {
+		// the last part fills every byte the parts before it left
+		bits := 8
+		if i == len(parts)-1 {
+			bits = 8 * (net4Len - i)
+		}
+
+		value, ok := parseInetAtonPart(part, bits)
+		if !ok {
+			return netip.Addr{}, false
+		}
+
+		ip = ip<<bits | value
+	}
+
+	return netip.AddrFrom4([net4Len]byte{byte(ip >> 24), byte(ip >> 16), byte(ip >> 8), byte(ip)}), true
+}
+
+// parseInetAtonPart reads a decimal, 0x prefixed hex or 0 prefixed octal part that fits bits.
+func parseInetAtonPart(part string, bits int) (uint32, bool) {
+	base := 10
+
+	switch {
+	case len(part) > 2 && (part[:2] == "0x" || part[:2] == "0X"):
+		base, part = 16, part[2:]
+	case len(part) > 1 && part[0] == '0':
+		base, part = 8, part[1:]
+	}
+
+	value, err := strconv.ParseUint(part, base, bits)
+	if err != nil {
+		return 0, false
+	}
+
+	return uint32(value), true //nolint:gosec // value fits bits, which are at most 32
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, netip does not count it as private.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+func (l *blocklist) check(u *url.URL, rawURL string) safety.Verdict {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+
+	// a blocked domain covers its subdomains as well
+	for domain := host; domain != ""; {
+		if _, blocked := l.domains[domain]; blocked {
+			return safety.Unsafe("domain " + domain + " is blocklisted")
+		}
+
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+
+		domain = parent
+	}
+
+	for _, prefix := range l.prefixes {
+		if strings.HasPrefix(rawURL, prefix) {
+			return safety.Unsafe("url prefix " + prefix + " is blocklisted")
+		}
+	}
+
+	for _, pattern := range l.patterns {
+		if pattern.MatchString(rawURL) {
+			return safety.Unsafe("url pattern " + pattern.String() + " is blocklisted")
+		}
+	}
+
+	return safety.Safe()
+}
+
+func (c *blocklistChecker) Serve() error {
+	defer close(c.done)
+
+	if c.options.ReloadInterval <= 0 {
+		<-c.stop
+
+		return nil
+	}
+
+	ticker := time.NewTicker(c.options.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.reload()
+		case <-c.stop:
+			return nil
+		}
+	}
+}
+
+func (c *blocklistChecker) Shutdown() error {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+
+	<-c.done
+
+	return nil
+}
+
+func (c *blocklistChecker) reload() {
+	modTimes, err := blocklistModTimes(c.options)
+	if err != nil {
+		c.logger.Error("failed to stat blocklist files", "error", err)
+
+		return
+	}
+
+	if modTimes == c.list.Load().modTimes {
+		return
+	}
+
+	list, err := loadBlocklist(c.options)
+	if err != nil {
+		c.logger.Error("failed to reload blocklist, keeping the previous one", "error", err)
+
+		return
+	}
+
+	c.list.Store(list)
+
+	c.logger.Info(
+		"blocklist reloaded",
+		"domains", len(list.domains),
+		"url_prefixes", len(list.prefixes),
+		"patterns", len(list.patterns),
+	)
+}
+
+func blocklistModTimes(options BlocklistOptions) ([blocklistFiles]time.Time, error) {
+	modTimes := [blocklistFiles]time.Time{}
+
+	for i, path := range []string{options.DomainsFile, options.URLPrefixFile, options.PatternsFile} {
+		if path == "" {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return modTimes, fmt.Errorf("stat %s: %w", path, err)
+		}
+
+		modTimes[i] = info.ModTime()
+	}
+
+	return modTimes, nil
+}
+
+func loadBlocklist(options BlocklistOptions) (*blocklist, error) {
+	modTimes, err := blocklistModTimes(options)
+	if err != nil {
+		return nil, fmt.Errorf("blocklist mod times: %w", err)
+	}
+
+	domains, err := readBlocklistFile(options.DomainsFile)
+	if err != nil {
+		return nil, fmt.Errorf("read domains: %w", err)
+	}
+
+	prefixes, err := readBlocklistFile(options.URLPrefixFile)
+	if err != nil {
+		return nil, fmt.Errorf("read url prefixes: %w", err)
+	}
+
+	rawPatterns, err := readBlocklistFile(options.PatternsFile)
+	if err != nil {
+		return nil, fmt.Errorf("read patterns: %w", err)
+	}
+
+	list := &blocklist{
+		domains:  make(map[string]struct{}, len(domains)),
+		prefixes: prefixes,
+		patterns: make([]*regexp.Regexp, 0, len(rawPatterns)),
+		modTimes: modTimes,
+	}
+
+	for _, domain := range domains {
+		list.domains[strings.TrimSuffix(strings.ToLower(domain), ".")] = struct{}{}
+	}
+
+	for _, rawPattern := range rawPatterns {
+		pattern, compileErr := regexp.Compile(rawPattern)
+		if compileErr != nil {
+			return nil, fmt.Errorf("compile pattern %q: %w", rawPattern, compileErr)
+		}
+
+		list.patterns = append(list.patterns, pattern)
+	}
+
+	return list, nil
+}
+
+// readBlocklistFile returns one entry per line, blank lines and lines starting with # are skipped.
+func readBlocklistFile(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	var entries []string
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entries = append(entries, line)
+	}
+
+	return entries, nil
+}
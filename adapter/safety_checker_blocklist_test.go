@@ -0,0 +1,114 @@
+package adapter_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/adapter"
+)
+
+func TestBlocklistCheckerCheckTarget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		rawURL   string
+		wantSafe bool
+	}{
+		{
+			name:     "Accept a public https url",
+			rawURL:   "https://example.com/path?q=1",
+			wantSafe: true,
+		},
+		{
+			name:     "Accept a public IPv4 address",
+			rawURL:   "http://8.8.8.8/",
+			wantSafe: true,
+		},
+		{
+			name:     "Accept a public IPv4 address written as a single number",
+			rawURL:   "http://134744072/",
+			wantSafe: true,
+		},
+		{
+			name:     "Accept a host of more numeric labels than an IPv4 address has",
+			rawURL:   "http://1.2.3.4.5/",
+			wantSafe: true,
+		},
+		{
+			name:   "Return unsafe if the scheme is not http",
+			rawURL: "javascript:alert(1)",
+		},
+		{
+			name:   "Return unsafe if the host is localhost",
+			rawURL: "http://localhost:8080/",
+		},
+		{
+			name:   "Return unsafe if the host is an internal name",
+			rawURL: "http://metadata.google.internal/",
+		},
+		{
+			name:   "Return unsafe if the host is a loopback address",
+			rawURL: "http://127.0.0.1/",
+		},
+		{
+			name:   "Return unsafe if the host is a private address",
+			rawURL: "http://10.1.2.3/",
+		},
+		{
+			name:   "Return unsafe if the host is a link local address",
+			rawURL: "http://169.254.169.254/latest/meta-data/",
+		},
+		{
+			name:   "Return unsafe if the host is in the shared address space",
+			rawURL: "http://100.64.0.1/",
+		},
+		{
+			name:   "Return unsafe if the host is the IPv6 loopback",
+			rawURL: "http://[::1]/",
+		},
+		{
+			name:   "Return unsafe if the host is an IPv4 mapped loopback",
+			rawURL: "http://[::ffff:127.0.0.1]/",
+		},
+		{
+			name:   "Return unsafe if the host is a loopback address written as a single number",
+			rawURL: "http://2130706433/",
+		},
+		{
+			name:   "Return unsafe if the host is a loopback address written in hex parts",
+			rawURL: "http://0x7f.1/",
+		},
+		{
+			name:   "Return unsafe if the host is a loopback address written in octal parts",
+			rawURL: "http://0177.0.0.1/",
+		},
+		{
+			name:   "Return unsafe if the host is a private address written in three parts",
+			rawURL: "http://192.168.257/",
+		},
+		{
+			name:   "Return unsafe if the host is the unspecified address written as zero",
+			rawURL: "http://0/",
+		},
+	}
+
+	checker := adapter.MustNewBlocklistChecker(adapter.BlocklistOptions{}, log.NewLogger())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			verdict, err := checker.Check(context.Background(), tt.rawURL)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			if verdict.Safe != tt.wantSafe {
+				t.Errorf("Check(%q) safe = %v (%s), want %v", tt.rawURL, verdict.Safe, verdict.Reason, tt.wantSafe)
+			}
+		})
+	}
+}
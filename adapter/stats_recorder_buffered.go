@@ -0,0 +1,124 @@
+package adapter
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/truewebber/gopkg/log"
+	"github.com/truewebber/gopkg/starter"
+
+	"github.com/truewebber/link-shortener/domain/stats"
+)
+
+type StatsRecorderServer interface {
+	stats.Recorder
+	starter.Server
+}
+
+type bufferedStatsRecorder struct {
+	storage       stats.Storage
+	logger        log.Logger
+	visits        chan stats.Visit
+	stop          chan struct{}
+	done          chan struct{}
+	dropped       atomic.Uint64
+	stopOnce      sync.Once
+	flushInterval time.Duration
+	batchSize     int
+}
+
+func NewBufferedStatsRecorder(
+	storage stats.Storage,
+	bufferSize, batchSize int,
+	flushInterval time.Duration,
+	logger log.Logger,
+) StatsRecorderServer {
+	return &bufferedStatsRecorder{
+		storage:       storage,
+		logger:        logger,
+		visits:        make(chan stats.Visit, bufferSize),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+		flushInterval: flushInterval,
+		batchSize:     batchSize,
+	}
+}
+
+func (r *bufferedStatsRecorder) Record(visit stats.Visit) {
+	select {
+	case r.visits <- visit:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+func (r *bufferedStatsRecorder) Serve() error {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]stats.Visit, 0, r.batchSize)
+
+	for {
+		select {
+		case visit := <-r.visits:
+			batch = append(batch, visit)
+
+			if len(batch) >= r.batchSize {
+				batch = r.flush(batch)
+			}
+		case <-ticker.C:
+			batch = r.flush(batch)
+		case <-r.stop:
+			r.flush(r.drain(batch))
+
+			return nil
+		}
+	}
+}
+
+// Shutdown flushes what is buffered, visits recorded afterwards are lost, so it goes after the servers that record.
+func (r *bufferedStatsRecorder) Shutdown() error {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+
+	<-r.done
+
+	return nil
+}
+
+func (r *bufferedStatsRecorder) drain(batch []stats.Visit) []stats.Visit {
+	for {
+		select {
+		case visit := <-r.visits:
+			batch = append(batch, visit)
+		default:
+			return batch
+		}
+	}
+}
+
+const flushTimeout = 5 * time.Second
+
+func (r *bufferedStatsRecorder) flush(batch []stats.Visit) []stats.Visit {
+	if dropped := r.dropped.Swap(0); dropped > 0 {
+		r.logger.Error("stats buffer is full, visits dropped", "count", dropped)
+	}
+
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	if err := r.storage.CreateBatch(ctx, batch); err != nil {
+		r.logger.Error("failed to flush visits", "count", len(batch), "error", err)
+	}
+
+	return batch[:0]
+}
@@ -0,0 +1,98 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/truewebber/link-shortener/domain/stats"
+)
+
+type statsStoragePgx struct {
+	pool *pgxpool.Pool
+}
+
+func NewStatsStoragePgx(pool *pgxpool.Pool) stats.Storage {
+	return &statsStoragePgx{
+		pool: pool,
+	}
+}
+
+//nolint:gochecknoglobals // static value
+var urlStatsColumns = []string{"url_id", "user_agent", "visitor_hash", "visited_at", "deleted"}
+
+func (s *statsStoragePgx) CreateBatch(ctx context.Context, visits []stats.Visit) error {
+	rows := make([][]any, 0, len(visits))
+
+	for i := range visits {
+		rows = append(rows, []any{
+			visits[i].LinkID, visits[i].UserAgent, visits[i].VisitorID, visits[i].VisitedAt, false,
+		})
+	}
+
+	_, err := s.pool.CopyFrom(ctx, pgx.Identifier{"public", "url_stats"}, urlStatsColumns, pgx.CopyFromRows(rows))
+	if err != nil {
+		return fmt.Errorf("copy url stats rows: %w", err)
+	}
+
+	return nil
+}
+
+const selectStatsSummary = `SELECT count(*), max(visited_at)
+FROM public.url_stats
+WHERE url_id = $1 AND NOT deleted;`
+
+func (s *statsStoragePgx) Summary(ctx context.Context, linkID uint64) (stats.Summary, error) {
+	summary := stats.Summary{}
+
+	err := s.pool.QueryRow(ctx, selectStatsSummary, linkID).Scan(
+		&summary.TotalClicks,
+		&summary.LastVisitAt,
+	)
+	if err != nil {
+		return stats.Summary{}, fmt.Errorf("select stats summary: %w", err)
+	}
+
+	return summary, nil
+}
+
+// selectDailyClicks counts unique visitors per day, visitor hashes change from one day to the next.
+const selectDailyClicks = `SELECT date_trunc('day', visited_at) AS day,
+       count(*),
+       count(DISTINCT NULLIF(visitor_hash, ''))
+FROM public.url_stats
+WHERE url_id = $1 AND NOT deleted AND visited_at >= $2 AND visited_at < $3
+GROUP BY day
+ORDER BY day;`
+
+func (s *statsStoragePgx) DailyClicks(
+	ctx context.Context, linkID uint64, from, to time.Time,
+) ([]stats.DailyClicks, error) {
+	rows, err := s.pool.Query(ctx, selectDailyClicks, linkID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("select daily clicks: %w", err)
+	}
+
+	defer rows.Close()
+
+	var daily []stats.DailyClicks
+
+	for rows.Next() {
+		day := stats.DailyClicks{}
+
+		if scanErr := rows.Scan(&day.Day, &day.Clicks, &day.UniqueVisitors); scanErr != nil {
+			return nil, fmt.Errorf("scan daily clicks: %w", scanErr)
+		}
+
+		daily = append(daily, day)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("rows: %w", rowsErr)
+	}
+
+	return daily, nil
+}
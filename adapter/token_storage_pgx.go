@@ -2,21 +2,39 @@ package adapter
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	pgxpkg "github.com/truewebber/gopkg/pgx"
 
 	tokendomain "github.com/truewebber/link-shortener/domain/token"
 )
 
 type tokenStoragePgx struct {
-	db *pgxpool.Pool
+	db      *pgxpool.Pool
+	hashKey []byte
 }
 
-func NewTokenStoragePgx(db *pgxpool.Pool) tokendomain.Storage {
-	return &tokenStoragePgx{db: db}
+// NewTokenStoragePgx keeps only HMAC-SHA256 of token values under hashKey,
+// tokens found by value carry that value back, the other token of the pair stays empty.
+func NewTokenStoragePgx(db *pgxpool.Pool, hashKey []byte) tokendomain.Storage {
+	return &tokenStoragePgx{
+		db:      db,
+		hashKey: hashKey,
+	}
+}
+
+func (s *tokenStoragePgx) hashToken(value string) string {
+	mac := hmac.New(sha256.New, s.hashKey)
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))
 }
 
 //nolint:dupword // CURRENT_TIMESTAMP used twice for two different fields.
@@ -24,21 +42,40 @@ const insertTokenQuery = `
 			INSERT INTO tokens (
 				user_id, access_token, refresh_token, 
 				access_token_expires_at, refresh_token_expires_at, 
-				created_at, updated_at, deleted
-			) VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, false)
-			RETURNING id, created_at, updated_at;`
+				user_agent, ip, family_id, parent_id, created_at, updated_at, deleted
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, NULLIF($8, 0), NULLIF($9, 0), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, false
+			)
+			RETURNING id, COALESCE(family_id, id), created_at, updated_at;`
 
 func (s *tokenStoragePgx) Create(ctx context.Context, token *tokendomain.Token) error {
-	if err := s.db.QueryRow(
+	if err := s.insertToken(ctx, s.db, token); err != nil {
+		return fmt.Errorf("insert token: %w", err)
+	}
+
+	return nil
+}
+
+// rowQuerier is satisfied by both the pool and a transaction.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func (s *tokenStoragePgx) insertToken(ctx context.Context, db rowQuerier, token *tokendomain.Token) error {
+	if err := db.QueryRow(
 		ctx,
 		insertTokenQuery,
 		token.UserID,
-		token.AccessToken,
-		token.RefreshToken,
+		s.hashToken(token.AccessToken),
+		s.hashToken(token.RefreshToken),
 		token.AccessTokenExpiresAt,
 		token.RefreshTokenExpiresAt,
-	).Scan(&token.ID, &token.CreatedAt, &token.UpdatedAt); err != nil {
-		return fmt.Errorf("insert token: %w", err)
+		token.Device.UserAgent,
+		token.Device.IP,
+		token.FamilyID,
+		token.ParentID,
+	).Scan(&token.ID, &token.FamilyID, &token.CreatedAt, &token.UpdatedAt); err != nil {
+		return fmt.Errorf("query row: %w", err)
 	}
 
 	return nil
@@ -47,62 +84,143 @@ func (s *tokenStoragePgx) Create(ctx context.Context, token *tokendomain.Token)
 //nolint:gosec // false positive
 const selectTokenByAccessTokenQuery = `
 		SELECT 
-			id, user_id, access_token, refresh_token, access_token_expires_at,
-			refresh_token_expires_at, created_at, updated_at
+			id, user_id, access_token_expires_at, refresh_token_expires_at,
+			user_agent, ip, last_used_at, rotated_at, COALESCE(family_id, id), COALESCE(parent_id, 0),
+			created_at, updated_at
 		FROM tokens
 		WHERE access_token = $1 AND NOT deleted;`
 
 func (s *tokenStoragePgx) ByAccessToken(ctx context.Context, accessToken string) (*tokendomain.Token, error) {
-	t, err := s.selectToken(ctx, selectTokenByAccessTokenQuery, accessToken)
+	t, err := s.selectToken(ctx, selectTokenByAccessTokenQuery, s.hashToken(accessToken))
 	if err != nil {
 		return nil, fmt.Errorf("select token by accessToken: %w", err)
 	}
 
+	t.AccessToken = accessToken
+
 	return t, nil
 }
 
 //nolint:gosec // false positive
 const selectTokenByRefreshTokenQuery = `
 		SELECT 
-			id, user_id, access_token, refresh_token, access_token_expires_at,
-			refresh_token_expires_at, created_at, updated_at
+			id, user_id, access_token_expires_at, refresh_token_expires_at,
+			user_agent, ip, last_used_at, rotated_at, COALESCE(family_id, id), COALESCE(parent_id, 0),
+			created_at, updated_at
 		FROM tokens
-		WHERE refresh_token = $1 AND NOT deleted;`
+		WHERE refresh_token = $1 AND (NOT deleted OR rotated_at IS NOT NULL);`
 
 func (s *tokenStoragePgx) ByRefreshToken(ctx context.Context, refreshToken string) (*tokendomain.Token, error) {
-	t, err := s.selectToken(ctx, selectTokenByRefreshTokenQuery, refreshToken)
+	t, err := s.selectToken(ctx, selectTokenByRefreshTokenQuery, s.hashToken(refreshToken))
 	if err != nil {
 		return nil, fmt.Errorf("select token by refreshToken: %w", err)
 	}
 
+	t.RefreshToken = refreshToken
+
 	return t, nil
 }
 
-func (s *tokenStoragePgx) selectToken(
-	ctx context.Context, sql string, tokenValue string,
-) (*tokendomain.Token, error) {
+//nolint:gosec // false positive
+const selectTokenByIDQuery = `
+		SELECT 
+			id, user_id, access_token_expires_at, refresh_token_expires_at,
+			user_agent, ip, last_used_at, rotated_at, COALESCE(family_id, id), COALESCE(parent_id, 0),
+			created_at, updated_at
+		FROM tokens
+		WHERE id = $1 AND NOT deleted;`
+
+func (s *tokenStoragePgx) ByID(ctx context.Context, id uint64) (*tokendomain.Token, error) {
+	t, err := s.selectToken(ctx, selectTokenByIDQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("select token by id: %w", err)
+	}
+
+	return t, nil
+}
+
+func (s *tokenStoragePgx) selectToken(ctx context.Context, sql string, arg any) (*tokendomain.Token, error) {
+	t, err := scanToken(s.db.QueryRow(ctx, sql, arg))
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, tokendomain.ErrTokenNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("select token: %w", err)
+	}
+
+	return t, nil
+}
+
+//nolint:gosec // false positive
+const selectActiveTokensByUserIDQuery = `
+		SELECT 
+			id, user_id, access_token_expires_at, refresh_token_expires_at,
+			user_agent, ip, last_used_at, rotated_at, COALESCE(family_id, id), COALESCE(parent_id, 0),
+			created_at, updated_at
+		FROM tokens
+		WHERE user_id = $1 AND NOT deleted AND refresh_token_expires_at > CURRENT_TIMESTAMP
+		ORDER BY COALESCE(last_used_at, created_at) DESC;`
+
+func (s *tokenStoragePgx) ActiveByUserID(ctx context.Context, userID uint64) ([]tokendomain.Token, error) {
+	rows, err := s.db.Query(ctx, selectActiveTokensByUserIDQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("select active tokens by user id: %w", err)
+	}
+
+	defer rows.Close()
+
+	var tokens []tokendomain.Token
+
+	for rows.Next() {
+		t, scanErr := scanToken(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("scan token: %w", scanErr)
+		}
+
+		tokens = append(tokens, *t)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("rows: %w", rowsErr)
+	}
+
+	return tokens, nil
+}
+
+func scanToken(row pgx.Row) (*tokendomain.Token, error) {
 	t := &tokendomain.Token{}
 
-	err := s.db.QueryRow(ctx, sql, tokenValue).Scan(
+	if err := row.Scan(
 		&t.ID,
 		&t.UserID,
-		&t.AccessToken,
-		&t.RefreshToken,
 		&t.AccessTokenExpiresAt,
 		&t.RefreshTokenExpiresAt,
+		&t.Device.UserAgent,
+		&t.Device.IP,
+		&t.LastUsedAt,
+		&t.RotatedAt,
+		&t.FamilyID,
+		&t.ParentID,
 		&t.CreatedAt,
 		&t.UpdatedAt,
-	)
-
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, tokendomain.ErrTokenNotFound
+	); err != nil {
+		return nil, fmt.Errorf("scan row: %w", err)
 	}
 
-	if err != nil {
-		return nil, fmt.Errorf("select token: %w", err)
+	return t, nil
+}
+
+//nolint:gosec // false positive
+const updateTokenLastUsedAtQuery = "UPDATE tokens SET last_used_at = $2 WHERE id = $1;"
+
+func (s *tokenStoragePgx) Touch(ctx context.Context, id uint64, usedAt time.Time) error {
+	if _, err := s.db.Exec(ctx, updateTokenLastUsedAtQuery, id, usedAt); err != nil {
+		return fmt.Errorf("exec update token last used at: %w", err)
 	}
 
-	return t, nil
+	return nil
 }
 
 //nolint:gosec // false positive
@@ -126,3 +244,66 @@ func (s *tokenStoragePgx) DeleteByUserID(ctx context.Context, userID uint64) err
 
 	return nil
 }
+
+//nolint:gosec // false positive
+const setTokenDeletedByFamilyID = `
+		UPDATE tokens SET deleted = true, updated_at = CURRENT_TIMESTAMP
+		WHERE (id = $1 OR family_id = $1) AND NOT deleted;`
+
+func (s *tokenStoragePgx) DeleteByFamilyID(ctx context.Context, familyID uint64) error {
+	if _, err := s.db.Exec(ctx, setTokenDeletedByFamilyID, familyID); err != nil {
+		return fmt.Errorf("exec update set token deleted by family id: %w", err)
+	}
+
+	return nil
+}
+
+//nolint:gosec // false positive
+const setTokenRotatedByID = `
+		UPDATE tokens SET deleted = true, rotated_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND NOT deleted;`
+
+func (s *tokenStoragePgx) Rotate(ctx context.Context, token *tokendomain.Token) error {
+	doErr := pgxpkg.DoAtomic(ctx, s.db, func(doCtx context.Context, tx pgx.Tx) error {
+		if err := s.insertToken(doCtx, tx, token); err != nil {
+			return fmt.Errorf("insert token: %w", err)
+		}
+
+		cmd, err := tx.Exec(doCtx, setTokenRotatedByID, token.ParentID)
+		if err != nil {
+			return fmt.Errorf("exec update set token rotated by id: %w", err)
+		}
+
+		if cmd.RowsAffected() == 0 {
+			return tokendomain.ErrTokenAlreadyRotated
+		}
+
+		return nil
+	})
+	if doErr != nil {
+		return fmt.Errorf("rotate token on tx: %w", doErr)
+	}
+
+	return nil
+}
+
+//nolint:gosec // false positive
+const deleteExpiredTokensBatch = `
+		DELETE FROM tokens
+		WHERE id IN (
+			SELECT id FROM tokens
+			WHERE refresh_token_expires_at <= CURRENT_TIMESTAMP OR (deleted AND rotated_at IS NULL)
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		);`
+
+// PurgeExpired keeps rotated tokens until they expire so that their reuse is still detected.
+func (s *tokenStoragePgx) PurgeExpired(ctx context.Context, limit uint32) (uint32, error) {
+	cmd, err := s.db.Exec(ctx, deleteExpiredTokensBatch, limit)
+	if err != nil {
+		return 0, fmt.Errorf("exec delete expired tokens: %w", err)
+	}
+
+	//nolint:gosec // rows affected is bounded by limit
+	return uint32(cmd.RowsAffected()), nil
+}
@@ -7,8 +7,11 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	pgxpkg "github.com/truewebber/gopkg/pgx"
 
+	"github.com/truewebber/link-shortener/domain/moderation"
 	userdomain "github.com/truewebber/link-shortener/domain/user"
 )
 
@@ -26,31 +29,64 @@ const insertUserQuery = `
 			provider_type, provider_user_id, provider_user_email, provider_user_name, 
 			provider_avatar_url, created_at, updated_at, deleted
 		) VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, false)
-		  ON CONFLICT DO NOTHING
-		RETURNING id, created_at, updated_at;`
+		RETURNING id, role, created_at, updated_at;`
+
+//nolint:dupword // CURRENT_TIMESTAMP used twice for two different fields.
+const insertFirstIdentityQuery = `
+		INSERT INTO identities (
+			user_id, provider_type, provider_user_id, email, name, avatar_url, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);`
 
 func (s *userStoragePgx) Create(ctx context.Context, user *userdomain.User) error {
-	providerType, err := s.buildProviderTypePGX(user.Provider)
+	providerType, err := providerTypeToPGX(user.Provider)
 	if err != nil {
 		return fmt.Errorf("build provider type pgx: %w", err)
 	}
 
-	queryErr := s.db.QueryRow(
-		ctx,
-		insertUserQuery,
-		providerType,
-		user.ProviderID,
-		user.Email,
-		user.Name,
-		user.AvatarURL,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
-
-	if errors.Is(queryErr, pgx.ErrNoRows) {
-		return userdomain.ErrAlreadyExists
+	role := ""
+
+	doErr := pgxpkg.DoAtomic(ctx, s.db, func(doCtx context.Context, tx pgx.Tx) error {
+		if queryErr := tx.QueryRow(
+			doCtx,
+			insertUserQuery,
+			providerType,
+			user.ProviderID,
+			user.Email,
+			user.Name,
+			user.AvatarURL,
+		).Scan(&user.ID, &role, &user.CreatedAt, &user.UpdatedAt); queryErr != nil {
+			return fmt.Errorf("insert user: %w", queryErr)
+		}
+
+		_, execErr := tx.Exec(
+			doCtx,
+			insertFirstIdentityQuery,
+			user.ID,
+			providerType,
+			user.ProviderID,
+			user.Email,
+			user.Name,
+			user.AvatarURL,
+		)
+
+		var pgErr *pgconn.PgError
+		if errors.As(execErr, &pgErr) && pgErr.Code == pgUniqueViolationCode {
+			return userdomain.ErrAlreadyExists
+		}
+
+		if execErr != nil {
+			return fmt.Errorf("insert identity: %w", execErr)
+		}
+
+		return nil
+	})
+	if doErr != nil {
+		return fmt.Errorf("create user on tx: %w", doErr)
 	}
 
-	if queryErr != nil {
-		return fmt.Errorf("insert user: %w", queryErr)
+	user.Role, err = roleFromPGX(role)
+	if err != nil {
+		return fmt.Errorf("build role domain: %w", err)
 	}
 
 	return nil
@@ -59,13 +95,14 @@ func (s *userStoragePgx) Create(ctx context.Context, user *userdomain.User) erro
 const selectUserByIDQuery = `
 		SELECT 
 			id, provider_type, provider_user_id, provider_user_email, 
-			provider_user_name, provider_avatar_url, created_at, updated_at
+			provider_user_name, provider_avatar_url, role, banned_at, created_at, updated_at
 		FROM users
 		WHERE id = $1 AND NOT deleted;`
 
 func (s *userStoragePgx) ByID(ctx context.Context, id uint64) (*userdomain.User, error) {
 	user := &userdomain.User{}
 	providerType := uint8(0)
+	role := ""
 
 	err := s.db.QueryRow(ctx, selectUserByIDQuery, id).Scan(
 		&user.ID,
@@ -74,6 +111,8 @@ func (s *userStoragePgx) ByID(ctx context.Context, id uint64) (*userdomain.User,
 		&user.Email,
 		&user.Name,
 		&user.AvatarURL,
+		&role,
+		&user.BannedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -86,51 +125,16 @@ func (s *userStoragePgx) ByID(ctx context.Context, id uint64) (*userdomain.User,
 		return nil, fmt.Errorf("select user by id: %w", err)
 	}
 
-	user.Provider, err = s.buildProviderTypeDomain(providerType)
+	user.Provider, err = providerTypeFromPGX(providerType)
 	if err != nil {
 		return nil, fmt.Errorf("build provider type domain: %w", err)
 	}
 
-	return user, nil
-}
-
-const selectUserByProviderQuery = `
-		SELECT 
-			id, provider_user_email, provider_user_name,
-			provider_avatar_url, created_at, updated_at
-		FROM users
-		WHERE provider_type = $1 AND provider_user_id = $2 AND NOT deleted;`
-
-func (s *userStoragePgx) ByProviderID(
-	ctx context.Context, provider userdomain.Provider, providerID string,
-) (*userdomain.User, error) {
-	user := &userdomain.User{}
-
-	providerType, err := s.buildProviderTypePGX(provider)
+	user.Role, err = roleFromPGX(role)
 	if err != nil {
-		return nil, fmt.Errorf("build provider type pgx: %w", err)
+		return nil, fmt.Errorf("build role domain: %w", err)
 	}
 
-	queryErr := s.db.QueryRow(ctx, selectUserByProviderQuery, providerType, providerID).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Name,
-		&user.AvatarURL,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
-	if errors.Is(queryErr, pgx.ErrNoRows) {
-		return nil, userdomain.ErrUserNotFound
-	}
-
-	if queryErr != nil {
-		return nil, fmt.Errorf("select user by provider: %w", queryErr)
-	}
-
-	user.ProviderID = providerID
-	user.Provider = provider
-
 	return user, nil
 }
 
@@ -140,7 +144,9 @@ const updateUserInfoByID = `
 			provider_user_email = $1,
 			provider_user_name = $2,
 			provider_avatar_url = $3,
-			updated_at = $4
+			updated_at = $4,
+			provider_type = $6,
+			provider_user_id = $7
 		WHERE id = $5 AND NOT deleted;`
 
 const exactOneRowShouldBeUpdated = 1
@@ -148,6 +154,11 @@ const exactOneRowShouldBeUpdated = 1
 var errWrongAmountOfRowsAffected = errors.New("wrong amount of rows affected")
 
 func (s *userStoragePgx) Update(ctx context.Context, user *userdomain.User) error {
+	providerType, err := providerTypeToPGX(user.Provider)
+	if err != nil {
+		return fmt.Errorf("build provider type pgx: %w", err)
+	}
+
 	updatedAt := time.Now()
 
 	cmd, err := s.db.Exec(
@@ -158,6 +169,8 @@ func (s *userStoragePgx) Update(ctx context.Context, user *userdomain.User) erro
 		user.AvatarURL,
 		updatedAt,
 		user.ID,
+		providerType,
+		user.ProviderID,
 	)
 	if err != nil {
 		return fmt.Errorf("update user: %w", err)
@@ -172,29 +185,118 @@ func (s *userStoragePgx) Update(ctx context.Context, user *userdomain.User) erro
 	return nil
 }
 
-const setUserDeletedByID = `
+const (
+	deleteIdentitiesByUserIDQuery = "DELETE FROM identities WHERE user_id = $1;"
+	setUserDeletedByID            = `
 		UPDATE users
-		SET deleted = true, updated_at = CURRENT_TIMESTAMP 
-		WHERE id = $2;`
+		SET
+			deleted = true,
+			provider_user_id = 'deleted_' || id,
+			provider_user_email = '',
+			provider_user_name = '',
+			provider_avatar_url = '',
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND NOT deleted;`
+)
 
 func (s *userStoragePgx) Delete(ctx context.Context, id uint64) error {
-	if _, err := s.db.Exec(ctx, setUserDeletedByID, id); err != nil {
-		return fmt.Errorf("delete user: %w", err)
+	doErr := pgxpkg.DoAtomic(ctx, s.db, func(doCtx context.Context, tx pgx.Tx) error {
+		if _, err := tx.Exec(doCtx, deleteIdentitiesByUserIDQuery, id); err != nil {
+			return fmt.Errorf("delete identities: %w", err)
+		}
+
+		cmd, err := tx.Exec(doCtx, setUserDeletedByID, id)
+		if err != nil {
+			return fmt.Errorf("set user deleted: %w", err)
+		}
+
+		if cmd.RowsAffected() == 0 {
+			return userdomain.ErrUserNotFound
+		}
+
+		return nil
+	})
+	if doErr != nil {
+		return fmt.Errorf("delete user on tx: %w", doErr)
 	}
 
 	return nil
 }
 
+//nolint:dupword // CURRENT_TIMESTAMP used twice for two different fields.
+const (
+	setUserBannedByID = `
+		UPDATE users
+		SET banned_at = COALESCE(banned_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND NOT deleted;`
+	setUserUnbannedByID = `
+		UPDATE users
+		SET banned_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND NOT deleted;`
+)
+
+func (s *userStoragePgx) Ban(ctx context.Context, action *moderation.Action) error {
+	return s.setBanned(ctx, setUserBannedByID, action)
+}
+
+func (s *userStoragePgx) Unban(ctx context.Context, action *moderation.Action) error {
+	return s.setBanned(ctx, setUserUnbannedByID, action)
+}
+
+func (s *userStoragePgx) setBanned(ctx context.Context, query string, action *moderation.Action) error {
+	doErr := pgxpkg.DoAtomic(ctx, s.db, func(doCtx context.Context, tx pgx.Tx) error {
+		cmd, err := tx.Exec(doCtx, query, action.TargetID)
+		if err != nil {
+			return fmt.Errorf("set user banned: %w", err)
+		}
+
+		if cmd.RowsAffected() == 0 {
+			return userdomain.ErrUserNotFound
+		}
+
+		return insertModerationAction(doCtx, tx, action)
+	})
+	if doErr != nil {
+		return fmt.Errorf("set user banned on tx: %w", doErr)
+	}
+
+	return nil
+}
+
+const (
+	roleUser  = "user"
+	roleAdmin = "admin"
+)
+
+var errUnknownRole = errors.New("unknown role")
+
+func roleFromPGX(role string) (userdomain.Role, error) {
+	switch role {
+	case roleUser:
+		return userdomain.RoleUser, nil
+	case roleAdmin:
+		return userdomain.RoleAdmin, nil
+	}
+
+	return 0, errUnknownRole
+}
+
 const (
 	providerTypeAnonymous = 1
 	providerTypeGoogle    = 2
 	providerTypeApple     = 3
 	providerTypeGithub    = 4
+	// providerTypeOIDC is the OpenID Connect slot 0, slot n is stored as providerTypeOIDC + n.
+	providerTypeOIDC = 5
 )
 
 var errUnknownProviderType = errors.New("unknown provider type")
 
-func (s *userStoragePgx) buildProviderTypePGX(provider userdomain.Provider) (uint8, error) {
+func providerTypeToPGX(provider userdomain.Provider) (uint8, error) {
+	if slot, ok := provider.OIDCSlot(); ok {
+		return providerTypeOIDC + slot, nil
+	}
+
 	switch provider {
 	case userdomain.ProviderAnonymous:
 		return providerTypeAnonymous, nil
@@ -209,7 +311,11 @@ func (s *userStoragePgx) buildProviderTypePGX(provider userdomain.Provider) (uin
 	return 0, errUnknownProviderType
 }
 
-func (s *userStoragePgx) buildProviderTypeDomain(provider uint8) (userdomain.Provider, error) {
+func providerTypeFromPGX(provider uint8) (userdomain.Provider, error) {
+	if provider >= providerTypeOIDC && provider < providerTypeOIDC+userdomain.MaxOIDCProviders {
+		return userdomain.OIDCProvider(provider - providerTypeOIDC), nil
+	}
+
 	switch provider {
 	case providerTypeAnonymous:
 		return userdomain.ProviderAnonymous, nil
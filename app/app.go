@@ -11,15 +11,53 @@ type APIApp struct {
 }
 
 type APICommand struct {
-	CreateLink      *command.CreateLinkHandler
-	FinishOAuth     *command.FinishOAuthHandler
-	Logout          *command.LogoutHandler
-	RefreshToken    *command.RefreshTokenHandler
-	ValidateCaptcha *command.ValidateCaptchaHandler
+	CreateLink          *command.CreateLinkHandler
+	DeleteLink          *command.DeleteLinkHandler
+	RestoreLink         *command.RestoreLinkHandler
+	UpdateLinkTTL       *command.UpdateLinkTTLHandler
+	FinishOAuth         *command.FinishOAuthHandler
+	Logout              *command.LogoutHandler
+	RecordVisit         *command.RecordVisitHandler
+	RefreshToken        *command.RefreshTokenHandler
+	ValidateCaptcha     *command.ValidateCaptchaHandler
+	CreatePersonalToken *command.CreatePersonalTokenHandler
+	RevokePersonalToken *command.RevokePersonalTokenHandler
+	RevokeSession       *command.RevokeSessionHandler
+	LogoutEverywhere    *command.LogoutEverywhereHandler
+	StartIdentityLink   *command.StartIdentityLinkHandler
+	UnlinkIdentity      *command.UnlinkIdentityHandler
+	DeleteAccount       *command.DeleteAccountHandler
+	TakeRateLimit       *command.TakeRateLimitHandler
+	DisableLink         *command.DisableLinkHandler
+	EnableLink          *command.EnableLinkHandler
+	BanUser             *command.BanUserHandler
+	UnbanUser           *command.UnbanUserHandler
+	ReportLink          *command.ReportLinkHandler
 }
 
 type APIQuery struct {
-	GetLinkByHash *query.GetLinkByHashHandler
-	AuthUser      *query.AuthUserHandler
-	GetAuthURL    *query.GetAuthURLHandler
+	GetLinkByHash         *query.GetLinkByHashHandler
+	AuthUser              *query.AuthUserHandler
+	GetAuthURL            *query.GetAuthURLHandler
+	ListProviders         *query.ListProvidersHandler
+	GetLinkStats          *query.GetLinkStatsHandler
+	ListUserLinks         *query.ListUserLinksHandler
+	AuthPersonalToken     *query.AuthPersonalTokenHandler
+	ListPersonalTokens    *query.ListPersonalTokensHandler
+	ListSessions          *query.ListSessionsHandler
+	ListIdentities        *query.ListIdentitiesHandler
+	ExportAccount         *query.ExportAccountHandler
+	IssueCaptchaChallenge *query.IssueCaptchaChallengeHandler
+	SearchLinks           *query.SearchLinksHandler
+	ListModerationActions *query.ListModerationActionsHandler
+	ListOpenReports       *query.ListOpenReportsHandler
+}
+
+type CleanerApp struct {
+	Command CleanerCommand
+}
+
+type CleanerCommand struct {
+	CleanExpired *command.CleanExpiredHandler
+	RescanLinks  *command.RescanLinksHandler
 }
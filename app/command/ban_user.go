@@ -0,0 +1,81 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/moderation"
+	"github.com/truewebber/link-shortener/domain/pat"
+	tokendomain "github.com/truewebber/link-shortener/domain/token"
+	userdomain "github.com/truewebber/link-shortener/domain/user"
+)
+
+type ModerateUserParams struct {
+	Reason      string
+	ModeratorID uint64
+	UserID      uint64
+}
+
+type BanUserHandler struct {
+	userStorage  userdomain.Storage
+	tokenStorage tokendomain.Storage
+	patStorage   pat.Storage
+}
+
+// NewBanUserHandler revokes the sessions and personal access tokens of the banned user,
+// the links stay as they are, they are disabled one by one.
+func NewBanUserHandler(
+	userStorage userdomain.Storage,
+	tokenStorage tokendomain.Storage,
+	patStorage pat.Storage,
+) *BanUserHandler {
+	return &BanUserHandler{
+		userStorage:  userStorage,
+		tokenStorage: tokenStorage,
+		patStorage:   patStorage,
+	}
+}
+
+// Handle refuses to ban the anonymous user, admins and the moderator themselves.
+func (h *BanUserHandler) Handle(ctx context.Context, params ModerateUserParams) error {
+	if params.UserID == types.AnonymousUser().ID || params.UserID == params.ModeratorID {
+		return apperrors.ErrUserNotBannable
+	}
+
+	user, err := h.userStorage.ByID(ctx, params.UserID)
+	if errors.Is(err, userdomain.ErrUserNotFound) {
+		return apperrors.ErrUserNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("find user: %w", err)
+	}
+
+	if user.IsAdmin() {
+		return apperrors.ErrUserNotBannable
+	}
+
+	action := moderation.New(params.ModeratorID, moderation.KindBanUser, params.UserID, params.Reason)
+
+	err = h.userStorage.Ban(ctx, action)
+	if errors.Is(err, userdomain.ErrUserNotFound) {
+		return apperrors.ErrUserNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("ban user: %w", err)
+	}
+
+	if err := h.tokenStorage.DeleteByUserID(ctx, params.UserID); err != nil {
+		return fmt.Errorf("delete tokens: %w", err)
+	}
+
+	if err := h.patStorage.DeleteByUserID(ctx, params.UserID); err != nil {
+		return fmt.Errorf("delete personal tokens: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/truewebber/gopkg/log"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/domain/captcha"
+	"github.com/truewebber/link-shortener/domain/identity"
+	"github.com/truewebber/link-shortener/domain/link"
+	"github.com/truewebber/link-shortener/domain/lock"
+	"github.com/truewebber/link-shortener/domain/ratelimit"
+	tokendomain "github.com/truewebber/link-shortener/domain/token"
+)
+
+type CleanExpiredParams struct {
+	BatchSize uint32
+}
+
+type CleanExpiredResult struct {
+	Links             uint64
+	Tokens            uint64
+	CaptchaChallenges uint64
+	RateLimits        uint64
+	LinkRequests      uint64
+}
+
+type CleanExpiredHandler struct {
+	linkStorage         link.Storage
+	tokenStorage        tokendomain.Storage
+	captchaSpentStorage captcha.SpentStorage
+	rateLimitStore      ratelimit.Store
+	linkRequestStorage  identity.LinkRequestStorage
+	locker              lock.Locker
+	logger              log.Logger
+}
+
+func NewCleanExpiredHandler(
+	linkStorage link.Storage,
+	tokenStorage tokendomain.Storage,
+	captchaSpentStorage captcha.SpentStorage,
+	rateLimitStore ratelimit.Store,
+	linkRequestStorage identity.LinkRequestStorage,
+	locker lock.Locker,
+	logger log.Logger,
+) *CleanExpiredHandler {
+	return &CleanExpiredHandler{
+		linkStorage:         linkStorage,
+		tokenStorage:        tokenStorage,
+		captchaSpentStorage: captchaSpentStorage,
+		rateLimitStore:      rateLimitStore,
+		linkRequestStorage:  linkRequestStorage,
+		locker:              locker,
+		logger:              logger,
+	}
+}
+
+const cleanExpiredLockKey = "link-shortener:clean-expired"
+
+var errZeroBatchSize = errors.New("batch size must be positive")
+
+func (h *CleanExpiredHandler) Handle(ctx context.Context, params CleanExpiredParams) (CleanExpiredResult, error) {
+	if params.BatchSize == 0 {
+		return CleanExpiredResult{}, fmt.Errorf("%w: %w", ErrValidation, errZeroBatchSize)
+	}
+
+	l, err := h.locker.TryAcquire(ctx, cleanExpiredLockKey)
+	if errors.Is(err, lock.ErrNotAcquired) {
+		return CleanExpiredResult{}, apperrors.ErrLocked
+	}
+
+	if err != nil {
+		return CleanExpiredResult{}, fmt.Errorf("acquire lock: %w", err)
+	}
+
+	defer func() {
+		if releaseErr := l.Release(context.WithoutCancel(ctx)); releaseErr != nil {
+			h.logger.Error("failed to release clean expired lock", "error", releaseErr)
+		}
+	}()
+
+	result := CleanExpiredResult{}
+
+	result.Links, err = h.inBatches(ctx, params.BatchSize, h.linkStorage.DeleteExpired)
+	if err != nil {
+		return result, fmt.Errorf("delete expired links: %w", err)
+	}
+
+	result.Tokens, err = h.inBatches(ctx, params.BatchSize, h.tokenStorage.PurgeExpired)
+	if err != nil {
+		return result, fmt.Errorf("purge expired tokens: %w", err)
+	}
+
+	result.CaptchaChallenges, err = h.inBatches(ctx, params.BatchSize, h.captchaSpentStorage.PurgeExpired)
+	if err != nil {
+		return result, fmt.Errorf("purge expired captcha challenges: %w", err)
+	}
+
+	result.RateLimits, err = h.inBatches(ctx, params.BatchSize, h.rateLimitStore.PurgeExpired)
+	if err != nil {
+		return result, fmt.Errorf("purge full rate limits: %w", err)
+	}
+
+	result.LinkRequests, err = h.inBatches(ctx, params.BatchSize, h.linkRequestStorage.PurgeExpired)
+	if err != nil {
+		return result, fmt.Errorf("purge expired identity link requests: %w", err)
+	}
+
+	return result, nil
+}
+
+func (h *CleanExpiredHandler) inBatches(
+	ctx context.Context,
+	batchSize uint32,
+	deleteBatch func(ctx context.Context, limit uint32) (uint32, error),
+) (uint64, error) {
+	total := uint64(0)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, fmt.Errorf("context: %w", err)
+		}
+
+		affected, err := deleteBatch(ctx, batchSize)
+		if err != nil {
+			return total, fmt.Errorf("delete batch: %w", err)
+		}
+
+		total += uint64(affected)
+
+		if affected < batchSize {
+			return total, nil
+		}
+	}
+}
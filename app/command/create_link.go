@@ -8,30 +8,45 @@ import (
 	"github.com/truewebber/gopkg/log"
 	urlpkg "github.com/truewebber/gopkg/url"
 
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/linkhash"
+	"github.com/truewebber/link-shortener/domain/alias"
 	"github.com/truewebber/link-shortener/domain/hash"
 	"github.com/truewebber/link-shortener/domain/link"
+	"github.com/truewebber/link-shortener/domain/safety"
 )
 
 type CreateLinkParams struct {
 	RedirectURL string
+	Alias       string
 	UserID      uint64
 	ExpiresType link.ExpiresType
 }
 
 type CreateLinkHandler struct {
 	linkStorage   link.Storage
-	hashGenerator hash.Generator
+	aliasStorage  alias.Storage
+	codeGenerator hash.CodeGenerator
+	safetyChecker safety.Checker
 	logger        log.Logger
+	hashResolver  *linkhash.Resolver
 }
 
+// NewCreateLinkHandler takes a nil codeGenerator when short hashes are derived from link ids.
 func NewCreateLinkHandler(
 	linkStorage link.Storage,
-	hashGenerator hash.Generator,
+	aliasStorage alias.Storage,
+	hashResolver *linkhash.Resolver,
+	codeGenerator hash.CodeGenerator,
+	safetyChecker safety.Checker,
 	logger log.Logger,
 ) *CreateLinkHandler {
 	return &CreateLinkHandler{
 		linkStorage:   linkStorage,
-		hashGenerator: hashGenerator,
+		aliasStorage:  aliasStorage,
+		hashResolver:  hashResolver,
+		codeGenerator: codeGenerator,
+		safetyChecker: safetyChecker,
 		logger:        logger,
 	}
 }
@@ -39,8 +54,14 @@ func NewCreateLinkHandler(
 var ErrValidation = errors.New("validation")
 
 func (h *CreateLinkHandler) Handle(ctx context.Context, cmd *CreateLinkParams) (string, error) {
-	if err := h.validateCreateLinkCommand(cmd); err != nil {
-		return "", fmt.Errorf("%w: %w", ErrValidation, err)
+	if err := h.validate(ctx, cmd); err != nil {
+		return "", fmt.Errorf("validate: %w", err)
+	}
+
+	if cmd.Alias != "" {
+		if err := h.checkAliasAvailable(ctx, cmd.Alias); err != nil {
+			return "", fmt.Errorf("check alias available: %w", err)
+		}
 	}
 
 	l, err := link.New(cmd.UserID, cmd.RedirectURL, cmd.ExpiresType)
@@ -48,18 +69,95 @@ func (h *CreateLinkHandler) Handle(ctx context.Context, cmd *CreateLinkParams) (
 		return "", fmt.Errorf("create link: %w", err)
 	}
 
-	if createErr := h.linkStorage.Create(ctx, l); createErr != nil {
+	if cmd.Alias != "" {
+		return h.createLinkWithAlias(ctx, l, cmd.Alias)
+	}
+
+	if createErr := h.createLink(ctx, l, h.linkStorage.Create); createErr != nil {
 		return "", fmt.Errorf("create link: %w", createErr)
 	}
 
-	linkHash, err := h.hashGenerator.ToHash(l.ID)
+	linkHash, err := h.hashResolver.Hash(l)
 	if err != nil {
-		return "", fmt.Errorf("generate hash from link id: %w", err)
+		return "", fmt.Errorf("link hash: %w", err)
 	}
 
 	return linkHash, nil
 }
 
+const maxCodeAttempts = 5
+
+var errCodeAttemptsExhausted = errors.New("no free random code found")
+
+// createLink retries create with another random code while codes collide.
+func (h *CreateLinkHandler) createLink(
+	ctx context.Context, l *link.Link, create func(ctx context.Context, l *link.Link) error,
+) error {
+	if h.codeGenerator == nil {
+		if err := create(ctx, l); err != nil {
+			return fmt.Errorf("create link in storage: %w", err)
+		}
+
+		return nil
+	}
+
+	for range maxCodeAttempts {
+		code, free, err := h.generateCode(ctx)
+		if err != nil {
+			return fmt.Errorf("generate code: %w", err)
+		}
+
+		if !free {
+			continue
+		}
+
+		l.Code = code
+
+		createErr := create(ctx, l)
+		if errors.Is(createErr, link.ErrCodeAlreadyExists) {
+			h.logger.Info("random code collision, retrying", "code", code)
+
+			continue
+		}
+
+		if createErr != nil {
+			return fmt.Errorf("create link in storage: %w", createErr)
+		}
+
+		return nil
+	}
+
+	return errCodeAttemptsExhausted
+}
+
+// generateCode also reports whether the code is free, a code must not shadow an alias or a derived hash.
+func (h *CreateLinkHandler) generateCode(ctx context.Context) (string, bool, error) {
+	code, err := h.codeGenerator.Generate()
+	if err != nil {
+		return "", false, fmt.Errorf("generate random code: %w", err)
+	}
+
+	taken, err := h.hashResolver.IsTaken(ctx, code)
+	if err != nil {
+		return "", false, fmt.Errorf("check code is taken: %w", err)
+	}
+
+	if taken {
+		return code, false, nil
+	}
+
+	_, aliasErr := h.aliasStorage.ByValue(ctx, code)
+	if errors.Is(aliasErr, alias.ErrNotFound) {
+		return code, true, nil
+	}
+
+	if aliasErr != nil {
+		return "", false, fmt.Errorf("find alias: %w", aliasErr)
+	}
+
+	return code, false, nil
+}
+
 var errEmptyRedirectURL = errors.New("empty redirect URL")
 
 func (h *CreateLinkHandler) validateCreateLinkCommand(cmd *CreateLinkParams) error {
@@ -74,5 +172,96 @@ func (h *CreateLinkHandler) validateCreateLinkCommand(cmd *CreateLinkParams) err
 
 	cmd.RedirectURL = normalizedURL.String()
 
+	if cmd.Alias == "" {
+		return nil
+	}
+
+	if err := alias.Validate(cmd.Alias); err != nil {
+		return fmt.Errorf("validate alias: %w", err)
+	}
+
+	return nil
+}
+
+// validate screens the url as sent, normalizing would turn scripts away as malformed rather than unsafe,
+// and once more as normalized, which is what blocklists are written against.
+func (h *CreateLinkHandler) validate(ctx context.Context, cmd *CreateLinkParams) error {
+	rawURL := cmd.RedirectURL
+
+	if rawURL != "" {
+		if err := h.checkSafety(ctx, rawURL); err != nil {
+			return fmt.Errorf("check safety: %w", err)
+		}
+	}
+
+	if err := h.validateCreateLinkCommand(cmd); err != nil {
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	if cmd.RedirectURL == rawURL {
+		return nil
+	}
+
+	if err := h.checkSafety(ctx, cmd.RedirectURL); err != nil {
+		return fmt.Errorf("check normalized safety: %w", err)
+	}
+
 	return nil
 }
+
+// checkSafety reports an unsafe destination as a validation error of its own, so clients can tell it apart.
+func (h *CreateLinkHandler) checkSafety(ctx context.Context, redirectURL string) error {
+	verdict, err := h.safetyChecker.Check(ctx, redirectURL)
+	if err != nil {
+		return fmt.Errorf("check url: %w", err)
+	}
+
+	if !verdict.Safe {
+		return fmt.Errorf("%w: %w: %s", ErrValidation, apperrors.ErrUnsafeURL, verdict.Reason)
+	}
+
+	return nil
+}
+
+func (h *CreateLinkHandler) checkAliasAvailable(ctx context.Context, value string) error {
+	// an alias equal to a short hash would shadow the link the hash points to
+	taken, err := h.hashResolver.IsTaken(ctx, value)
+	if err != nil {
+		return fmt.Errorf("check alias is a short hash: %w", err)
+	}
+
+	if taken {
+		return fmt.Errorf("%w: %w: alias looks like a generated short code", ErrValidation, alias.ErrReserved)
+	}
+
+	_, err = h.aliasStorage.ByValue(ctx, value)
+	if errors.Is(err, alias.ErrNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("find alias: %w", err)
+	}
+
+	return apperrors.ErrAliasAlreadyExists
+}
+
+func (h *CreateLinkHandler) createLinkWithAlias(ctx context.Context, l *link.Link, value string) (string, error) {
+	a, err := alias.New(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	createErr := h.createLink(ctx, l, func(ctx context.Context, l *link.Link) error {
+		return h.aliasStorage.CreateWithLink(ctx, l, a)
+	})
+	if errors.Is(createErr, alias.ErrAlreadyExists) {
+		return "", apperrors.ErrAliasAlreadyExists
+	}
+
+	if createErr != nil {
+		return "", fmt.Errorf("create link with alias: %w", createErr)
+	}
+
+	return a.Value, nil
+}
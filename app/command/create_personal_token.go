@@ -0,0 +1,66 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/pat"
+)
+
+type CreatePersonalTokenParams struct {
+	ExpiresAt *time.Time
+	Name      string
+	Scopes    []string
+	UserID    uint64
+}
+
+type CreatePersonalTokenHandler struct {
+	patStorage pat.Storage
+}
+
+func NewCreatePersonalTokenHandler(patStorage pat.Storage) *CreatePersonalTokenHandler {
+	return &CreatePersonalTokenHandler{
+		patStorage: patStorage,
+	}
+}
+
+const maxPersonalTokensPerUser = 50
+
+func (h *CreatePersonalTokenHandler) Handle(
+	ctx context.Context, params CreatePersonalTokenParams,
+) (*types.PersonalToken, error) {
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expiry is in the past", ErrValidation)
+	}
+
+	existing, err := h.patStorage.ByUserID(ctx, params.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("list personal tokens: %w", err)
+	}
+
+	if len(existing) >= maxPersonalTokensPerUser {
+		return nil, apperrors.ErrTooManyPersonalTokens
+	}
+
+	scopes := make([]pat.Scope, 0, len(params.Scopes))
+	for _, scope := range params.Scopes {
+		scopes = append(scopes, pat.Scope(scope))
+	}
+
+	token, secret, err := pat.New(params.UserID, params.Name, scopes, params.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	if createErr := h.patStorage.Create(ctx, token); createErr != nil {
+		return nil, fmt.Errorf("create personal token: %w", createErr)
+	}
+
+	created := types.BuildPersonalTokenFromDomain(token)
+	created.Secret = secret
+
+	return created, nil
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/link"
+	"github.com/truewebber/link-shortener/domain/pat"
+	tokendomain "github.com/truewebber/link-shortener/domain/token"
+	userdomain "github.com/truewebber/link-shortener/domain/user"
+)
+
+type DeleteAccountHandler struct {
+	userStorage   userdomain.Storage
+	tokenStorage  tokendomain.Storage
+	patStorage    pat.Storage
+	linkStorage   link.Storage
+	reassignLinks bool
+}
+
+// NewDeleteAccountHandler hands the links of a deleted user over to the anonymous user when reassignLinks is set,
+// links the anonymous user already has for the same URL are deleted instead, as are all links otherwise.
+func NewDeleteAccountHandler(
+	userStorage userdomain.Storage,
+	tokenStorage tokendomain.Storage,
+	patStorage pat.Storage,
+	linkStorage link.Storage,
+	reassignLinks bool,
+) *DeleteAccountHandler {
+	return &DeleteAccountHandler{
+		userStorage:   userStorage,
+		tokenStorage:  tokenStorage,
+		patStorage:    patStorage,
+		linkStorage:   linkStorage,
+		reassignLinks: reassignLinks,
+	}
+}
+
+func (h *DeleteAccountHandler) Handle(ctx context.Context, userID uint64) error {
+	if userID == types.AnonymousUser().ID {
+		return apperrors.ErrUserNotFound
+	}
+
+	// credentials go first, a failure further on leaves the account signed out but intact to delete again
+	if err := h.tokenStorage.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("delete tokens: %w", err)
+	}
+
+	if err := h.patStorage.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("delete personal tokens: %w", err)
+	}
+
+	if h.reassignLinks {
+		if err := h.linkStorage.ReassignByUserID(ctx, userID, types.AnonymousUser().ID); err != nil {
+			return fmt.Errorf("reassign links: %w", err)
+		}
+	}
+
+	// the aliases of the deleted links are freed along with them
+	if _, err := h.linkStorage.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("delete links: %w", err)
+	}
+
+	err := h.userStorage.Delete(ctx, userID)
+	if errors.Is(err, userdomain.ErrUserNotFound) {
+		return apperrors.ErrUserNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/linkhash"
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+type DeleteLinkParams struct {
+	Hash   string
+	UserID uint64
+}
+
+type DeleteLinkHandler struct {
+	linkStorage  link.Storage
+	hashResolver *linkhash.Resolver
+}
+
+func NewDeleteLinkHandler(
+	linkStorage link.Storage,
+	hashResolver *linkhash.Resolver,
+) *DeleteLinkHandler {
+	return &DeleteLinkHandler{
+		linkStorage:  linkStorage,
+		hashResolver: hashResolver,
+	}
+}
+
+func (h *DeleteLinkHandler) Handle(ctx context.Context, params DeleteLinkParams) error {
+	id, err := h.hashResolver.LinkID(ctx, params.Hash)
+	if errors.Is(err, link.ErrNotFound) {
+		return apperrors.ErrLinkNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("resolve link id: %w", err)
+	}
+
+	l, err := h.linkStorage.ByID(ctx, id)
+	if errors.Is(err, link.ErrNotFound) {
+		return apperrors.ErrLinkNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("find link: %w", err)
+	}
+
+	if !l.IsOwnedBy(params.UserID) {
+		return apperrors.ErrLinkNotFound
+	}
+
+	if err := h.linkStorage.Delete(ctx, l.ID); err != nil {
+		return fmt.Errorf("delete link: %w", err)
+	}
+
+	return nil
+}
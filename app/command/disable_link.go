@@ -0,0 +1,95 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/domain/link"
+	"github.com/truewebber/link-shortener/domain/moderation"
+	"github.com/truewebber/link-shortener/domain/report"
+)
+
+type ModerateLinkParams struct {
+	Reason      string
+	ModeratorID uint64
+	LinkID      uint64
+}
+
+type DisableLinkHandler struct {
+	linkStorage       link.Storage
+	moderationStorage moderation.Storage
+	reportStorage     report.Storage
+}
+
+// NewDisableLinkHandler blocks the link the same way the rescanner does, so visitors get the interstitial,
+// open abuse reports of the link are resolved by the decision.
+func NewDisableLinkHandler(
+	linkStorage link.Storage,
+	moderationStorage moderation.Storage,
+	reportStorage report.Storage,
+) *DisableLinkHandler {
+	return &DisableLinkHandler{
+		linkStorage:       linkStorage,
+		moderationStorage: moderationStorage,
+		reportStorage:     reportStorage,
+	}
+}
+
+func (h *DisableLinkHandler) Handle(ctx context.Context, params ModerateLinkParams) error {
+	l, err := h.linkStorage.ByID(ctx, params.LinkID)
+	if errors.Is(err, link.ErrNotFound) {
+		return apperrors.ErrLinkNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("get link by id: %w", err)
+	}
+
+	if l.IsBlocked() {
+		return h.confirmBlock(ctx, params)
+	}
+
+	err = h.linkStorage.Disable(ctx, newDisableLinkAction(params))
+	if errors.Is(err, link.ErrNotFound) {
+		// blocked or deleted since it was read
+		return apperrors.ErrLinkAlreadyBlocked
+	}
+
+	if err != nil {
+		return fmt.Errorf("disable link: %w", err)
+	}
+
+	return h.resolveReports(ctx, params.LinkID)
+}
+
+// confirmBlock lets a moderator settle the reports of a link that abuse reports disabled already.
+func (h *DisableLinkHandler) confirmBlock(ctx context.Context, params ModerateLinkParams) error {
+	reporters, err := h.reportStorage.Reporters(ctx, params.LinkID)
+	if err != nil {
+		return fmt.Errorf("count reporters: %w", err)
+	}
+
+	if reporters == 0 {
+		return apperrors.ErrLinkAlreadyBlocked
+	}
+
+	if err := h.moderationStorage.Create(ctx, newDisableLinkAction(params)); err != nil {
+		return fmt.Errorf("create moderation action: %w", err)
+	}
+
+	return h.resolveReports(ctx, params.LinkID)
+}
+
+func newDisableLinkAction(params ModerateLinkParams) *moderation.Action {
+	return moderation.New(params.ModeratorID, moderation.KindDisableLink, params.LinkID, params.Reason)
+}
+
+func (h *DisableLinkHandler) resolveReports(ctx context.Context, linkID uint64) error {
+	if err := h.reportStorage.Resolve(ctx, linkID); err != nil {
+		return fmt.Errorf("resolve reports: %w", err)
+	}
+
+	return nil
+}
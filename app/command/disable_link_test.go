@@ -0,0 +1,89 @@
+package command_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/truewebber/link-shortener/app/command"
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+func TestDisableLinkHandle(t *testing.T) {
+	t.Parallel()
+
+	blockedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		linkStorage   *fakeModeratedLinkStorage
+		reportStorage *fakeReportStorage
+		wantErr       error
+		name          string
+		linkID        uint64
+		wantActions   int
+		wantAudited   int
+		wantResolved  bool
+	}{
+		{
+			name:          "Disable a live link, record the action and resolve its reports",
+			linkStorage:   &fakeModeratedLinkStorage{link: &link.Link{ID: 11}},
+			reportStorage: &fakeReportStorage{},
+			linkID:        11,
+			wantActions:   1,
+			wantResolved:  true,
+		},
+		{
+			name:        "Record the decision on a link abuse reports disabled already",
+			linkStorage: &fakeModeratedLinkStorage{link: &link.Link{ID: 11, BlockedAt: &blockedAt}},
+			reportStorage: &fakeReportStorage{reporters: map[uint64]map[string]bool{
+				11: {"ip:198.51.100.1": true, "ip:198.51.100.2": true, "ip:198.51.100.3": true},
+			}},
+			linkID:       11,
+			wantAudited:  1,
+			wantResolved: true,
+		},
+		{
+			name:          "Return error if the link is blocked without open reports",
+			linkStorage:   &fakeModeratedLinkStorage{link: &link.Link{ID: 11, BlockedAt: &blockedAt}},
+			reportStorage: &fakeReportStorage{},
+			linkID:        11,
+			wantErr:       apperrors.ErrLinkAlreadyBlocked,
+		},
+		{
+			name:          "Return error if the link does not exist",
+			linkStorage:   &fakeModeratedLinkStorage{link: &link.Link{ID: 11}},
+			reportStorage: &fakeReportStorage{},
+			linkID:        12,
+			wantErr:       apperrors.ErrLinkNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			moderationStorage := &fakeModerationStorage{}
+			handler := command.NewDisableLinkHandler(tt.linkStorage, moderationStorage, tt.reportStorage)
+
+			err := handler.Handle(context.Background(), command.ModerateLinkParams{
+				Reason:      "phishing",
+				ModeratorID: 2,
+				LinkID:      tt.linkID,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Handle() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(tt.linkStorage.actions) != tt.wantActions || len(moderationStorage.actions) != tt.wantAudited {
+				t.Errorf("actions with the block = %d, on their own = %d, want %d, %d",
+					len(tt.linkStorage.actions), len(moderationStorage.actions), tt.wantActions, tt.wantAudited)
+			}
+
+			if tt.reportStorage.resolved != tt.wantResolved {
+				t.Errorf("reports resolved = %v, want %v", tt.reportStorage.resolved, tt.wantResolved)
+			}
+		})
+	}
+}
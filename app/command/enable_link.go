@@ -0,0 +1,62 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/domain/link"
+	"github.com/truewebber/link-shortener/domain/moderation"
+	"github.com/truewebber/link-shortener/domain/report"
+)
+
+type EnableLinkHandler struct {
+	linkStorage   link.Storage
+	reportStorage report.Storage
+}
+
+// NewEnableLinkHandler lifts a block no matter whether a moderator, the rescanner or abuse reports put it there,
+// the open reports are resolved so the link has to be reported anew to be disabled again.
+func NewEnableLinkHandler(
+	linkStorage link.Storage,
+	reportStorage report.Storage,
+) *EnableLinkHandler {
+	return &EnableLinkHandler{
+		linkStorage:   linkStorage,
+		reportStorage: reportStorage,
+	}
+}
+
+func (h *EnableLinkHandler) Handle(ctx context.Context, params ModerateLinkParams) error {
+	l, err := h.linkStorage.ByID(ctx, params.LinkID)
+	if errors.Is(err, link.ErrNotFound) {
+		return apperrors.ErrLinkNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("get link by id: %w", err)
+	}
+
+	if !l.IsBlocked() {
+		return apperrors.ErrLinkNotBlocked
+	}
+
+	action := moderation.New(params.ModeratorID, moderation.KindEnableLink, params.LinkID, params.Reason)
+
+	err = h.linkStorage.Enable(ctx, action)
+	if errors.Is(err, link.ErrNotFound) {
+		// unblocked or deleted since it was read
+		return apperrors.ErrLinkNotBlocked
+	}
+
+	if err != nil {
+		return fmt.Errorf("enable link: %w", err)
+	}
+
+	if err := h.reportStorage.Resolve(ctx, params.LinkID); err != nil {
+		return fmt.Errorf("resolve reports: %w", err)
+	}
+
+	return nil
+}
@@ -9,37 +9,50 @@ import (
 
 	"github.com/truewebber/gopkg/log"
 
+	apperrors "github.com/truewebber/link-shortener/app/errors"
 	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/identity"
 	tokendomain "github.com/truewebber/link-shortener/domain/token"
 	userdomain "github.com/truewebber/link-shortener/domain/user"
 )
 
 type FinishOAuthHandler struct {
-	userStorage    userdomain.Storage
-	tokenStorage   tokendomain.Storage
-	oauthProviders map[types.Provider]userdomain.OAuthProvider
-	logger         log.Logger
+	userStorage        userdomain.Storage
+	identityStorage    identity.Storage
+	linkRequestStorage identity.LinkRequestStorage
+	tokenStorage       tokendomain.Storage
+	oauthProviders     map[types.Provider]userdomain.OAuthProvider
+	logger             log.Logger
 }
 
 func NewFinishOAuthHandler(
 	userStorage userdomain.Storage,
+	identityStorage identity.Storage,
+	linkRequestStorage identity.LinkRequestStorage,
 	tokenStorage tokendomain.Storage,
 	oauthProviders map[types.Provider]userdomain.OAuthProvider,
 	logger log.Logger,
 ) *FinishOAuthHandler {
 	return &FinishOAuthHandler{
-		userStorage:    userStorage,
-		tokenStorage:   tokenStorage,
-		oauthProviders: oauthProviders,
-		logger:         logger,
+		userStorage:        userStorage,
+		identityStorage:    identityStorage,
+		linkRequestStorage: linkRequestStorage,
+		tokenStorage:       tokenStorage,
+		oauthProviders:     oauthProviders,
+		logger:             logger,
 	}
 }
 
 type FinishOAuthParams struct {
 	Code         string
+	CodeVerifier string
 	ErrorMessage string
-	UserData     []byte
-	Provider     types.Provider
+	UserAgent    string
+	ClientIP     string
+	// LinkNonce attaches the identity to the user who started linking it instead of signing in with it.
+	LinkNonce string
+	UserData  []byte
+	Provider  types.Provider
 }
 
 const (
@@ -53,26 +66,33 @@ func (h *FinishOAuthHandler) Handle(ctx context.Context, params FinishOAuthParam
 		return nil, fmt.Errorf("get oauth provider: %w", err)
 	}
 
-	oauthInfo, err := oauthProvider.ExchangeCode(ctx, params.Code)
+	linkUserID, err := h.linkUserID(ctx, params.LinkNonce)
+	if err != nil {
+		return nil, fmt.Errorf("link user id: %w", err)
+	}
+
+	oauthInfo, err := oauthProvider.ExchangeCode(ctx, params.Code, params.CodeVerifier)
 	if err != nil {
 		return nil, fmt.Errorf("exchange code: %w", err)
 	}
 
 	h.mixInName(oauthInfo, params.UserData)
 
-	provider, err := types.BuildProviderToDomain(params.Provider)
+	oauthInfo.Provider, err = types.BuildProviderToDomain(params.Provider)
 	if err != nil {
 		return nil, fmt.Errorf("build provider domain: %w", err)
 	}
 
-	user, err := h.upsertUser(
-		ctx, provider, oauthInfo.ProviderID, oauthInfo.Email, oauthInfo.Name, oauthInfo.AvatarURL,
-	)
+	user, err := h.resolveUser(ctx, linkUserID, oauthInfo)
 	if err != nil {
-		return nil, fmt.Errorf("upsert user: %w", err)
+		return nil, fmt.Errorf("resolve user: %w", err)
 	}
 
-	token, err := h.generateAndSaveNewToken(ctx, user)
+	if user.IsBanned() {
+		return nil, apperrors.ErrUserBanned
+	}
+
+	token, err := h.generateAndSaveNewToken(ctx, user, tokendomain.NewDevice(params.UserAgent, params.ClientIP))
 	if err != nil {
 		return nil, fmt.Errorf("generate and save new token: %w", err)
 	}
@@ -88,6 +108,34 @@ func (h *FinishOAuthHandler) Handle(ctx context.Context, params FinishOAuthParam
 	}, nil
 }
 
+// linkUserID returns zero for a plain sign in.
+func (h *FinishOAuthHandler) linkUserID(ctx context.Context, linkNonce string) (uint64, error) {
+	if linkNonce == "" {
+		return 0, nil
+	}
+
+	userID, err := h.linkRequestStorage.Consume(ctx, linkNonce)
+	if errors.Is(err, identity.ErrLinkRequestNotFound) {
+		return 0, apperrors.ErrLinkRequestExpired
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("consume link request: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (h *FinishOAuthHandler) resolveUser(
+	ctx context.Context, linkUserID uint64, info *userdomain.OAuthInfo,
+) (*userdomain.User, error) {
+	if linkUserID == 0 {
+		return h.upsertUser(ctx, info)
+	}
+
+	return h.linkIdentity(ctx, linkUserID, info)
+}
+
 type appleUserData struct {
 	Name struct {
 		FirstName string `json:"firstName"`
@@ -136,9 +184,9 @@ func (h *FinishOAuthHandler) getOAuthProvider(provider types.Provider) (userdoma
 }
 
 func (h *FinishOAuthHandler) generateAndSaveNewToken(
-	ctx context.Context, user *userdomain.User,
+	ctx context.Context, user *userdomain.User, device tokendomain.Device,
 ) (*tokendomain.Token, error) {
-	token, err := tokendomain.GenerateNewToken(user.ID, AccessTokenDuration, RefreshTokenDuration)
+	token, err := tokendomain.GenerateNewToken(user.ID, device, AccessTokenDuration, RefreshTokenDuration)
 	if err != nil {
 		return nil, fmt.Errorf("generate token pair: %w", err)
 	}
@@ -150,58 +198,100 @@ func (h *FinishOAuthHandler) generateAndSaveNewToken(
 	return token, nil
 }
 
-func (h *FinishOAuthHandler) upsertUser(
-	ctx context.Context,
-	provider userdomain.Provider,
-	providerID, email, name, avatarURL string,
-) (*userdomain.User, error) {
-	existingUser, err := h.userStorage.ByProviderID(ctx, provider, providerID)
-	if err != nil && !errors.Is(err, userdomain.ErrUserNotFound) {
-		return nil, fmt.Errorf("find user by provider id: %w", err)
+func (h *FinishOAuthHandler) upsertUser(ctx context.Context, info *userdomain.OAuthInfo) (*userdomain.User, error) {
+	existing, err := h.identityStorage.ByProviderID(ctx, info.Provider, info.ProviderID)
+	if errors.Is(err, identity.ErrNotFound) {
+		user := &userdomain.User{
+			Provider:   info.Provider,
+			ProviderID: info.ProviderID,
+			Email:      info.Email,
+			Name:       info.Name,
+			AvatarURL:  info.AvatarURL,
+		}
+
+		if createErr := h.userStorage.Create(ctx, user); createErr != nil {
+			return nil, fmt.Errorf("create user: %w", createErr)
+		}
+
+		return user, nil
 	}
 
-	userToUpsert := &userdomain.User{
-		Provider:   provider,
-		ProviderID: providerID,
-		Email:      email,
-		Name:       name,
-		AvatarURL:  avatarURL,
+	if err != nil {
+		return nil, fmt.Errorf("find identity by provider id: %w", err)
 	}
 
-	if errors.Is(err, userdomain.ErrUserNotFound) {
-		if err := h.userStorage.Create(ctx, userToUpsert); err != nil {
-			return nil, fmt.Errorf("create user: %w", err)
+	if existing.Refresh(info) {
+		if updateErr := h.identityStorage.Update(ctx, existing); updateErr != nil {
+			return nil, fmt.Errorf("update identity: %w", updateErr)
 		}
+	}
 
-		return userToUpsert, nil
+	user, err := h.userStorage.ByID(ctx, existing.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("find user by id: %w", err)
 	}
 
-	userToUpsert.ID = existingUser.ID
+	if err := h.refreshProfile(ctx, user, existing); err != nil {
+		return nil, fmt.Errorf("refresh profile: %w", err)
+	}
+
+	return user, nil
+}
+
+// linkIdentity attaches the identity to the signed in user, an identity of another user is never moved.
+func (h *FinishOAuthHandler) linkIdentity(
+	ctx context.Context, userID uint64, info *userdomain.OAuthInfo,
+) (*userdomain.User, error) {
+	existing, err := h.identityStorage.ByProviderID(ctx, info.Provider, info.ProviderID)
+
+	switch {
+	case err == nil && !existing.IsOwnedBy(userID):
+		return nil, apperrors.ErrIdentityAlreadyLinked
+	case errors.Is(err, identity.ErrNotFound):
+		createErr := h.identityStorage.Create(ctx, identity.FromOAuthInfo(userID, info))
+		if errors.Is(createErr, identity.ErrAlreadyExists) {
+			return nil, apperrors.ErrIdentityAlreadyLinked
+		}
 
-	updated := false
+		if createErr != nil {
+			return nil, fmt.Errorf("create identity: %w", createErr)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("find identity by provider id: %w", err)
+	}
 
-	if existingUser.Email != email {
-		userToUpsert.Email = email
-		updated = true
+	user, err := h.userStorage.ByID(ctx, userID)
+	if errors.Is(err, userdomain.ErrUserNotFound) {
+		return nil, apperrors.ErrUserNotFound
 	}
 
-	if existingUser.Name != name {
-		userToUpsert.Name = name
-		updated = true
+	if err != nil {
+		return nil, fmt.Errorf("find user by id: %w", err)
 	}
 
-	if existingUser.AvatarURL != avatarURL {
-		userToUpsert.AvatarURL = avatarURL
-		updated = true
+	return user, nil
+}
+
+// refreshProfile copies the identity into the profile only when the profile shows it,
+// signing in with another linked identity leaves the profile as it is.
+func (h *FinishOAuthHandler) refreshProfile(
+	ctx context.Context, user *userdomain.User, signedIn *identity.Identity,
+) error {
+	if user.Provider != signedIn.Provider || user.ProviderID != signedIn.ProviderID {
+		return nil
 	}
 
-	if !updated {
-		return existingUser, nil
+	if user.Email == signedIn.Email && user.Name == signedIn.Name && user.AvatarURL == signedIn.AvatarURL {
+		return nil
 	}
 
-	if err := h.userStorage.Update(ctx, userToUpsert); err != nil {
-		return nil, fmt.Errorf("update user: %w", err)
+	user.Email = signedIn.Email
+	user.Name = signedIn.Name
+	user.AvatarURL = signedIn.AvatarURL
+
+	if err := h.userStorage.Update(ctx, user); err != nil {
+		return fmt.Errorf("update user: %w", err)
 	}
 
-	return userToUpsert, nil
+	return nil
 }
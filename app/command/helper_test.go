@@ -0,0 +1,199 @@
+package command_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/truewebber/link-shortener/domain/link"
+	"github.com/truewebber/link-shortener/domain/lock"
+	"github.com/truewebber/link-shortener/domain/moderation"
+	"github.com/truewebber/link-shortener/domain/report"
+	"github.com/truewebber/link-shortener/domain/safety"
+	tokendomain "github.com/truewebber/link-shortener/domain/token"
+	userdomain "github.com/truewebber/link-shortener/domain/user"
+)
+
+type fakeTokenStorage struct {
+	tokendomain.Storage
+	token         *tokendomain.Token
+	rotateErr     error
+	stored        *tokendomain.Token
+	revokedFamily uint64
+}
+
+func (s *fakeTokenStorage) ByRefreshToken(_ context.Context, value string) (*tokendomain.Token, error) {
+	if s.token == nil || s.token.RefreshToken != value {
+		return nil, tokendomain.ErrTokenNotFound
+	}
+
+	return s.token, nil
+}
+
+func (s *fakeTokenStorage) Rotate(_ context.Context, token *tokendomain.Token) error {
+	if s.rotateErr != nil {
+		return s.rotateErr
+	}
+
+	s.stored = token
+
+	return nil
+}
+
+func (s *fakeTokenStorage) DeleteByFamilyID(_ context.Context, familyID uint64) error {
+	s.revokedFamily = familyID
+
+	return nil
+}
+
+type fakeUserStorage struct {
+	userdomain.Storage
+	user *userdomain.User
+}
+
+func (s *fakeUserStorage) ByID(_ context.Context, id uint64) (*userdomain.User, error) {
+	if s.user == nil || s.user.ID != id {
+		return nil, userdomain.ErrUserNotFound
+	}
+
+	return s.user, nil
+}
+
+type fakeLock struct{}
+
+func (fakeLock) Release(context.Context) error {
+	return nil
+}
+
+type fakeLocker struct{}
+
+func (fakeLocker) TryAcquire(context.Context, string) (lock.Lock, error) {
+	return fakeLock{}, nil
+}
+
+var errFakeFailure = errors.New("fake failure")
+
+type fakeRescanLinkStorage struct {
+	link.Storage
+	blockErrs map[uint64]error
+	blocked   map[uint64]string
+	links     []link.Link
+}
+
+func (s *fakeRescanLinkStorage) Live(_ context.Context, afterID uint64, limit uint32) ([]link.Link, error) {
+	page := make([]link.Link, 0, limit)
+
+	for _, l := range s.links {
+		if l.ID > afterID && uint32(len(page)) < limit { //nolint:gosec // a page never holds more than limit links
+			page = append(page, l)
+		}
+	}
+
+	return page, nil
+}
+
+func (s *fakeRescanLinkStorage) Block(_ context.Context, id uint64, reason string) error {
+	if err := s.blockErrs[id]; err != nil {
+		return err
+	}
+
+	s.blocked[id] = reason
+
+	return nil
+}
+
+// fakeURLChecker finds the urls in unsafe blocklisted and fails to check the urls in failing.
+type fakeURLChecker struct {
+	unsafe  map[string]bool
+	failing map[string]bool
+}
+
+func (c *fakeURLChecker) Check(_ context.Context, rawURL string) (safety.Verdict, error) {
+	if c.failing[rawURL] {
+		return safety.Verdict{}, errFakeFailure
+	}
+
+	if c.unsafe[rawURL] {
+		return safety.Unsafe("blocklisted"), nil
+	}
+
+	return safety.Safe(), nil
+}
+
+type fakeModeratedLinkStorage struct {
+	link.Storage
+	link    *link.Link
+	actions []moderation.Action
+}
+
+func (s *fakeModeratedLinkStorage) ByID(_ context.Context, id uint64) (*link.Link, error) {
+	if s.link == nil || s.link.ID != id {
+		return nil, link.ErrNotFound
+	}
+
+	return s.link, nil
+}
+
+func (s *fakeModeratedLinkStorage) Block(_ context.Context, id uint64, reason string) error {
+	if s.link == nil || s.link.ID != id || s.link.IsBlocked() {
+		return link.ErrNotFound
+	}
+
+	blockedAt := time.Now()
+	s.link.BlockedAt = &blockedAt
+	s.link.BlockedReason = reason
+
+	return nil
+}
+
+func (s *fakeModeratedLinkStorage) Disable(ctx context.Context, action *moderation.Action) error {
+	if err := s.Block(ctx, action.TargetID, action.Reason); err != nil {
+		return err
+	}
+
+	s.actions = append(s.actions, *action)
+
+	return nil
+}
+
+type fakeModerationStorage struct {
+	moderation.Storage
+	actions []moderation.Action
+}
+
+func (s *fakeModerationStorage) Create(_ context.Context, action *moderation.Action) error {
+	s.actions = append(s.actions, *action)
+
+	return nil
+}
+
+// fakeReportStorage keeps a single open report per link and reporter, the way the storage does.
+type fakeReportStorage struct {
+	report.Storage
+	reporters map[uint64]map[string]bool
+	resolved  bool
+}
+
+func (s *fakeReportStorage) Create(_ context.Context, r *report.Report) error {
+	if s.reporters[r.LinkID][r.ReporterID] {
+		return report.ErrAlreadyReported
+	}
+
+	if s.reporters[r.LinkID] == nil {
+		s.reporters[r.LinkID] = make(map[string]bool)
+	}
+
+	s.reporters[r.LinkID][r.ReporterID] = true
+
+	return nil
+}
+
+func (s *fakeReportStorage) Reporters(_ context.Context, linkID uint64) (uint32, error) {
+	return uint32(len(s.reporters[linkID])), nil //nolint:gosec // a test never holds that many reporters
+}
+
+func (s *fakeReportStorage) Resolve(context.Context, uint64) error {
+	s.resolved = true
+
+	return nil
+}
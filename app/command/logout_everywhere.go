@@ -0,0 +1,26 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	tokendomain "github.com/truewebber/link-shortener/domain/token"
+)
+
+type LogoutEverywhereHandler struct {
+	tokenStorage tokendomain.Storage
+}
+
+func NewLogoutEverywhereHandler(tokenStorage tokendomain.Storage) *LogoutEverywhereHandler {
+	return &LogoutEverywhereHandler{
+		tokenStorage: tokenStorage,
+	}
+}
+
+func (h *LogoutEverywhereHandler) Handle(ctx context.Context, userID uint64) error {
+	if err := h.tokenStorage.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("delete tokens by user id: %w", err)
+	}
+
+	return nil
+}
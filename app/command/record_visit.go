@@ -0,0 +1,25 @@
+package command
+
+import "github.com/truewebber/link-shortener/domain/stats"
+
+type RecordVisitParams struct {
+	ClientIP  string
+	UserAgent string
+	LinkID    uint64
+}
+
+type RecordVisitHandler struct {
+	recorder   stats.Recorder
+	visitorKey []byte
+}
+
+func NewRecordVisitHandler(recorder stats.Recorder, visitorKey []byte) *RecordVisitHandler {
+	return &RecordVisitHandler{
+		recorder:   recorder,
+		visitorKey: visitorKey,
+	}
+}
+
+func (h *RecordVisitHandler) Handle(params RecordVisitParams) {
+	h.recorder.Record(stats.NewVisit(params.LinkID, params.ClientIP, params.UserAgent, h.visitorKey))
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/truewebber/gopkg/log"
+
 	apperrors "github.com/truewebber/link-shortener/app/errors"
 	"github.com/truewebber/link-shortener/app/types"
 	tokendomain "github.com/truewebber/link-shortener/domain/token"
@@ -14,20 +16,29 @@ import (
 type RefreshTokenHandler struct {
 	userStorage  userdomain.Storage
 	tokenStorage tokendomain.Storage
+	logger       log.Logger
 }
 
 func NewRefreshTokenHandler(
 	userStorage userdomain.Storage,
 	tokenStorage tokendomain.Storage,
+	logger log.Logger,
 ) *RefreshTokenHandler {
 	return &RefreshTokenHandler{
 		userStorage:  userStorage,
 		tokenStorage: tokenStorage,
+		logger:       logger,
 	}
 }
 
-func (h *RefreshTokenHandler) Handle(ctx context.Context, refreshTokenString string) (*types.Auth, error) {
-	token, err := h.tokenStorage.ByRefreshToken(ctx, refreshTokenString)
+type RefreshTokenParams struct {
+	RefreshToken string
+	UserAgent    string
+	ClientIP     string
+}
+
+func (h *RefreshTokenHandler) Handle(ctx context.Context, params RefreshTokenParams) (*types.Auth, error) {
+	token, err := h.tokenStorage.ByRefreshToken(ctx, params.RefreshToken)
 	if errors.Is(err, tokendomain.ErrTokenNotFound) {
 		return nil, apperrors.ErrInvalidCredentials
 	}
@@ -36,30 +47,34 @@ func (h *RefreshTokenHandler) Handle(ctx context.Context, refreshTokenString str
 		return nil, fmt.Errorf("find refresh token: %w", err)
 	}
 
-	if !token.CanBeRefreshed() {
-		return nil, apperrors.ErrTokenExpired
+	if token.IsRotated() {
+		return nil, h.revokeFamily(ctx, token, params)
 	}
 
-	user, err := h.userStorage.ByID(ctx, token.UserID)
-	if errors.Is(err, userdomain.ErrUserNotFound) {
-		return nil, apperrors.ErrUserNotFound
+	if !token.CanBeRefreshed() {
+		return nil, apperrors.ErrTokenExpired
 	}
 
+	user, err := h.activeUser(ctx, token.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("find user: %w", err)
+		return nil, err
 	}
 
-	newToken, err := tokendomain.GenerateNewToken(user.ID, AccessTokenDuration, RefreshTokenDuration)
+	device := tokendomain.NewDevice(params.UserAgent, params.ClientIP)
+
+	newToken, err := token.Rotate(device, AccessTokenDuration, RefreshTokenDuration)
 	if err != nil {
-		return nil, fmt.Errorf("generate new token: %w", err)
+		return nil, fmt.Errorf("rotate token: %w", err)
 	}
 
-	if createErr := h.tokenStorage.Create(ctx, newToken); createErr != nil {
-		return nil, fmt.Errorf("create token: %w", createErr)
+	rotateErr := h.tokenStorage.Rotate(ctx, newToken)
+	if errors.Is(rotateErr, tokendomain.ErrTokenAlreadyRotated) {
+		// a concurrent refresh with the same token won, one of the two callers replays it
+		return nil, h.revokeFamily(ctx, token, params)
 	}
 
-	if deleteErr := h.tokenStorage.DeleteByID(ctx, token.ID); deleteErr != nil {
-		return nil, fmt.Errorf("delete token: %w", deleteErr)
+	if rotateErr != nil {
+		return nil, fmt.Errorf("store rotated token: %w", rotateErr)
 	}
 
 	builtUser, err := types.BuildUserFromDomain(user)
@@ -72,3 +87,41 @@ func (h *RefreshTokenHandler) Handle(ctx context.Context, refreshTokenString str
 		User:  builtUser,
 	}, nil
 }
+
+// activeUser turns banned users away, their sessions are revoked with the ban already.
+func (h *RefreshTokenHandler) activeUser(ctx context.Context, userID uint64) (*userdomain.User, error) {
+	user, err := h.userStorage.ByID(ctx, userID)
+	if errors.Is(err, userdomain.ErrUserNotFound) {
+		return nil, apperrors.ErrUserNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("find user: %w", err)
+	}
+
+	if user.IsBanned() {
+		return nil, apperrors.ErrUserBanned
+	}
+
+	return user, nil
+}
+
+// revokeFamily ends every session rotated from the same login, a reused refresh token means it leaked.
+func (h *RefreshTokenHandler) revokeFamily(
+	ctx context.Context, token *tokendomain.Token, params RefreshTokenParams,
+) error {
+	h.logger.Error("security event: refresh token reuse detected, revoking token family",
+		"event", "refresh_token_reuse",
+		"user_id", token.UserID,
+		"token_id", token.ID,
+		"family_id", token.FamilyID,
+		"client_ip", params.ClientIP,
+		"user_agent", params.UserAgent,
+	)
+
+	if err := h.tokenStorage.DeleteByFamilyID(ctx, token.FamilyID); err != nil {
+		return fmt.Errorf("delete token family: %w", err)
+	}
+
+	return apperrors.ErrInvalidCredentials
+}
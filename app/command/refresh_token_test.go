@@ -0,0 +1,129 @@
+package command_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/app/command"
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	tokendomain "github.com/truewebber/link-shortener/domain/token"
+	userdomain "github.com/truewebber/link-shortener/domain/user"
+)
+
+func TestRefreshTokenHandle(t *testing.T) {
+	t.Parallel()
+
+	aSecondAgo := time.Now().Add(-time.Second)
+	aMinuteAgo := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		tokenStorage      *fakeTokenStorage
+		user              *userdomain.User
+		wantErr           error
+		name              string
+		refreshToken      string
+		wantRevokedFamily uint64
+		wantStored        bool
+	}{
+		{
+			name: "Rotate a live refresh token into a new pair of the same family",
+			tokenStorage: &fakeTokenStorage{token: &tokendomain.Token{
+				ID: 42, UserID: 7, FamilyID: 40, RefreshToken: "live-refresh-token",
+				RefreshTokenExpiresAt: time.Now().Add(time.Hour),
+			}},
+			user:         &userdomain.User{ID: 7, Provider: userdomain.ProviderGoogle},
+			refreshToken: "live-refresh-token",
+			wantStored:   true,
+		},
+		{
+			name: "Revoke the family when a rotated token is reused",
+			tokenStorage: &fakeTokenStorage{token: &tokendomain.Token{
+				ID: 42, UserID: 7, FamilyID: 40, RefreshToken: "rotated-refresh-token",
+				RefreshTokenExpiresAt: time.Now().Add(time.Hour), RotatedAt: &aSecondAgo,
+			}},
+			user:              &userdomain.User{ID: 7, Provider: userdomain.ProviderGoogle},
+			refreshToken:      "rotated-refresh-token",
+			wantErr:           apperrors.ErrInvalidCredentials,
+			wantRevokedFamily: 40,
+		},
+		{
+			name: "Revoke the family when a concurrent refresh rotated the token first",
+			tokenStorage: &fakeTokenStorage{
+				token: &tokendomain.Token{
+					ID: 42, UserID: 7, FamilyID: 40, RefreshToken: "raced-refresh-token",
+					RefreshTokenExpiresAt: time.Now().Add(time.Hour),
+				},
+				rotateErr: tokendomain.ErrTokenAlreadyRotated,
+			},
+			user:              &userdomain.User{ID: 7, Provider: userdomain.ProviderGoogle},
+			refreshToken:      "raced-refresh-token",
+			wantErr:           apperrors.ErrInvalidCredentials,
+			wantRevokedFamily: 40,
+		},
+		{
+			name: "Return error if the refresh token expired",
+			tokenStorage: &fakeTokenStorage{token: &tokendomain.Token{
+				ID: 42, UserID: 7, FamilyID: 40, RefreshToken: "expired-refresh-token",
+				RefreshTokenExpiresAt: time.Now().Add(-time.Minute),
+			}},
+			user:         &userdomain.User{ID: 7, Provider: userdomain.ProviderGoogle},
+			refreshToken: "expired-refresh-token",
+			wantErr:      apperrors.ErrTokenExpired,
+		},
+		{
+			name:         "Return error if the refresh token is unknown",
+			tokenStorage: &fakeTokenStorage{},
+			user:         &userdomain.User{ID: 7, Provider: userdomain.ProviderGoogle},
+			refreshToken: "unknown-refresh-token",
+			wantErr:      apperrors.ErrInvalidCredentials,
+		},
+		{
+			name: "Return error if the user is banned",
+			tokenStorage: &fakeTokenStorage{token: &tokendomain.Token{
+				ID: 42, UserID: 7, FamilyID: 40, RefreshToken: "live-refresh-token",
+				RefreshTokenExpiresAt: time.Now().Add(time.Hour),
+			}},
+			user:         &userdomain.User{ID: 7, Provider: userdomain.ProviderGoogle, BannedAt: &aMinuteAgo},
+			refreshToken: "live-refresh-token",
+			wantErr:      apperrors.ErrUserBanned,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := command.NewRefreshTokenHandler(&fakeUserStorage{user: tt.user}, tt.tokenStorage, log.NewLogger())
+
+			auth, err := handler.Handle(context.Background(), command.RefreshTokenParams{RefreshToken: tt.refreshToken})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Handle() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.tokenStorage.revokedFamily != tt.wantRevokedFamily {
+				t.Errorf("revoked family = %d, want %d", tt.tokenStorage.revokedFamily, tt.wantRevokedFamily)
+			}
+
+			stored := tt.tokenStorage.stored
+			if (stored != nil) != tt.wantStored {
+				t.Fatalf("stored new token = %v, want %v", stored != nil, tt.wantStored)
+			}
+
+			if !tt.wantStored {
+				return
+			}
+
+			if stored.FamilyID != 40 || stored.ParentID != 42 {
+				t.Errorf("new token family = %d, parent = %d, want 40, 42", stored.FamilyID, stored.ParentID)
+			}
+
+			if auth.Token.RefreshToken != stored.RefreshToken || auth.Token.RefreshToken == tt.refreshToken {
+				t.Errorf("returned refresh token %q is not the new stored one", auth.Token.RefreshToken)
+			}
+		})
+	}
+}
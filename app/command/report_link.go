@@ -0,0 +1,103 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/link"
+	"github.com/truewebber/link-shortener/domain/moderation"
+	"github.com/truewebber/link-shortener/domain/report"
+)
+
+type ReportLinkParams struct {
+	ClientIP string
+	Reason   string
+	Contact  string
+	LinkID   uint64
+}
+
+type ReportLinkHandler struct {
+	linkStorage          link.Storage
+	reportStorage        report.Storage
+	logger               log.Logger
+	reporterKey          []byte
+	autoDisableThreshold uint32
+}
+
+// NewReportLinkHandler blocks the link once autoDisableThreshold distinct reporters have open reports on it,
+// a zero threshold leaves every link to the moderators. Reporters are hashed with reporterKey.
+func NewReportLinkHandler(
+	linkStorage link.Storage,
+	reportStorage report.Storage,
+	reporterKey []byte,
+	autoDisableThreshold uint32,
+	logger log.Logger,
+) *ReportLinkHandler {
+	return &ReportLinkHandler{
+		linkStorage:          linkStorage,
+		reportStorage:        reportStorage,
+		logger:               logger,
+		reporterKey:          reporterKey,
+		autoDisableThreshold: autoDisableThreshold,
+	}
+}
+
+const autoDisableReason = "reported as abusive"
+
+// Handle accepts a repeated report of the same reporter silently, it does not count twice.
+func (h *ReportLinkHandler) Handle(ctx context.Context, params ReportLinkParams) error {
+	reporter := "ip:" + types.ClientNetwork(params.ClientIP)
+
+	r, err := report.New(params.LinkID, reporter, params.Reason, params.Contact, h.reporterKey)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	err = h.reportStorage.Create(ctx, r)
+	if errors.Is(err, report.ErrAlreadyReported) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("create report: %w", err)
+	}
+
+	if h.autoDisableThreshold == 0 {
+		return nil
+	}
+
+	reporters, err := h.reportStorage.Reporters(ctx, params.LinkID)
+	if err != nil {
+		return fmt.Errorf("count reporters: %w", err)
+	}
+
+	if reporters < h.autoDisableThreshold {
+		return nil
+	}
+
+	return h.autoDisable(ctx, params.LinkID, reporters)
+}
+
+// autoDisable records the block as a moderation action of the anonymous user, the reports stay open
+// for the moderators to confirm or lift it.
+func (h *ReportLinkHandler) autoDisable(ctx context.Context, linkID uint64, reporters uint32) error {
+	action := moderation.New(types.AnonymousUser().ID, moderation.KindDisableLink, linkID, autoDisableReason)
+
+	err := h.linkStorage.Disable(ctx, action)
+	if errors.Is(err, link.ErrNotFound) {
+		// blocked already
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("disable link: %w", err)
+	}
+
+	h.logger.Info("link disabled by abuse reports", "link_id", linkID, "reporters", reporters)
+
+	return nil
+}
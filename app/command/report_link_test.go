@@ -0,0 +1,121 @@
+package command_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/app/command"
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+func TestReportLinkHandle(t *testing.T) {
+	t.Parallel()
+
+	blockedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		linkStorage   *fakeModeratedLinkStorage
+		name          string
+		clientIPs     []string
+		threshold     uint32
+		wantReporters int
+		wantDisabled  bool
+	}{
+		{
+			name:          "Keep the link live below the threshold",
+			linkStorage:   &fakeModeratedLinkStorage{link: &link.Link{ID: 11}},
+			clientIPs:     []string{"198.51.100.1", "198.51.100.2"},
+			threshold:     3,
+			wantReporters: 2,
+		},
+		{
+			name:          "Disable the link once the threshold of reporters is reached",
+			linkStorage:   &fakeModeratedLinkStorage{link: &link.Link{ID: 11}},
+			clientIPs:     []string{"198.51.100.1", "198.51.100.2", "2001:db8:1::1"},
+			threshold:     3,
+			wantReporters: 3,
+			wantDisabled:  true,
+		},
+		{
+			name:          "Count a repeated report of the same reporter once",
+			linkStorage:   &fakeModeratedLinkStorage{link: &link.Link{ID: 11}},
+			clientIPs:     []string{"198.51.100.1", "198.51.100.1", "198.51.100.2"},
+			threshold:     3,
+			wantReporters: 2,
+		},
+		{
+			name:          "Count the addresses of one IPv6 /64 as a single reporter",
+			linkStorage:   &fakeModeratedLinkStorage{link: &link.Link{ID: 11}},
+			clientIPs:     []string{"2001:db8:1:2::1", "2001:db8:1:2::2", "2001:db8:1:2:ffff::3"},
+			threshold:     3,
+			wantReporters: 1,
+		},
+		{
+			name:          "Count the addresses of different IPv6 /64 networks apart",
+			linkStorage:   &fakeModeratedLinkStorage{link: &link.Link{ID: 11}},
+			clientIPs:     []string{"2001:db8:1:1::1", "2001:db8:1:2::1", "2001:db8:1:3::1"},
+			threshold:     3,
+			wantReporters: 3,
+			wantDisabled:  true,
+		},
+		{
+			name:          "Count an IPv4 mapped address as the IPv4 one",
+			linkStorage:   &fakeModeratedLinkStorage{link: &link.Link{ID: 11}},
+			clientIPs:     []string{"198.51.100.1", "::ffff:198.51.100.1"},
+			threshold:     3,
+			wantReporters: 1,
+		},
+		{
+			name:          "Leave every link to the moderators with a zero threshold",
+			linkStorage:   &fakeModeratedLinkStorage{link: &link.Link{ID: 11}},
+			clientIPs:     []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"},
+			wantReporters: 3,
+		},
+		{
+			name: "Accept reports of a link blocked already without disabling it again",
+			linkStorage: &fakeModeratedLinkStorage{
+				link: &link.Link{ID: 11, BlockedAt: &blockedAt, BlockedReason: "malware"},
+			},
+			clientIPs:     []string{"198.51.100.1", "198.51.100.2", "198.51.100.3", "198.51.100.4"},
+			threshold:     3,
+			wantReporters: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reportStorage := &fakeReportStorage{reporters: make(map[uint64]map[string]bool)}
+			handler := command.NewReportLinkHandler(
+				tt.linkStorage, reportStorage, []byte("client-hash-secret"), tt.threshold, log.NewLogger(),
+			)
+
+			for _, clientIP := range tt.clientIPs {
+				if err := handler.Handle(context.Background(), command.ReportLinkParams{
+					ClientIP: clientIP,
+					Reason:   "phishing",
+					LinkID:   11,
+				}); err != nil {
+					t.Fatalf("Handle() error = %v", err)
+				}
+			}
+
+			if reporters := len(reportStorage.reporters[11]); reporters != tt.wantReporters {
+				t.Errorf("reporters = %d, want %d", reporters, tt.wantReporters)
+			}
+
+			if disabled := len(tt.linkStorage.actions) == 1; disabled != tt.wantDisabled || len(tt.linkStorage.actions) > 1 {
+				t.Fatalf("disable actions = %d, want disabled %v", len(tt.linkStorage.actions), tt.wantDisabled)
+			}
+
+			if tt.wantDisabled && tt.linkStorage.actions[0].ModeratorID != types.AnonymousUser().ID {
+				t.Errorf("auto disable recorded moderator %d, want the anonymous user", tt.linkStorage.actions[0].ModeratorID)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/truewebber/gopkg/log"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/domain/link"
+	"github.com/truewebber/link-shortener/domain/lock"
+	"github.com/truewebber/link-shortener/domain/safety"
+)
+
+type RescanLinksParams struct {
+	BatchSize uint32
+}
+
+// RescanLinksResult counts a link that could not be checked or blocked as Failed, not Scanned.
+type RescanLinksResult struct {
+	Scanned uint64
+	Blocked uint64
+	Failed  uint64
+}
+
+type RescanLinksHandler struct {
+	linkStorage   link.Storage
+	safetyChecker safety.Checker
+	locker        lock.Locker
+	logger        log.Logger
+}
+
+// NewRescanLinksHandler blocks live links whose destination went bad after they were created,
+// blocked links stay in place so that their owners can see why they stopped working.
+func NewRescanLinksHandler(
+	linkStorage link.Storage,
+	safetyChecker safety.Checker,
+	locker lock.Locker,
+	logger log.Logger,
+) *RescanLinksHandler {
+	return &RescanLinksHandler{
+		linkStorage:   linkStorage,
+		safetyChecker: safetyChecker,
+		locker:        locker,
+		logger:        logger,
+	}
+}
+
+const rescanLinksLockKey = "link-shortener:rescan-links"
+
+func (h *RescanLinksHandler) Handle(ctx context.Context, params RescanLinksParams) (RescanLinksResult, error) {
+	if params.BatchSize == 0 {
+		return RescanLinksResult{}, fmt.Errorf("%w: %w", ErrValidation, errZeroBatchSize)
+	}
+
+	l, err := h.locker.TryAcquire(ctx, rescanLinksLockKey)
+	if errors.Is(err, lock.ErrNotAcquired) {
+		return RescanLinksResult{}, apperrors.ErrLocked
+	}
+
+	if err != nil {
+		return RescanLinksResult{}, fmt.Errorf("acquire lock: %w", err)
+	}
+
+	defer func() {
+		if releaseErr := l.Release(context.WithoutCancel(ctx)); releaseErr != nil {
+			h.logger.Error("failed to release rescan links lock", "error", releaseErr)
+		}
+	}()
+
+	return h.rescanAll(ctx, params.BatchSize)
+}
+
+func (h *RescanLinksHandler) rescanAll(ctx context.Context, batchSize uint32) (RescanLinksResult, error) {
+	result := RescanLinksResult{}
+
+	for afterID := uint64(0); ; {
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("context: %w", err)
+		}
+
+		links, err := h.linkStorage.Live(ctx, afterID, batchSize)
+		if err != nil {
+			return result, fmt.Errorf("get live links: %w", err)
+		}
+
+		for i := range links {
+			blocked, rescanErr := h.rescan(ctx, &links[i])
+			if rescanErr != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return result, fmt.Errorf("context: %w", ctxErr)
+				}
+
+				// one link the checker or the storage trips over must not keep the rest from being scanned
+				h.logger.Error("failed to rescan link", "link_id", links[i].ID, "error", rescanErr)
+
+				result.Failed++
+
+				continue
+			}
+
+			result.Scanned++
+
+			if blocked {
+				result.Blocked++
+			}
+		}
+
+		//nolint:gosec // a batch never holds more than BatchSize links
+		if uint32(len(links)) < batchSize {
+			return result, nil
+		}
+
+		afterID = links[len(links)-1].ID
+	}
+}
+
+func (h *RescanLinksHandler) rescan(ctx context.Context, l *link.Link) (bool, error) {
+	verdict, err := h.safetyChecker.Check(ctx, l.RedirectURL)
+	if err != nil {
+		return false, fmt.Errorf("check url: %w", err)
+	}
+
+	if verdict.Safe {
+		return false, nil
+	}
+
+	err = h.linkStorage.Block(ctx, l.ID, verdict.Reason)
+	if errors.Is(err, link.ErrNotFound) {
+		// deleted or blocked since the batch was read
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("block link: %w", err)
+	}
+
+	h.logger.Info("link blocked", "link_id", l.ID, "reason", verdict.Reason)
+
+	return true, nil
+}
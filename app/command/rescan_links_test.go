@@ -0,0 +1,108 @@
+package command_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/app/command"
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+func TestRescanLinksHandle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		storage     *fakeRescanLinkStorage
+		wantBlocked []uint64
+		name        string
+		wantResult  command.RescanLinksResult
+	}{
+		{
+			name: "Block the unsafe links of every batch",
+			storage: &fakeRescanLinkStorage{
+				links: []link.Link{
+					{ID: 1, RedirectURL: "https://safe.example"},
+					{ID: 2, RedirectURL: "https://unsafe.example"},
+					{ID: 3, RedirectURL: "https://safe.example"},
+					{ID: 4, RedirectURL: "https://unsafe.example"},
+					{ID: 5, RedirectURL: "https://safe.example"},
+				},
+				blocked: make(map[uint64]string),
+			},
+			wantBlocked: []uint64{2, 4},
+			wantResult:  command.RescanLinksResult{Scanned: 5, Blocked: 2},
+		},
+		{
+			name: "Skip a link deleted or blocked since its batch was read",
+			storage: &fakeRescanLinkStorage{
+				links: []link.Link{
+					{ID: 1, RedirectURL: "https://unsafe.example"},
+					{ID: 2, RedirectURL: "https://safe.example"},
+				},
+				blockErrs: map[uint64]error{1: link.ErrNotFound},
+				blocked:   make(map[uint64]string),
+			},
+			wantResult: command.RescanLinksResult{Scanned: 2},
+		},
+		{
+			name: "Keep scanning after a link fails the check",
+			storage: &fakeRescanLinkStorage{
+				links: []link.Link{
+					{ID: 1, RedirectURL: "https://unchecked.example"},
+					{ID: 2, RedirectURL: "https://unsafe.example"},
+					{ID: 3, RedirectURL: "https://unchecked.example"},
+				},
+				blocked: make(map[uint64]string),
+			},
+			wantBlocked: []uint64{2},
+			wantResult:  command.RescanLinksResult{Scanned: 1, Blocked: 1, Failed: 2},
+		},
+		{
+			name: "Keep scanning after a link fails to be blocked",
+			storage: &fakeRescanLinkStorage{
+				links: []link.Link{
+					{ID: 1, RedirectURL: "https://unsafe.example"},
+					{ID: 2, RedirectURL: "https://unsafe.example"},
+					{ID: 3, RedirectURL: "https://safe.example"},
+				},
+				blockErrs: map[uint64]error{1: errFakeFailure},
+				blocked:   make(map[uint64]string),
+			},
+			wantBlocked: []uint64{2},
+			wantResult:  command.RescanLinksResult{Scanned: 2, Blocked: 1, Failed: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			checker := &fakeURLChecker{
+				unsafe:  map[string]bool{"https://unsafe.example": true},
+				failing: map[string]bool{"https://unchecked.example": true},
+			}
+			handler := command.NewRescanLinksHandler(tt.storage, checker, fakeLocker{}, log.NewLogger())
+
+			result, err := handler.Handle(context.Background(), command.RescanLinksParams{BatchSize: 2})
+			if err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+
+			if result != tt.wantResult {
+				t.Errorf("Handle() = %+v, want %+v", result, tt.wantResult)
+			}
+
+			if len(tt.storage.blocked) != len(tt.wantBlocked) {
+				t.Fatalf("blocked links = %v, want %v", tt.storage.blocked, tt.wantBlocked)
+			}
+
+			for _, id := range tt.wantBlocked {
+				if _, ok := tt.storage.blocked[id]; !ok {
+					t.Errorf("link %d is not blocked, blocked links = %v", id, tt.storage.blocked)
+				}
+			}
+		})
+	}
+}
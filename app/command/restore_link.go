@@ -0,0 +1,84 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/linkhash"
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+type RestoreLinkParams struct {
+	Hash   string
+	UserID uint64
+}
+
+type RestoreLinkHandler struct {
+	linkStorage  link.Storage
+	hashResolver *linkhash.Resolver
+}
+
+func NewRestoreLinkHandler(
+	linkStorage link.Storage,
+	hashResolver *linkhash.Resolver,
+) *RestoreLinkHandler {
+	return &RestoreLinkHandler{
+		linkStorage:  linkStorage,
+		hashResolver: hashResolver,
+	}
+}
+
+const LinkRestoreGracePeriod = 7 * 24 * time.Hour
+
+func (h *RestoreLinkHandler) Handle(ctx context.Context, params RestoreLinkParams) (*types.Link, error) {
+	id, err := h.hashResolver.LinkID(ctx, params.Hash)
+	if errors.Is(err, link.ErrNotFound) {
+		return nil, apperrors.ErrLinkNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("resolve link id: %w", err)
+	}
+
+	l, err := h.linkStorage.DeletedByID(ctx, id)
+	if errors.Is(err, link.ErrNotFound) {
+		return nil, apperrors.ErrLinkNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("find deleted link: %w", err)
+	}
+
+	if !l.IsOwnedBy(params.UserID) {
+		return nil, apperrors.ErrLinkNotFound
+	}
+
+	if !l.CanBeRestored(LinkRestoreGracePeriod) {
+		return nil, apperrors.ErrLinkNotRestorable
+	}
+
+	restoreErr := h.linkStorage.Restore(ctx, l.ID)
+	if errors.Is(restoreErr, link.ErrAlreadyExists) {
+		return nil, apperrors.ErrLinkAlreadyExists
+	}
+
+	if errors.Is(restoreErr, link.ErrAliasAlreadyExists) {
+		return nil, apperrors.ErrAliasAlreadyExists
+	}
+
+	if errors.Is(restoreErr, link.ErrNotFound) {
+		return nil, apperrors.ErrLinkNotFound
+	}
+
+	if restoreErr != nil {
+		return nil, fmt.Errorf("restore link: %w", restoreErr)
+	}
+
+	l.DeletedAt = nil
+
+	return types.BuildLinkFromDomain(l, params.Hash), nil
+}
@@ -0,0 +1,51 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/domain/pat"
+)
+
+type RevokePersonalTokenParams struct {
+	ID     uint64
+	UserID uint64
+}
+
+type RevokePersonalTokenHandler struct {
+	patStorage pat.Storage
+}
+
+func NewRevokePersonalTokenHandler(patStorage pat.Storage) *RevokePersonalTokenHandler {
+	return &RevokePersonalTokenHandler{
+		patStorage: patStorage,
+	}
+}
+
+func (h *RevokePersonalTokenHandler) Handle(ctx context.Context, params RevokePersonalTokenParams) error {
+	token, err := h.patStorage.ByID(ctx, params.ID)
+	if errors.Is(err, pat.ErrNotFound) {
+		return apperrors.ErrPersonalTokenNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("find personal token: %w", err)
+	}
+
+	if !token.IsOwnedBy(params.UserID) {
+		return apperrors.ErrPersonalTokenNotFound
+	}
+
+	deleteErr := h.patStorage.Delete(ctx, token.ID)
+	if errors.Is(deleteErr, pat.ErrNotFound) {
+		return apperrors.ErrPersonalTokenNotFound
+	}
+
+	if deleteErr != nil {
+		return fmt.Errorf("delete personal token: %w", deleteErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	tokendomain "github.com/truewebber/link-shortener/domain/token"
+)
+
+type RevokeSessionParams struct {
+	ID     uint64
+	UserID uint64
+}
+
+type RevokeSessionHandler struct {
+	tokenStorage tokendomain.Storage
+}
+
+func NewRevokeSessionHandler(tokenStorage tokendomain.Storage) *RevokeSessionHandler {
+	return &RevokeSessionHandler{
+		tokenStorage: tokenStorage,
+	}
+}
+
+func (h *RevokeSessionHandler) Handle(ctx context.Context, params RevokeSessionParams) error {
+	token, err := h.tokenStorage.ByID(ctx, params.ID)
+	if errors.Is(err, tokendomain.ErrTokenNotFound) {
+		return apperrors.ErrSessionNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("find token: %w", err)
+	}
+
+	if !token.IsOwnedBy(params.UserID) {
+		return apperrors.ErrSessionNotFound
+	}
+
+	if deleteErr := h.tokenStorage.DeleteByID(ctx, token.ID); deleteErr != nil {
+		return fmt.Errorf("delete token: %w", deleteErr)
+	}
+
+	return nil
+}
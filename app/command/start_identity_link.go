@@ -0,0 +1,33 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/truewebber/link-shortener/domain/identity"
+)
+
+// IdentityLinkTTL matches the lifetime of the OAuth state cookies the link nonce travels with.
+const IdentityLinkTTL = 5 * time.Minute
+
+type StartIdentityLinkHandler struct {
+	linkRequestStorage identity.LinkRequestStorage
+}
+
+func NewStartIdentityLinkHandler(linkRequestStorage identity.LinkRequestStorage) *StartIdentityLinkHandler {
+	return &StartIdentityLinkHandler{
+		linkRequestStorage: linkRequestStorage,
+	}
+}
+
+// Handle returns the nonce the OAuth callback exchanges for the user, the access token never leaves the API.
+func (h *StartIdentityLinkHandler) Handle(ctx context.Context, userID uint64) (string, error) {
+	request := identity.NewLinkRequest(userID, IdentityLinkTTL)
+
+	if err := h.linkRequestStorage.Create(ctx, request); err != nil {
+		return "", fmt.Errorf("create link request: %w", err)
+	}
+
+	return request.Nonce, nil
+}
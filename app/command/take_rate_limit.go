@@ -0,0 +1,81 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/ratelimit"
+)
+
+type TakeRateLimitParams struct {
+	Class types.RateLimitClass
+	// Key tells clients apart within the class, a user or a client IP.
+	Key string
+}
+
+type TakeRateLimitResult struct {
+	RetryAfter time.Duration
+	Allowed    bool
+}
+
+type TakeRateLimitHandler struct {
+	store    ratelimit.Store
+	limits   map[types.RateLimitClass]ratelimit.Limit
+	requests gokitmetrics.Counter
+	logger   log.Logger
+}
+
+// NewTakeRateLimitHandler leaves classes without a limit unlimited,
+// requests are let through when the store fails so that an outage of it does not take the API down.
+func NewTakeRateLimitHandler(
+	store ratelimit.Store,
+	limits map[types.RateLimitClass]ratelimit.Limit,
+	requests gokitmetrics.Counter,
+	logger log.Logger,
+) *TakeRateLimitHandler {
+	return &TakeRateLimitHandler{
+		store:    store,
+		limits:   limits,
+		requests: requests,
+		logger:   logger,
+	}
+}
+
+const (
+	rateLimitResultAllowed = "allowed"
+	rateLimitResultLimited = "limited"
+	rateLimitResultFailed  = "failed"
+)
+
+func (h *TakeRateLimitHandler) Handle(ctx context.Context, params TakeRateLimitParams) TakeRateLimitResult {
+	limit, ok := h.limits[params.Class]
+	if !ok || limit.Burst == 0 {
+		return TakeRateLimitResult{Allowed: true}
+	}
+
+	decision, err := h.store.Take(ctx, string(params.Class)+":"+params.Key, limit)
+	if err != nil {
+		h.logger.Error("failed to take rate limit token", "class", params.Class, "key", params.Key, "error", err)
+		h.count(params.Class, rateLimitResultFailed)
+
+		return TakeRateLimitResult{Allowed: true}
+	}
+
+	if !decision.Allowed {
+		h.count(params.Class, rateLimitResultLimited)
+
+		return TakeRateLimitResult{RetryAfter: decision.RetryAfter}
+	}
+
+	h.count(params.Class, rateLimitResultAllowed)
+
+	return TakeRateLimitResult{Allowed: true}
+}
+
+func (h *TakeRateLimitHandler) count(class types.RateLimitClass, result string) {
+	h.requests.With("class", string(class), "result", result).Add(1)
+}
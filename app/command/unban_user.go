@@ -0,0 +1,37 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/domain/moderation"
+	userdomain "github.com/truewebber/link-shortener/domain/user"
+)
+
+type UnbanUserHandler struct {
+	userStorage userdomain.Storage
+}
+
+// NewUnbanUserHandler lets the user sign in again, the revoked tokens are not brought back.
+func NewUnbanUserHandler(userStorage userdomain.Storage) *UnbanUserHandler {
+	return &UnbanUserHandler{
+		userStorage: userStorage,
+	}
+}
+
+func (h *UnbanUserHandler) Handle(ctx context.Context, params ModerateUserParams) error {
+	action := moderation.New(params.ModeratorID, moderation.KindUnbanUser, params.UserID, params.Reason)
+
+	err := h.userStorage.Unban(ctx, action)
+	if errors.Is(err, userdomain.ErrUserNotFound) {
+		return apperrors.ErrUserNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("unban user: %w", err)
+	}
+
+	return nil
+}
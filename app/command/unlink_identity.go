@@ -0,0 +1,87 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/domain/identity"
+	userdomain "github.com/truewebber/link-shortener/domain/user"
+)
+
+type UnlinkIdentityParams struct {
+	ID     uint64
+	UserID uint64
+}
+
+type UnlinkIdentityHandler struct {
+	userStorage     userdomain.Storage
+	identityStorage identity.Storage
+}
+
+func NewUnlinkIdentityHandler(
+	userStorage userdomain.Storage,
+	identityStorage identity.Storage,
+) *UnlinkIdentityHandler {
+	return &UnlinkIdentityHandler{
+		userStorage:     userStorage,
+		identityStorage: identityStorage,
+	}
+}
+
+func (h *UnlinkIdentityHandler) Handle(ctx context.Context, params UnlinkIdentityParams) error {
+	err := h.identityStorage.Delete(ctx, params.ID, params.UserID)
+
+	switch {
+	case errors.Is(err, identity.ErrNotFound):
+		return apperrors.ErrIdentityNotFound
+	case errors.Is(err, identity.ErrLastIdentity):
+		return apperrors.ErrLastIdentity
+	case err != nil:
+		return fmt.Errorf("delete identity: %w", err)
+	}
+
+	if err := h.replaceProfile(ctx, params.UserID); err != nil {
+		return fmt.Errorf("replace profile: %w", err)
+	}
+
+	return nil
+}
+
+// replaceProfile moves the user profile to a remaining identity when it showed the unlinked one.
+func (h *UnlinkIdentityHandler) replaceProfile(ctx context.Context, userID uint64) error {
+	user, err := h.userStorage.ByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("find user by id: %w", err)
+	}
+
+	identities, err := h.identityStorage.ByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get identities by user id: %w", err)
+	}
+
+	for i := range identities {
+		if identities[i].Provider == user.Provider && identities[i].ProviderID == user.ProviderID {
+			return nil
+		}
+	}
+
+	if len(identities) == 0 {
+		return nil
+	}
+
+	remaining := &identities[0]
+
+	user.Provider = remaining.Provider
+	user.ProviderID = remaining.ProviderID
+	user.Email = remaining.Email
+	user.Name = remaining.Name
+	user.AvatarURL = remaining.AvatarURL
+
+	if err := h.userStorage.Update(ctx, user); err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/linkhash"
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+type UpdateLinkTTLParams struct {
+	Hash        string
+	UserID      uint64
+	ExpiresType link.ExpiresType
+}
+
+type UpdateLinkTTLHandler struct {
+	linkStorage  link.Storage
+	hashResolver *linkhash.Resolver
+}
+
+func NewUpdateLinkTTLHandler(
+	linkStorage link.Storage,
+	hashResolver *linkhash.Resolver,
+) *UpdateLinkTTLHandler {
+	return &UpdateLinkTTLHandler{
+		linkStorage:  linkStorage,
+		hashResolver: hashResolver,
+	}
+}
+
+func (h *UpdateLinkTTLHandler) Handle(ctx context.Context, params UpdateLinkTTLParams) (*types.Link, error) {
+	id, err := h.hashResolver.LinkID(ctx, params.Hash)
+	if errors.Is(err, link.ErrNotFound) {
+		return nil, apperrors.ErrLinkNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("resolve link id: %w", err)
+	}
+
+	l, err := h.linkStorage.ByID(ctx, id)
+	if errors.Is(err, link.ErrNotFound) {
+		return nil, apperrors.ErrLinkNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("find link: %w", err)
+	}
+
+	if !l.IsOwnedBy(params.UserID) {
+		return nil, apperrors.ErrLinkNotFound
+	}
+
+	if changeErr := l.ChangeExpiresType(params.ExpiresType); changeErr != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, changeErr)
+	}
+
+	if updateErr := h.linkStorage.Update(ctx, l); updateErr != nil {
+		return nil, fmt.Errorf("update link: %w", updateErr)
+	}
+
+	return types.BuildLinkFromDomain(l, params.Hash), nil
+}
@@ -26,7 +26,9 @@ func (h *ValidateCaptchaHandler) Handle(ctx context.Context, response string) er
 
 	if errors.Is(err, captcha.ErrUnsuccessful) ||
 		errors.Is(err, captcha.ErrActionInvalid) ||
-		errors.Is(err, captcha.ErrNotHuman) {
+		errors.Is(err, captcha.ErrNotHuman) ||
+		errors.Is(err, captcha.ErrExpired) ||
+		errors.Is(err, captcha.ErrAlreadySpent) {
 		return apperrors.ErrCaptchaInvalid
 	}
 
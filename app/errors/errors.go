@@ -7,4 +7,31 @@ var (
 	ErrTokenExpired       = errors.New("token expired")
 	ErrUserNotFound       = errors.New("user not found")
 	ErrCaptchaInvalid     = errors.New("captcha invalid")
+	ErrLinkNotFound       = errors.New("link not found")
+	ErrLinkAlreadyExists  = errors.New("link already exists")
+	ErrLinkNotRestorable  = errors.New("link can not be restored")
+	ErrLocked             = errors.New("locked by another process")
+	ErrInvalidDateRange   = errors.New("invalid date range")
+	ErrAliasAlreadyExists = errors.New("alias already exists")
+
+	ErrPersonalTokenNotFound = errors.New("personal access token not found")
+	ErrTooManyPersonalTokens = errors.New("too many personal access tokens")
+
+	ErrSessionNotFound = errors.New("session not found")
+
+	ErrProviderNotFound = errors.New("oauth provider not found")
+
+	ErrIdentityNotFound      = errors.New("identity not found")
+	ErrIdentityAlreadyLinked = errors.New("identity already linked to another user")
+	ErrLastIdentity          = errors.New("last identity can not be unlinked")
+	ErrLinkRequestExpired    = errors.New("identity link request expired")
+
+	ErrCaptchaChallengeNotSupported = errors.New("captcha challenge not supported")
+
+	ErrUnsafeURL = errors.New("unsafe destination url")
+
+	ErrUserBanned         = errors.New("user banned")
+	ErrUserNotBannable    = errors.New("user can not be banned")
+	ErrLinkAlreadyBlocked = errors.New("link already blocked")
+	ErrLinkNotBlocked     = errors.New("link not blocked")
 )
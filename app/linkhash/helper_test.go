@@ -0,0 +1,73 @@
+package linkhash_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/truewebber/link-shortener/domain/alias"
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+type fakeLinkStorage struct {
+	link.Storage
+	idsByCode map[string]uint64
+	codesByID map[uint64]string
+}
+
+func (s *fakeLinkStorage) IDByCode(_ context.Context, code string) (uint64, error) {
+	id, ok := s.idsByCode[code]
+	if !ok {
+		return 0, link.ErrNotFound
+	}
+
+	return id, nil
+}
+
+func (s *fakeLinkStorage) CodeByID(_ context.Context, id uint64) (string, error) {
+	code, ok := s.codesByID[id]
+	if !ok {
+		return "", link.ErrNotFound
+	}
+
+	return code, nil
+}
+
+type fakeAliasStorage struct {
+	alias.Storage
+	linkIDs map[string]uint64
+}
+
+func (s *fakeAliasStorage) ByValue(_ context.Context, value string) (*alias.Alias, error) {
+	linkID, ok := s.linkIDs[value]
+	if !ok {
+		return nil, alias.ErrNotFound
+	}
+
+	return &alias.Alias{Value: value, LinkID: linkID}, nil
+}
+
+var errNotAHash = errors.New("not a hash")
+
+// fakeHashGenerator decodes only the hashes in ids.
+type fakeHashGenerator struct {
+	ids map[string]uint64
+}
+
+func (g *fakeHashGenerator) ToHash(id uint64) (string, error) {
+	for hash, hashID := range g.ids {
+		if hashID == id {
+			return hash, nil
+		}
+	}
+
+	return "", errNotAHash
+}
+
+func (g *fakeHashGenerator) FromHash(hash string) (uint64, error) {
+	id, ok := g.ids[hash]
+	if !ok {
+		return 0, errNotAHash
+	}
+
+	return id, nil
+}
@@ -0,0 +1,118 @@
+package linkhash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/truewebber/link-shortener/domain/alias"
+	"github.com/truewebber/link-shortener/domain/hash"
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+// Resolver maps short hashes to links for both strategies:
+// hashes derived from the link id and random codes stored with the link, as well as custom aliases.
+type Resolver struct {
+	linkStorage  link.Storage
+	aliasStorage alias.Storage
+	hashGen      hash.Generator
+	randomCodes  bool
+}
+
+// NewResolver takes randomCodes when new links get random codes, derived hashes then address only older links.
+func NewResolver(
+	linkStorage link.Storage, aliasStorage alias.Storage, hashGen hash.Generator, randomCodes bool,
+) *Resolver {
+	return &Resolver{
+		linkStorage:  linkStorage,
+		aliasStorage: aliasStorage,
+		hashGen:      hashGen,
+		randomCodes:  randomCodes,
+	}
+}
+
+// LinkID returns link.ErrNotFound when the value is neither an alias, a derived hash nor a stored code.
+func (r *Resolver) LinkID(ctx context.Context, value string) (uint64, error) {
+	a, err := r.aliasStorage.ByValue(ctx, value)
+	if err == nil {
+		return a.LinkID, nil
+	}
+
+	if !errors.Is(err, alias.ErrNotFound) {
+		return 0, fmt.Errorf("find alias: %w", err)
+	}
+
+	if r.randomCodes {
+		return r.codeLinkID(ctx, value)
+	}
+
+	if id, err := r.hashGen.FromHash(value); err == nil {
+		return id, nil
+	}
+
+	id, err := r.linkStorage.IDByCode(ctx, value)
+	if err != nil {
+		return 0, fmt.Errorf("find link id by code: %w", err)
+	}
+
+	return id, nil
+}
+
+// codeLinkID accepts a derived hash only for a link created before the switch to random codes, one without a code.
+func (r *Resolver) codeLinkID(ctx context.Context, value string) (uint64, error) {
+	id, err := r.linkStorage.IDByCode(ctx, value)
+	if err == nil {
+		return id, nil
+	}
+
+	if !errors.Is(err, link.ErrNotFound) {
+		return 0, fmt.Errorf("find link id by code: %w", err)
+	}
+
+	id, err = r.hashGen.FromHash(value)
+	if err != nil {
+		return 0, link.ErrNotFound
+	}
+
+	code, err := r.linkStorage.CodeByID(ctx, id)
+	if err != nil {
+		return 0, fmt.Errorf("find link code: %w", err)
+	}
+
+	if code != "" {
+		return 0, link.ErrNotFound
+	}
+
+	return id, nil
+}
+
+func (r *Resolver) Hash(l *link.Link) (string, error) {
+	if l.Code != "" {
+		return l.Code, nil
+	}
+
+	linkHash, err := r.hashGen.ToHash(l.ID)
+	if err != nil {
+		return "", fmt.Errorf("generate hash from link id: %w", err)
+	}
+
+	return linkHash, nil
+}
+
+// IsTaken reports whether the value already addresses a link or could be issued by the hash generator.
+func (r *Resolver) IsTaken(ctx context.Context, value string) (bool, error) {
+	if _, err := r.hashGen.FromHash(value); err == nil {
+		return true, nil
+	}
+
+	_, err := r.linkStorage.IDByCode(ctx, value)
+	if errors.Is(err, link.ErrNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("find link id by code: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,182 @@
+package linkhash_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/truewebber/link-shortener/app/linkhash"
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+func TestResolverLinkID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr     error
+		name        string
+		value       string
+		wantID      uint64
+		randomCodes bool
+	}{
+		{
+			name:   "Resolve an alias before any hash",
+			value:  "my-alias",
+			wantID: 3,
+		},
+		{
+			name:   "Resolve a derived hash in sqids mode",
+			value:  "derived",
+			wantID: 2,
+		},
+		{
+			name:   "Resolve a stored code in sqids mode when the hash does not decode",
+			value:  "Rnd0mC0d",
+			wantID: 2,
+		},
+		{
+			name:        "Resolve a stored code in random mode",
+			value:       "Rnd0mC0d",
+			wantID:      2,
+			randomCodes: true,
+		},
+		{
+			name:        "Resolve a derived hash in random mode for a link created before the switch",
+			value:       "legacy",
+			wantID:      1,
+			randomCodes: true,
+		},
+		{
+			name:        "Return error in random mode if the derived hash is of a link stored with a code",
+			value:       "derived",
+			wantErr:     link.ErrNotFound,
+			randomCodes: true,
+		},
+		{
+			name:        "Return error in random mode if the value is unknown",
+			value:       "unknown",
+			wantErr:     link.ErrNotFound,
+			randomCodes: true,
+		},
+		{
+			name:    "Return error in sqids mode if the value is unknown",
+			value:   "unknown",
+			wantErr: link.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resolver := linkhash.NewResolver(
+				&fakeLinkStorage{
+					idsByCode: map[string]uint64{"Rnd0mC0d": 2},
+					codesByID: map[uint64]string{1: "", 2: "Rnd0mC0d", 3: ""},
+				},
+				&fakeAliasStorage{linkIDs: map[string]uint64{"my-alias": 3}},
+				&fakeHashGenerator{ids: map[string]uint64{"legacy": 1, "derived": 2}},
+				tt.randomCodes,
+			)
+
+			id, err := resolver.LinkID(context.Background(), tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LinkID() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if id != tt.wantID {
+				t.Errorf("LinkID() = %d, want %d", id, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestResolverHash(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		wantHash string
+		link     link.Link
+	}{
+		{
+			name:     "Return the code stored with a link created with a random code",
+			link:     link.Link{ID: 2, Code: "Rnd0mC0d"},
+			wantHash: "Rnd0mC0d",
+		},
+		{
+			name:     "Return the hash derived from the id of a link without a code",
+			link:     link.Link{ID: 1},
+			wantHash: "legacy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resolver := linkhash.NewResolver(
+				&fakeLinkStorage{},
+				&fakeAliasStorage{},
+				&fakeHashGenerator{ids: map[string]uint64{"legacy": 1}},
+				true,
+			)
+
+			hash, err := resolver.Hash(&tt.link)
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+
+			if hash != tt.wantHash {
+				t.Errorf("Hash() = %q, want %q", hash, tt.wantHash)
+			}
+		})
+	}
+}
+
+func TestResolverIsTaken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		value     string
+		wantTaken bool
+	}{
+		{
+			name:      "Report a value the hash generator could issue as taken",
+			value:     "derived",
+			wantTaken: true,
+		},
+		{
+			name:      "Report a code stored with a link as taken",
+			value:     "Rnd0mC0d",
+			wantTaken: true,
+		},
+		{
+			name:  "Report an unknown value as free",
+			value: "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resolver := linkhash.NewResolver(
+				&fakeLinkStorage{idsByCode: map[string]uint64{"Rnd0mC0d": 2}},
+				&fakeAliasStorage{},
+				&fakeHashGenerator{ids: map[string]uint64{"derived": 1}},
+				true,
+			)
+
+			taken, err := resolver.IsTaken(context.Background(), tt.value)
+			if err != nil {
+				t.Fatalf("IsTaken() error = %v", err)
+			}
+
+			if taken != tt.wantTaken {
+				t.Errorf("IsTaken(%q) = %v, want %v", tt.value, taken, tt.wantTaken)
+			}
+		})
+	}
+}
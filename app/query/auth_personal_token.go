@@ -0,0 +1,78 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/truewebber/gopkg/log"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/pat"
+	userdomain "github.com/truewebber/link-shortener/domain/user"
+)
+
+type AuthPersonalTokenHandler struct {
+	userStorage userdomain.Storage
+	patStorage  pat.Storage
+	logger      log.Logger
+}
+
+func NewAuthPersonalTokenHandler(
+	userStorage userdomain.Storage,
+	patStorage pat.Storage,
+	logger log.Logger,
+) *AuthPersonalTokenHandler {
+	return &AuthPersonalTokenHandler{
+		userStorage: userStorage,
+		patStorage:  patStorage,
+		logger:      logger,
+	}
+}
+
+func (h *AuthPersonalTokenHandler) Handle(ctx context.Context, secret string) (*types.PersonalTokenAuth, error) {
+	token, err := h.patStorage.BySecretHash(ctx, pat.HashSecret(secret))
+	if errors.Is(err, pat.ErrNotFound) {
+		return nil, apperrors.ErrInvalidCredentials
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("find personal token: %w", err)
+	}
+
+	if token.IsExpired() {
+		return nil, apperrors.ErrTokenExpired
+	}
+
+	user, err := h.userStorage.ByID(ctx, token.UserID)
+	if errors.Is(err, userdomain.ErrUserNotFound) {
+		return nil, apperrors.ErrUserNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("find user: %w", err)
+	}
+
+	if user.IsBanned() {
+		return nil, apperrors.ErrUserBanned
+	}
+
+	builtUser, err := types.BuildUserFromDomain(user)
+	if err != nil {
+		return nil, fmt.Errorf("build user from domain: %w", err)
+	}
+
+	if token.NeedsTouch() {
+		// last used time is informational, failing to store it must not deny access
+		if touchErr := h.patStorage.Touch(ctx, token.ID, time.Now()); touchErr != nil {
+			h.logger.Error("failed to touch personal token", "id", token.ID, "error", touchErr)
+		}
+	}
+
+	return &types.PersonalTokenAuth{
+		User:   builtUser,
+		Scopes: types.BuildPersonalTokenFromDomain(token).Scopes,
+	}, nil
+}
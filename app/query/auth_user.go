@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
+
+	"github.com/truewebber/gopkg/log"
 
 	apperrors "github.com/truewebber/link-shortener/app/errors"
 	"github.com/truewebber/link-shortener/app/types"
@@ -14,15 +17,18 @@ import (
 type AuthUserHandler struct {
 	userStorage  userdomain.Storage
 	tokenStorage tokendomain.Storage
+	logger       log.Logger
 }
 
 func NewAuthUserHandler(
 	userStorage userdomain.Storage,
 	tokenStorage tokendomain.Storage,
+	logger log.Logger,
 ) *AuthUserHandler {
 	return &AuthUserHandler{
 		userStorage:  userStorage,
 		tokenStorage: tokenStorage,
+		logger:       logger,
 	}
 }
 
@@ -49,10 +55,21 @@ func (h *AuthUserHandler) Handle(ctx context.Context, accessToken string) (*type
 		return nil, fmt.Errorf("find user: %w", err)
 	}
 
+	if user.IsBanned() {
+		return nil, apperrors.ErrUserBanned
+	}
+
 	builtUser, err := types.BuildUserFromDomain(user)
 	if err != nil {
 		return nil, fmt.Errorf("build user from domain: %w", err)
 	}
 
+	if token.NeedsTouch() {
+		// last used time is informational, failing to store it must not deny access
+		if touchErr := h.tokenStorage.Touch(ctx, token.ID, time.Now()); touchErr != nil {
+			h.logger.Error("failed to touch token", "id", token.ID, "error", touchErr)
+		}
+	}
+
 	return builtUser, nil
 }
@@ -0,0 +1,165 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/linkhash"
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/identity"
+	"github.com/truewebber/link-shortener/domain/link"
+	"github.com/truewebber/link-shortener/domain/stats"
+	userdomain "github.com/truewebber/link-shortener/domain/user"
+)
+
+type LinkExport struct {
+	Stats LinkStats
+	Link  types.Link
+}
+
+// AccountExport leaves the links out, ExportLinks streams them.
+type AccountExport struct {
+	User       *types.User
+	Identities []types.Identity
+}
+
+type ExportAccountHandler struct {
+	userStorage     userdomain.Storage
+	identityStorage identity.Storage
+	linkStorage     link.Storage
+	statsStorage    stats.Storage
+	hashResolver    *linkhash.Resolver
+}
+
+func NewExportAccountHandler(
+	userStorage userdomain.Storage,
+	identityStorage identity.Storage,
+	linkStorage link.Storage,
+	statsStorage stats.Storage,
+	hashResolver *linkhash.Resolver,
+) *ExportAccountHandler {
+	return &ExportAccountHandler{
+		userStorage:     userStorage,
+		identityStorage: identityStorage,
+		linkStorage:     linkStorage,
+		statsStorage:    statsStorage,
+		hashResolver:    hashResolver,
+	}
+}
+
+func (h *ExportAccountHandler) Handle(ctx context.Context, userID uint64) (*AccountExport, error) {
+	u, err := h.userStorage.ByID(ctx, userID)
+	if errors.Is(err, userdomain.ErrUserNotFound) {
+		return nil, apperrors.ErrUserNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("find user: %w", err)
+	}
+
+	user, err := types.BuildUserFromDomain(u)
+	if err != nil {
+		return nil, fmt.Errorf("build user from domain: %w", err)
+	}
+
+	identities, err := h.exportIdentities(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("export identities: %w", err)
+	}
+
+	return &AccountExport{
+		User:       user,
+		Identities: identities,
+	}, nil
+}
+
+func (h *ExportAccountHandler) exportIdentities(ctx context.Context, userID uint64) ([]types.Identity, error) {
+	identities, err := h.identityStorage.ByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get identities by user id: %w", err)
+	}
+
+	exported := make([]types.Identity, 0, len(identities))
+
+	for i := range identities {
+		built, buildErr := types.BuildIdentityFromDomain(&identities[i])
+		if buildErr != nil {
+			return nil, fmt.Errorf("build identity from domain: %w", buildErr)
+		}
+
+		exported = append(exported, *built)
+	}
+
+	return exported, nil
+}
+
+const exportLinksPageSize = 500
+
+// ExportLinks hands the links of the user to write one by one, a single page of them is held at a time.
+func (h *ExportAccountHandler) ExportLinks(
+	ctx context.Context, userID uint64, write func(linkExport *LinkExport) error,
+) error {
+	for afterID := uint64(0); ; {
+		links, err := h.linkStorage.ByUserIDAfter(ctx, userID, afterID, exportLinksPageSize)
+		if err != nil {
+			return fmt.Errorf("get links by user id: %w", err)
+		}
+
+		for i := range links {
+			linkExport, exportErr := h.exportLink(ctx, &links[i])
+			if exportErr != nil {
+				return fmt.Errorf("export link: %w", exportErr)
+			}
+
+			if writeErr := write(linkExport); writeErr != nil {
+				return fmt.Errorf("write link: %w", writeErr)
+			}
+		}
+
+		if len(links) < exportLinksPageSize {
+			return nil
+		}
+
+		afterID = links[len(links)-1].ID
+	}
+}
+
+// exportLink covers every day since the link was created, days without clicks are left out.
+func (h *ExportAccountHandler) exportLink(ctx context.Context, l *link.Link) (*LinkExport, error) {
+	linkHash, err := h.hashResolver.Hash(l)
+	if err != nil {
+		return nil, fmt.Errorf("link hash: %w", err)
+	}
+
+	summary, err := h.statsStorage.Summary(ctx, l.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get stats summary: %w", err)
+	}
+
+	daily, err := h.statsStorage.DailyClicks(ctx, l.ID, truncateToDay(l.CreatedAt), time.Now().UTC().Add(day))
+	if err != nil {
+		return nil, fmt.Errorf("get daily clicks: %w", err)
+	}
+
+	linkStats := LinkStats{
+		LastVisitAt: summary.LastVisitAt,
+		Daily:       make([]DailyClicks, 0, len(daily)),
+		TotalClicks: summary.TotalClicks,
+	}
+
+	for _, d := range daily {
+		linkStats.Daily = append(linkStats.Daily, DailyClicks{
+			Day:            d.Day,
+			Clicks:         d.Clicks,
+			UniqueVisitors: d.UniqueVisitors,
+		})
+	}
+
+	return &LinkExport{
+		Stats: linkStats,
+		Link:  *types.BuildLinkFromDomain(l, linkHash),
+	}, nil
+}
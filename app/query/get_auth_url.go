@@ -2,9 +2,9 @@ package query
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
+	apperrors "github.com/truewebber/link-shortener/app/errors"
 	"github.com/truewebber/link-shortener/app/types"
 	userdomain "github.com/truewebber/link-shortener/domain/user"
 )
@@ -24,14 +24,14 @@ type AuthURLResponse struct {
 }
 
 func (h *GetAuthURLHandler) Handle(
-	_ context.Context, provider types.Provider, state string,
+	ctx context.Context, provider types.Provider, state, codeVerifier string,
 ) (AuthURLResponse, error) {
 	oauthProvider, err := h.getOAuthProvider(provider)
 	if err != nil {
 		return AuthURLResponse{}, fmt.Errorf("get OAuth provider: %w", err)
 	}
 
-	url, err := oauthProvider.GetAuthURL(state)
+	url, err := oauthProvider.GetAuthURL(ctx, state, codeVerifier)
 	if err != nil {
 		return AuthURLResponse{}, fmt.Errorf("get auth URL from oauthProvider: %w", err)
 	}
@@ -41,12 +41,10 @@ func (h *GetAuthURLHandler) Handle(
 	}, nil
 }
 
-var errUnknownProvider = errors.New("unknown provider")
-
 func (h *GetAuthURLHandler) getOAuthProvider(provider types.Provider) (userdomain.OAuthProvider, error) {
 	oauthProvider, ok := h.oauthProviders[provider]
 	if !ok {
-		return nil, errUnknownProvider
+		return nil, apperrors.ErrProviderNotFound
 	}
 
 	return oauthProvider, nil
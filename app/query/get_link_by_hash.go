@@ -7,7 +7,7 @@ import (
 
 	"github.com/truewebber/gopkg/log"
 
-	"github.com/truewebber/link-shortener/domain/hash"
+	"github.com/truewebber/link-shortener/app/linkhash"
 	"github.com/truewebber/link-shortener/domain/link"
 )
 
@@ -16,29 +16,29 @@ type GetLinkByHashParams struct {
 }
 
 type GetLinkByHashHandler struct {
-	linkStorage link.Storage
-	hashGen     hash.Generator
-	logger      log.Logger
+	linkStorage  link.Storage
+	hashResolver *linkhash.Resolver
+	logger       log.Logger
 }
 
 func NewGetLinkByHashHandler(
 	linkStorage link.Storage,
-	hashGen hash.Generator,
+	hashResolver *linkhash.Resolver,
 	logger log.Logger,
 ) *GetLinkByHashHandler {
 	return &GetLinkByHashHandler{
-		linkStorage: linkStorage,
-		hashGen:     hashGen,
-		logger:      logger,
+		linkStorage:  linkStorage,
+		hashResolver: hashResolver,
+		logger:       logger,
 	}
 }
 
 var ErrNotFound = errors.New("not found")
 
 func (h *GetLinkByHashHandler) Handle(ctx context.Context, params GetLinkByHashParams) (*link.Link, error) {
-	id, err := h.hashGen.FromHash(params.Hash)
+	id, err := h.hashResolver.LinkID(ctx, params.Hash)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode hash: %w", err)
+		return nil, fmt.Errorf("failed to resolve link id: %w", err)
 	}
 
 	l, err := h.linkStorage.ByID(ctx, id)
@@ -0,0 +1,130 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/linkhash"
+	"github.com/truewebber/link-shortener/domain/link"
+	"github.com/truewebber/link-shortener/domain/stats"
+)
+
+type GetLinkStatsParams struct {
+	From   time.Time
+	To     time.Time
+	Hash   string
+	UserID uint64
+}
+
+type DailyClicks struct {
+	Day            time.Time
+	Clicks         uint64
+	UniqueVisitors uint64
+}
+
+// LinkStats has unique visitors per day only, a visitor is not recognizable across days.
+type LinkStats struct {
+	LastVisitAt *time.Time
+	Daily       []DailyClicks
+	TotalClicks uint64
+}
+
+type GetLinkStatsHandler struct {
+	linkStorage  link.Storage
+	statsStorage stats.Storage
+	hashResolver *linkhash.Resolver
+}
+
+func NewGetLinkStatsHandler(
+	linkStorage link.Storage,
+	statsStorage stats.Storage,
+	hashResolver *linkhash.Resolver,
+) *GetLinkStatsHandler {
+	return &GetLinkStatsHandler{
+		linkStorage:  linkStorage,
+		statsStorage: statsStorage,
+		hashResolver: hashResolver,
+	}
+}
+
+const (
+	day           = 24 * time.Hour
+	maxStatsDays  = 366
+	maxStatsRange = maxStatsDays * day
+)
+
+func (h *GetLinkStatsHandler) Handle(ctx context.Context, params GetLinkStatsParams) (*LinkStats, error) {
+	from := truncateToDay(params.From)
+	to := truncateToDay(params.To).Add(day)
+
+	if !from.Before(to) || to.Sub(from) > maxStatsRange {
+		return nil, apperrors.ErrInvalidDateRange
+	}
+
+	id, err := h.hashResolver.LinkID(ctx, params.Hash)
+	if errors.Is(err, link.ErrNotFound) {
+		return nil, apperrors.ErrLinkNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("resolve link id: %w", err)
+	}
+
+	l, err := h.linkStorage.ByID(ctx, id)
+	if errors.Is(err, link.ErrNotFound) {
+		return nil, apperrors.ErrLinkNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("find link: %w", err)
+	}
+
+	if !l.IsOwnedBy(params.UserID) {
+		return nil, apperrors.ErrLinkNotFound
+	}
+
+	summary, err := h.statsStorage.Summary(ctx, l.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get stats summary: %w", err)
+	}
+
+	daily, err := h.statsStorage.DailyClicks(ctx, l.ID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("get daily clicks: %w", err)
+	}
+
+	return &LinkStats{
+		LastVisitAt: summary.LastVisitAt,
+		Daily:       fillDailyGaps(daily, from, to),
+		TotalClicks: summary.TotalClicks,
+	}, nil
+}
+
+func truncateToDay(t time.Time) time.Time {
+	year, month, dayOfMonth := t.UTC().Date()
+
+	return time.Date(year, month, dayOfMonth, 0, 0, 0, 0, time.UTC)
+}
+
+func fillDailyGaps(daily []stats.DailyClicks, from, to time.Time) []DailyClicks {
+	dailyByDay := make(map[time.Time]stats.DailyClicks, len(daily))
+
+	for _, d := range daily {
+		dailyByDay[truncateToDay(d.Day)] = d
+	}
+
+	filled := make([]DailyClicks, 0, int(to.Sub(from)/day))
+
+	for current := from; current.Before(to); current = current.Add(day) {
+		filled = append(filled, DailyClicks{
+			Day:            current,
+			Clicks:         dailyByDay[current].Clicks,
+			UniqueVisitors: dailyByDay[current].UniqueVisitors,
+		})
+	}
+
+	return filled
+}
@@ -0,0 +1,34 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/captcha"
+)
+
+type IssueCaptchaChallengeHandler struct {
+	issuer captcha.ChallengeIssuer
+}
+
+// NewIssueCaptchaChallengeHandler takes a nil issuer when the captcha in use does not need server challenges.
+func NewIssueCaptchaChallengeHandler(issuer captcha.ChallengeIssuer) *IssueCaptchaChallengeHandler {
+	return &IssueCaptchaChallengeHandler{
+		issuer: issuer,
+	}
+}
+
+func (h *IssueCaptchaChallengeHandler) Handle(ctx context.Context) (*types.CaptchaChallenge, error) {
+	if h.issuer == nil {
+		return nil, apperrors.ErrCaptchaChallengeNotSupported
+	}
+
+	challenge, err := h.issuer.Issue(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("issue challenge: %w", err)
+	}
+
+	return types.BuildCaptchaChallengeFromDomain(challenge), nil
+}
@@ -0,0 +1,39 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/identity"
+)
+
+type ListIdentitiesHandler struct {
+	identityStorage identity.Storage
+}
+
+func NewListIdentitiesHandler(identityStorage identity.Storage) *ListIdentitiesHandler {
+	return &ListIdentitiesHandler{
+		identityStorage: identityStorage,
+	}
+}
+
+func (h *ListIdentitiesHandler) Handle(ctx context.Context, userID uint64) ([]types.Identity, error) {
+	identities, err := h.identityStorage.ByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get identities by user id: %w", err)
+	}
+
+	result := make([]types.Identity, 0, len(identities))
+
+	for i := range identities {
+		built, buildErr := types.BuildIdentityFromDomain(&identities[i])
+		if buildErr != nil {
+			return nil, fmt.Errorf("build identity from domain: %w", buildErr)
+		}
+
+		result = append(result, *built)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/moderation"
+)
+
+type ListModerationActionsParams struct {
+	Limit  uint32
+	Offset uint32
+}
+
+type ListModerationActionsHandler struct {
+	moderationStorage moderation.Storage
+}
+
+func NewListModerationActionsHandler(moderationStorage moderation.Storage) *ListModerationActionsHandler {
+	return &ListModerationActionsHandler{
+		moderationStorage: moderationStorage,
+	}
+}
+
+func (h *ListModerationActionsHandler) Handle(
+	ctx context.Context, params ListModerationActionsParams,
+) (*types.ModerationActionList, error) {
+	list, err := h.moderationStorage.List(ctx, params.Limit, params.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("list moderation actions: %w", err)
+	}
+
+	actions := make([]types.ModerationAction, 0, len(list.Actions))
+
+	for i := range list.Actions {
+		actions = append(actions, *types.BuildModerationActionFromDomain(&list.Actions[i]))
+	}
+
+	return &types.ModerationActionList{
+		Actions: actions,
+		Count:   list.Count,
+	}, nil
+}
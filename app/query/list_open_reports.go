@@ -0,0 +1,42 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/report"
+)
+
+type ListOpenReportsParams struct {
+	Limit  uint32
+	Offset uint32
+}
+
+type ListOpenReportsHandler struct {
+	reportStorage report.Storage
+}
+
+func NewListOpenReportsHandler(reportStorage report.Storage) *ListOpenReportsHandler {
+	return &ListOpenReportsHandler{
+		reportStorage: reportStorage,
+	}
+}
+
+func (h *ListOpenReportsHandler) Handle(ctx context.Context, params ListOpenReportsParams) (*types.ReportQueue, error) {
+	queue, err := h.reportStorage.Open(ctx, params.Limit, params.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("list open reports: %w", err)
+	}
+
+	links := make([]types.LinkReports, 0, len(queue.Links))
+
+	for i := range queue.Links {
+		links = append(links, *types.BuildLinkReportsFromDomain(&queue.Links[i]))
+	}
+
+	return &types.ReportQueue{
+		Links: links,
+		Count: queue.Count,
+	}, nil
+}
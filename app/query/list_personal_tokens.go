@@ -0,0 +1,33 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/pat"
+)
+
+type ListPersonalTokensHandler struct {
+	patStorage pat.Storage
+}
+
+func NewListPersonalTokensHandler(patStorage pat.Storage) *ListPersonalTokensHandler {
+	return &ListPersonalTokensHandler{
+		patStorage: patStorage,
+	}
+}
+
+func (h *ListPersonalTokensHandler) Handle(ctx context.Context, userID uint64) ([]types.PersonalToken, error) {
+	tokens, err := h.patStorage.ByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get personal tokens by user id: %w", err)
+	}
+
+	result := make([]types.PersonalToken, 0, len(tokens))
+	for i := range tokens {
+		result = append(result, *types.BuildPersonalTokenFromDomain(&tokens[i]))
+	}
+
+	return result, nil
+}
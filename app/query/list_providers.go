@@ -0,0 +1,30 @@
+package query
+
+import (
+	"context"
+	"slices"
+
+	"github.com/truewebber/link-shortener/app/types"
+	userdomain "github.com/truewebber/link-shortener/domain/user"
+)
+
+type ListProvidersHandler struct {
+	providers []types.Provider
+}
+
+func NewListProvidersHandler(oauthProviders map[types.Provider]userdomain.OAuthProvider) *ListProvidersHandler {
+	providers := make([]types.Provider, 0, len(oauthProviders))
+	for provider := range oauthProviders {
+		providers = append(providers, provider)
+	}
+
+	slices.Sort(providers)
+
+	return &ListProvidersHandler{
+		providers: providers,
+	}
+}
+
+func (h *ListProvidersHandler) Handle(_ context.Context) []types.Provider {
+	return slices.Clone(h.providers)
+}
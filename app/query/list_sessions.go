@@ -0,0 +1,49 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/types"
+	tokendomain "github.com/truewebber/link-shortener/domain/token"
+)
+
+type ListSessionsParams struct {
+	AccessToken string
+	UserID      uint64
+}
+
+type ListSessionsHandler struct {
+	tokenStorage tokendomain.Storage
+}
+
+func NewListSessionsHandler(tokenStorage tokendomain.Storage) *ListSessionsHandler {
+	return &ListSessionsHandler{
+		tokenStorage: tokenStorage,
+	}
+}
+
+func (h *ListSessionsHandler) Handle(ctx context.Context, params ListSessionsParams) ([]types.Session, error) {
+	current, err := h.tokenStorage.ByAccessToken(ctx, params.AccessToken)
+	if errors.Is(err, tokendomain.ErrTokenNotFound) {
+		return nil, apperrors.ErrInvalidCredentials
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("find current token: %w", err)
+	}
+
+	tokens, err := h.tokenStorage.ActiveByUserID(ctx, params.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("get active tokens by user id: %w", err)
+	}
+
+	result := make([]types.Session, 0, len(tokens))
+	for i := range tokens {
+		result = append(result, *types.BuildSessionFromDomain(&tokens[i], current.ID))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/truewebber/link-shortener/app/linkhash"
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+type ListUserLinksParams struct {
+	UserID uint64
+	Limit  uint32
+	Offset uint32
+}
+
+type ListUserLinksHandler struct {
+	linkStorage  link.Storage
+	hashResolver *linkhash.Resolver
+}
+
+func NewListUserLinksHandler(
+	linkStorage link.Storage,
+	hashResolver *linkhash.Resolver,
+) *ListUserLinksHandler {
+	return &ListUserLinksHandler{
+		linkStorage:  linkStorage,
+		hashResolver: hashResolver,
+	}
+}
+
+func (h *ListUserLinksHandler) Handle(ctx context.Context, params ListUserLinksParams) (*types.LinkList, error) {
+	list, err := h.linkStorage.ByUserID(ctx, params.UserID, params.Limit, params.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("get links by user id: %w", err)
+	}
+
+	links := make([]types.Link, 0, len(list.Links))
+
+	for i := range list.Links {
+		linkHash, hashErr := h.hashResolver.Hash(&list.Links[i])
+		if hashErr != nil {
+			return nil, fmt.Errorf("link hash: %w", hashErr)
+		}
+
+		links = append(links, *types.BuildLinkFromDomain(&list.Links[i], linkHash))
+	}
+
+	return &types.LinkList{
+		Links: links,
+		Count: list.Count,
+	}, nil
+}
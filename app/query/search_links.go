@@ -0,0 +1,62 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/truewebber/link-shortener/app/linkhash"
+	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+type SearchLinksParams struct {
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Domain      string
+	UserID      uint64
+	Limit       uint32
+	Offset      uint32
+}
+
+type SearchLinksHandler struct {
+	linkStorage  link.Storage
+	hashResolver *linkhash.Resolver
+}
+
+func NewSearchLinksHandler(linkStorage link.Storage, hashResolver *linkhash.Resolver) *SearchLinksHandler {
+	return &SearchLinksHandler{
+		linkStorage:  linkStorage,
+		hashResolver: hashResolver,
+	}
+}
+
+func (h *SearchLinksHandler) Handle(ctx context.Context, params SearchLinksParams) (*types.LinkList, error) {
+	filter := link.SearchFilter{
+		CreatedFrom: params.CreatedFrom,
+		CreatedTo:   params.CreatedTo,
+		Domain:      params.Domain,
+		UserID:      params.UserID,
+	}
+
+	list, err := h.linkStorage.Search(ctx, filter, params.Limit, params.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("search links: %w", err)
+	}
+
+	links := make([]types.Link, 0, len(list.Links))
+
+	for i := range list.Links {
+		linkHash, hashErr := h.hashResolver.Hash(&list.Links[i])
+		if hashErr != nil {
+			return nil, fmt.Errorf("link hash: %w", hashErr)
+		}
+
+		links = append(links, *types.BuildLinkFromDomain(&list.Links[i], linkHash))
+	}
+
+	return &types.LinkList{
+		Links: links,
+		Count: list.Count,
+	}, nil
+}
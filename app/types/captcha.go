@@ -0,0 +1,21 @@
+package types
+
+import "github.com/truewebber/link-shortener/domain/captcha"
+
+type CaptchaChallenge struct {
+	Algorithm string
+	Salt      string
+	Challenge string
+	Signature string
+	MaxNumber uint64
+}
+
+func BuildCaptchaChallengeFromDomain(challenge *captcha.Challenge) *CaptchaChallenge {
+	return &CaptchaChallenge{
+		Algorithm: challenge.Algorithm,
+		Salt:      challenge.Salt,
+		Challenge: challenge.Challenge,
+		Signature: challenge.Signature,
+		MaxNumber: challenge.MaxNumber,
+	}
+}
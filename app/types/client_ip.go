@@ -0,0 +1,22 @@
+package types
+
+import "net/netip"
+
+// clientIPv6PrefixBits is the network a single IPv6 subscriber is usually handed.
+const clientIPv6PrefixBits = 64
+
+// ClientNetwork keys an IPv6 client by its /64, so hopping addresses within it
+// counts as one client. IPv4 addresses and anything unparsable are kept as is.
+func ClientNetwork(clientIP string) string {
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return clientIP
+	}
+
+	addr = addr.Unmap()
+	if addr.Is6() {
+		return netip.PrefixFrom(addr, clientIPv6PrefixBits).Masked().String()
+	}
+
+	return addr.String()
+}
@@ -0,0 +1,48 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/truewebber/link-shortener/app/types"
+)
+
+func TestClientNetwork(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		clientIP string
+		want     string
+	}{
+		{
+			name:     "Keep an IPv4 address as is",
+			clientIP: "198.51.100.7",
+			want:     "198.51.100.7",
+		},
+		{
+			name:     "Unmap an IPv4-mapped IPv6 address",
+			clientIP: "::ffff:198.51.100.7",
+			want:     "198.51.100.7",
+		},
+		{
+			name:     "Mask an IPv6 address to its /64",
+			clientIP: "2001:db8:1:2:aaaa:bbbb:cccc:dddd",
+			want:     "2001:db8:1:2::/64",
+		},
+		{
+			name:     "Keep an unparsable address as is",
+			clientIP: "not-an-ip",
+			want:     "not-an-ip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := types.ClientNetwork(tt.clientIP); got != tt.want {
+				t.Errorf("ClientNetwork(%q) = %q, want %q", tt.clientIP, got, tt.want)
+			}
+		})
+	}
+}
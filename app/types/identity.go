@@ -0,0 +1,33 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/truewebber/link-shortener/domain/identity"
+)
+
+type Identity struct {
+	CreatedAt time.Time
+	Email     string
+	Name      string
+	AvatarURL string
+	ID        uint64
+	Provider  Provider
+}
+
+func BuildIdentityFromDomain(i *identity.Identity) (*Identity, error) {
+	provider, err := BuildProviderFromDomain(i.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("build identity provider: %w", err)
+	}
+
+	return &Identity{
+		CreatedAt: i.CreatedAt,
+		Email:     i.Email,
+		Name:      i.Name,
+		AvatarURL: i.AvatarURL,
+		ID:        i.ID,
+		Provider:  provider,
+	}, nil
+}
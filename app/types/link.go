@@ -0,0 +1,38 @@
+package types
+
+import (
+	"time"
+
+	linkdomain "github.com/truewebber/link-shortener/domain/link"
+)
+
+type Link struct {
+	CreatedAt     time.Time
+	ExpiresAt     *time.Time
+	BlockedAt     *time.Time
+	Hash          string
+	RedirectURL   string
+	BlockedReason string
+	ID            uint64
+	UserID        uint64
+	ExpiresType   linkdomain.ExpiresType
+}
+
+type LinkList struct {
+	Links []Link
+	Count uint32
+}
+
+func BuildLinkFromDomain(link *linkdomain.Link, hash string) *Link {
+	return &Link{
+		CreatedAt:     link.CreatedAt,
+		ExpiresAt:     link.ExpiresAt,
+		BlockedAt:     link.BlockedAt,
+		Hash:          hash,
+		RedirectURL:   link.RedirectURL,
+		BlockedReason: link.BlockedReason,
+		ID:            link.ID,
+		UserID:        link.UserID,
+		ExpiresType:   link.ExpiresType,
+	}
+}
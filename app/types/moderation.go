@@ -0,0 +1,32 @@
+package types
+
+import (
+	"time"
+
+	"github.com/truewebber/link-shortener/domain/moderation"
+)
+
+type ModerationAction struct {
+	CreatedAt   time.Time
+	Kind        string
+	Reason      string
+	ID          uint64
+	ModeratorID uint64
+	TargetID    uint64
+}
+
+type ModerationActionList struct {
+	Actions []ModerationAction
+	Count   uint32
+}
+
+func BuildModerationActionFromDomain(action *moderation.Action) *ModerationAction {
+	return &ModerationAction{
+		CreatedAt:   action.CreatedAt,
+		Kind:        string(action.Kind),
+		Reason:      action.Reason,
+		ID:          action.ID,
+		ModeratorID: action.ModeratorID,
+		TargetID:    action.TargetID,
+	}
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"time"
+
+	"github.com/truewebber/link-shortener/domain/pat"
+)
+
+const (
+	ScopeLinksRead  = string(pat.ScopeLinksRead)
+	ScopeLinksWrite = string(pat.ScopeLinksWrite)
+)
+
+type PersonalToken struct {
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	Name       string
+	// Secret is only known right after the token is created.
+	Secret string
+	Scopes []string
+	ID     uint64
+}
+
+type PersonalTokenAuth struct {
+	User   *User
+	Scopes []string
+}
+
+func BuildPersonalTokenFromDomain(token *pat.Token) *PersonalToken {
+	scopes := make([]string, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	return &PersonalToken{
+		CreatedAt:  token.CreatedAt,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		Name:       token.Name,
+		Scopes:     scopes,
+		ID:         token.ID,
+	}
+}
+
+func IsPersonalTokenSecret(value string) bool {
+	return pat.IsSecret(value)
+}
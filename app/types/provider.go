@@ -14,11 +14,29 @@ const (
 	ProviderGoogle
 	ProviderApple
 	ProviderGithub
+	// ProviderOIDC is the OpenID Connect provider of slot 0, see userdomain.OIDCProvider.
+	ProviderOIDC
 )
 
+func OIDCProvider(slot uint8) Provider {
+	return ProviderOIDC + Provider(slot)
+}
+
+func (p Provider) OIDCSlot() (uint8, bool) {
+	if p < ProviderOIDC || p >= ProviderOIDC+userdomain.MaxOIDCProviders {
+		return 0, false
+	}
+
+	return uint8(p - ProviderOIDC), true
+}
+
 var errUnknownProvider = errors.New("unknown provider")
 
 func BuildProviderFromDomain(provider userdomain.Provider) (Provider, error) {
+	if slot, ok := provider.OIDCSlot(); ok {
+		return OIDCProvider(slot), nil
+	}
+
 	switch provider {
 	case userdomain.ProviderAnonymous:
 		return ProviderAnonymous, nil
@@ -34,6 +52,10 @@ func BuildProviderFromDomain(provider userdomain.Provider) (Provider, error) {
 }
 
 func BuildProviderToDomain(provider Provider) (userdomain.Provider, error) {
+	if slot, ok := provider.OIDCSlot(); ok {
+		return userdomain.OIDCProvider(slot), nil
+	}
+
 	switch provider {
 	case ProviderAnonymous:
 		return userdomain.ProviderAnonymous, nil
@@ -0,0 +1,11 @@
+package types
+
+// RateLimitClass groups routes that share one limit.
+type RateLimitClass string
+
+const (
+	RateLimitClassRedirect  RateLimitClass = "redirect"
+	RateLimitClassAnonymous RateLimitClass = "anonymous"
+	RateLimitClassAPI       RateLimitClass = "api"
+	RateLimitClassAuth      RateLimitClass = "auth"
+)
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"time"
+
+	"github.com/truewebber/link-shortener/domain/report"
+)
+
+type Report struct {
+	CreatedAt time.Time
+	Reason    string
+	Contact   string
+	ID        uint64
+}
+
+type LinkReports struct {
+	FirstReportedAt time.Time
+	LastReportedAt  time.Time
+	BlockedAt       *time.Time
+	RedirectURL     string
+	Latest          []Report
+	LinkID          uint64
+	Reporters       uint32
+}
+
+type ReportQueue struct {
+	Links []LinkReports
+	Count uint32
+}
+
+func BuildLinkReportsFromDomain(linkReports *report.LinkReports) *LinkReports {
+	latest := make([]Report, 0, len(linkReports.Latest))
+
+	for _, r := range linkReports.Latest {
+		latest = append(latest, Report{
+			CreatedAt: r.CreatedAt,
+			Reason:    r.Reason,
+			Contact:   r.Contact,
+			ID:        r.ID,
+		})
+	}
+
+	return &LinkReports{
+		FirstReportedAt: linkReports.FirstReportedAt,
+		LastReportedAt:  linkReports.LastReportedAt,
+		BlockedAt:       linkReports.BlockedAt,
+		RedirectURL:     linkReports.RedirectURL,
+		Latest:          latest,
+		LinkID:          linkReports.LinkID,
+		Reporters:       linkReports.Reporters,
+	}
+}
@@ -0,0 +1,27 @@
+package types
+
+import (
+	"time"
+
+	tokendomain "github.com/truewebber/link-shortener/domain/token"
+)
+
+type Session struct {
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	UserAgent  string
+	IP         string
+	ID         uint64
+	Current    bool
+}
+
+func BuildSessionFromDomain(token *tokendomain.Token, currentID uint64) *Session {
+	return &Session{
+		CreatedAt:  token.CreatedAt,
+		LastUsedAt: token.LastUsedAt,
+		UserAgent:  token.Device.UserAgent,
+		IP:         token.Device.IP,
+		ID:         token.ID,
+		Current:    token.ID == currentID,
+	}
+}
@@ -13,6 +13,7 @@ type User struct {
 	AvatarURL  string
 	ID         uint64
 	Provider   Provider
+	IsAdmin    bool
 }
 
 func BuildUserFromDomain(user *userdomain.User) (*User, error) {
@@ -28,6 +29,7 @@ func BuildUserFromDomain(user *userdomain.User) (*User, error) {
 		Provider:   provider,
 		ProviderID: user.ProviderID,
 		AvatarURL:  user.AvatarURL,
+		IsAdmin:    user.IsAdmin(),
 	}, nil
 }
 
@@ -37,6 +39,11 @@ func BuildUserToDomain(user *User) (*userdomain.User, error) {
 		return nil, fmt.Errorf("build user provider: %w", err)
 	}
 
+	role := userdomain.RoleUser
+	if user.IsAdmin {
+		role = userdomain.RoleAdmin
+	}
+
 	return &userdomain.User{
 		Name:       user.Name,
 		Email:      user.Email,
@@ -44,6 +51,7 @@ func BuildUserToDomain(user *User) (*userdomain.User, error) {
 		Provider:   provider,
 		ProviderID: user.ProviderID,
 		AvatarURL:  user.AvatarURL,
+		Role:       role,
 	}, nil
 }
 
@@ -1,28 +1,87 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Netflix/go-env"
+
+	"github.com/truewebber/link-shortener/service"
 )
 
 type config struct {
-	GoogleClientID           string  `env:"GOOGLE_CLIENT_ID,required=true"`
-	GithubClientID           string  `env:"GITHUB_CLIENT_ID,required=true"`
-	BaseHost                 string  `env:"BASE_HOST,required=true"`
-	PostgresConnectionString string  `env:"POSTGRES_CONNECTION_STRING,required=true"`
-	GoogleCaptchaSecretKey   string  `env:"GOOGLE_CAPTCHA_SECRET_KEY,required=true"`
-	GithubClientSecret       string  `env:"GITHUB_CLIENT_SECRET,required=true"`
-	AppleClientID            string  `env:"APPLE_CLIENT_ID,required=true"`
-	AppHostPort              string  `env:"APP_HOST_PORT,required=true"`
-	MetricsHostPort          string  `env:"METRICS_HOST_PORT,required=true"`
-	ApplePrivateKey          string  `env:"APPLE_PRIVATE_KEY,required=true"`
-	AppleKeyID               string  `env:"APPLE_KEY_ID,required=true"`
-	AppleTeamID              string  `env:"APPLE_TEAM_ID,required=true"`
-	GoogleClientSecret       string  `env:"GOOGLE_CLIENT_SECRET,required=true"`
-	GoogleCaptchaThreshold   float32 `env:"GOOGLE_CAPTCHA_THRESHOLD,required=true"`
+	// rateLimit, trustedProxies and oidc are parsed from the RATE_LIMIT_*, TRUSTED_PROXIES and OIDC_* values
+	// by loadConfig.
+	rateLimit                service.RateLimit
+	trustedProxies           []netip.Prefix
+	oidc                     []oidcConfig
+	GoogleClientID           string        `env:"GOOGLE_CLIENT_ID"`
+	GithubClientID           string        `env:"GITHUB_CLIENT_ID"`
+	BaseHost                 string        `env:"BASE_HOST,required=true"`
+	PostgresConnectionString string        `env:"POSTGRES_CONNECTION_STRING,required=true"`
+	GoogleCaptchaSecretKey   string        `env:"GOOGLE_CAPTCHA_SECRET_KEY"`
+	GithubClientSecret       string        `env:"GITHUB_CLIENT_SECRET"`
+	AppleClientID            string        `env:"APPLE_CLIENT_ID"`
+	AppHostPort              string        `env:"APP_HOST_PORT,required=true"`
+	MetricsHostPort          string        `env:"METRICS_HOST_PORT,required=true"`
+	ApplePrivateKey          string        `env:"APPLE_PRIVATE_KEY"`
+	AppleKeyID               string        `env:"APPLE_KEY_ID"`
+	AppleTeamID              string        `env:"APPLE_TEAM_ID"`
+	GoogleClientSecret       string        `env:"GOOGLE_CLIENT_SECRET"`
+	TokenHashSecret          string        `env:"TOKEN_HASH_SECRET,required=true"`
+	ClientHashSecret         string        `env:"CLIENT_HASH_SECRET,required=true"`
+	HashAlphabet             string        `env:"HASH_ALPHABET"`
+	HashStrategy             string        `env:"HASH_STRATEGY,default=sqids"`
+	DeletedUserLinks         string        `env:"DELETED_USER_LINKS,default=delete"`
+	CaptchaProvider          string        `env:"CAPTCHA_PROVIDER,default=recaptcha"`
+	PowCaptchaSecret         string        `env:"POW_CAPTCHA_SECRET"`
+	HCaptchaSecretKey        string        `env:"HCAPTCHA_SECRET_KEY"`
+	TurnstileSecretKey       string        `env:"TURNSTILE_SECRET_KEY"`
+	CaptchaVerifyURL         string        `env:"CAPTCHA_VERIFY_URL"`
+	RateLimitStore           string        `env:"RATE_LIMIT_STORE,default=memory"`
+	RateLimitRedirect        string        `env:"RATE_LIMIT_REDIRECT,default=600/1m"`
+	RateLimitAnonymous       string        `env:"RATE_LIMIT_ANONYMOUS,default=10/1m"`
+	RateLimitAPI             string        `env:"RATE_LIMIT_API,default=300/1m"`
+	RateLimitAuth            string        `env:"RATE_LIMIT_AUTH,default=30/1m"`
+	SafetyDomainsFile        string        `env:"SAFETY_DOMAINS_FILE"`
+	SafetyURLPrefixFile      string        `env:"SAFETY_URL_PREFIX_FILE"`
+	SafetyPatternsFile       string        `env:"SAFETY_PATTERNS_FILE"`
+	HashBlocklist            []string      `env:"HASH_BLOCKLIST,separator= "`
+	TrustedProxies           []string      `env:"TRUSTED_PROXIES,separator= "`
+	TurnstileAllowedActions  []string      `env:"TURNSTILE_ALLOWED_ACTIONS,separator= "`
+	HashLegacyMaxID          uint64        `env:"HASH_LEGACY_MAX_ID,default=0"`
+	PowCaptchaMinDifficulty  uint64        `env:"POW_CAPTCHA_MIN_DIFFICULTY,default=50000"`
+	PowCaptchaMaxDifficulty  uint64        `env:"POW_CAPTCHA_MAX_DIFFICULTY,default=1000000"`
+	PowCaptchaLoadStep       uint64        `env:"POW_CAPTCHA_LOAD_STEP,default=100"`
+	HashRandomLength         int           `env:"HASH_RANDOM_LENGTH,default=8"`
+	LinkCacheSize            int           `env:"LINK_CACHE_SIZE,default=10000"`
+	LinkCacheTTL             time.Duration `env:"LINK_CACHE_TTL,default=1m"`
+	LinkCacheNegativeTTL     time.Duration `env:"LINK_CACHE_NEGATIVE_TTL,default=10s"`
+	PowCaptchaTTL            time.Duration `env:"POW_CAPTCHA_TTL,default=5m"`
+	SafetyReloadInterval     time.Duration `env:"SAFETY_RELOAD_INTERVAL,default=1m"`
+	GoogleCaptchaThreshold   float32       `env:"GOOGLE_CAPTCHA_THRESHOLD,default=0.5"`
+	ReportDisableThreshold   uint32        `env:"REPORT_DISABLE_THRESHOLD,default=5"`
+	HashMinLength            uint8         `env:"HASH_MIN_LENGTH,default=6"`
 }
 
+const minTokenHashSecretLength = 32
+
+var (
+	errWeakSecret   = errors.New("weak secret")
+	errInvalidOAuth = errors.New("invalid oauth config")
+	errInvalidOIDC  = errors.New("invalid oidc config")
+
+	errInvalidCaptcha = errors.New("invalid captcha config")
+
+	errInvalidRateLimit = errors.New("invalid rate limit config")
+)
+
 func mustLoadConfig() *config {
 	cfg, err := loadConfig()
 	if err != nil {
@@ -35,9 +94,279 @@ func mustLoadConfig() *config {
 func loadConfig() (*config, error) {
 	c := &config{}
 
-	if _, err := env.UnmarshalFromEnviron(c); err != nil {
+	environ, err := env.UnmarshalFromEnviron(c)
+	if err != nil {
 		return nil, fmt.Errorf("config unmarshal: %w", err)
 	}
 
+	if len(c.TokenHashSecret) < minTokenHashSecretLength {
+		return nil, fmt.Errorf("%w: TOKEN_HASH_SECRET must be at least %d bytes",
+			errWeakSecret, minTokenHashSecretLength)
+	}
+
+	if len(c.ClientHashSecret) < minTokenHashSecretLength {
+		return nil, fmt.Errorf("%w: CLIENT_HASH_SECRET must be at least %d bytes",
+			errWeakSecret, minTokenHashSecretLength)
+	}
+
+	if err := validateOAuth(c); err != nil {
+		return nil, fmt.Errorf("validate oauth: %w", err)
+	}
+
+	if c.oidc, err = parseOIDC(environ); err != nil {
+		return nil, fmt.Errorf("parse oidc: %w", err)
+	}
+
+	if err := validateCaptcha(c); err != nil {
+		return nil, fmt.Errorf("validate captcha: %w", err)
+	}
+
+	if err := parseRateLimit(c); err != nil {
+		return nil, fmt.Errorf("parse rate limit: %w", err)
+	}
+
 	return c, nil
 }
+
+// oidcProviderNames returns the route names of the configured OpenID Connect providers by slot.
+func (c *config) oidcProviderNames() map[uint8]string {
+	names := make(map[uint8]string, len(c.oidc))
+
+	for i := range c.oidc {
+		names[c.oidc[i].slot] = c.oidc[i].Name
+	}
+
+	return names
+}
+
+// validateOAuth lets every provider be left out, a provider with only some credentials is a mistake.
+func validateOAuth(c *config) error {
+	credentials := []struct {
+		provider string
+		values   []string
+	}{
+		{provider: "google", values: []string{c.GoogleClientID, c.GoogleClientSecret}},
+		{provider: "github", values: []string{c.GithubClientID, c.GithubClientSecret}},
+		{provider: "apple", values: []string{c.AppleClientID, c.ApplePrivateKey, c.AppleKeyID, c.AppleTeamID}},
+	}
+
+	for _, credential := range credentials {
+		set := 0
+
+		for _, value := range credential.values {
+			if value != "" {
+				set++
+			}
+		}
+
+		if set != 0 && set != len(credential.values) {
+			return fmt.Errorf("%w: %s credentials are incomplete", errInvalidOAuth, credential.provider)
+		}
+	}
+
+	return nil
+}
+
+var (
+	oidcNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	// reservedOIDCNames are taken by other providers and /api/auth endpoints.
+	reservedOIDCNames = []string{
+		"google", "apple", "github", "anonymous", "providers", "refresh", "logout", "me", "tokens", "sessions",
+		"identities",
+	}
+)
+
+// oidcConfig is read from the OIDC_* values for slot 0 and from the OIDC_<slot>_* values for the other slots,
+// a slot without an issuer is off.
+type oidcConfig struct {
+	Name         string   `env:"OIDC_NAME,default=oidc"`
+	IssuerURL    string   `env:"OIDC_ISSUER_URL"`
+	ClientID     string   `env:"OIDC_CLIENT_ID"`
+	ClientSecret string   `env:"OIDC_CLIENT_SECRET"`
+	Scopes       []string `env:"OIDC_SCOPES,separator= ,default=openid profile email"`
+	slot         uint8
+}
+
+func parseOIDC(environ env.EnvSet) ([]oidcConfig, error) {
+	configs := make([]oidcConfig, 0, service.MaxOIDCProviders)
+
+	for slot := range uint8(service.MaxOIDCProviders) {
+		oidc := oidcConfig{slot: slot}
+
+		if err := env.Unmarshal(oidcSlotEnviron(environ, slot), &oidc); err != nil {
+			return nil, fmt.Errorf("unmarshal oidc slot %d: %w", slot, err)
+		}
+
+		if oidc.IssuerURL == "" {
+			continue
+		}
+
+		if err := validateOIDC(&oidc, configs); err != nil {
+			return nil, fmt.Errorf("validate oidc slot %d: %w", slot, err)
+		}
+
+		configs = append(configs, oidc)
+	}
+
+	return configs, nil
+}
+
+// oidcSlotEnviron renames the OIDC_<slot>_* values of a slot other than 0 to OIDC_*.
+func oidcSlotEnviron(environ env.EnvSet, slot uint8) env.EnvSet {
+	if slot == 0 {
+		return environ
+	}
+
+	prefix := fmt.Sprintf("OIDC_%d_", slot)
+	slotEnviron := make(env.EnvSet)
+
+	for key, value := range environ {
+		if name, ok := strings.CutPrefix(key, prefix); ok {
+			slotEnviron["OIDC_"+name] = value
+		}
+	}
+
+	return slotEnviron
+}
+
+func validateOIDC(oidc *oidcConfig, configured []oidcConfig) error {
+	if oidc.ClientID == "" {
+		return fmt.Errorf("%w: OIDC_CLIENT_ID is required with OIDC_ISSUER_URL", errInvalidOIDC)
+	}
+
+	if !oidcNamePattern.MatchString(oidc.Name) || slices.Contains(reservedOIDCNames, oidc.Name) {
+		return fmt.Errorf("%w: OIDC_NAME %q is not allowed", errInvalidOIDC, oidc.Name)
+	}
+
+	if slices.ContainsFunc(configured, func(other oidcConfig) bool { return other.Name == oidc.Name }) {
+		return fmt.Errorf("%w: OIDC_NAME %q is used by another slot", errInvalidOIDC, oidc.Name)
+	}
+
+	if !slices.Contains(oidc.Scopes, "openid") {
+		return fmt.Errorf("%w: OIDC_SCOPES must contain openid", errInvalidOIDC)
+	}
+
+	return nil
+}
+
+const (
+	captchaProviderRecaptcha = "recaptcha"
+	captchaProviderHCaptcha  = "hcaptcha"
+	captchaProviderTurnstile = "turnstile"
+	captchaProviderPow       = "pow"
+)
+
+func validateCaptcha(c *config) error {
+	switch c.CaptchaProvider {
+	case captchaProviderRecaptcha:
+		if c.GoogleCaptchaSecretKey == "" {
+			return fmt.Errorf("%w: GOOGLE_CAPTCHA_SECRET_KEY is required for recaptcha", errInvalidCaptcha)
+		}
+	case captchaProviderHCaptcha:
+		if c.HCaptchaSecretKey == "" {
+			return fmt.Errorf("%w: HCAPTCHA_SECRET_KEY is required for hcaptcha", errInvalidCaptcha)
+		}
+	case captchaProviderTurnstile:
+		if c.TurnstileSecretKey == "" {
+			return fmt.Errorf("%w: TURNSTILE_SECRET_KEY is required for turnstile", errInvalidCaptcha)
+		}
+	case captchaProviderPow:
+		if len(c.PowCaptchaSecret) < minTokenHashSecretLength {
+			return fmt.Errorf("%w: POW_CAPTCHA_SECRET must be at least %d bytes",
+				errWeakSecret, minTokenHashSecretLength)
+		}
+
+		if c.PowCaptchaMinDifficulty == 0 || c.PowCaptchaMinDifficulty > c.PowCaptchaMaxDifficulty {
+			return fmt.Errorf("%w: POW_CAPTCHA_MIN_DIFFICULTY must be positive and at most the max one",
+				errInvalidCaptcha)
+		}
+	default:
+		return fmt.Errorf("%w: unknown CAPTCHA_PROVIDER %q", errInvalidCaptcha, c.CaptchaProvider)
+	}
+
+	return nil
+}
+
+// parseRateLimit reads limits written as "600/1m", an empty value turns the limit off.
+func parseRateLimit(c *config) error {
+	if c.RateLimitStore != string(service.RateLimitStoreMemory) &&
+		c.RateLimitStore != string(service.RateLimitStorePostgres) {
+		return fmt.Errorf("%w: unknown RATE_LIMIT_STORE %q", errInvalidRateLimit, c.RateLimitStore)
+	}
+
+	c.rateLimit.Store = service.RateLimitStore(c.RateLimitStore)
+
+	limits := []struct {
+		limit *service.RequestLimit
+		name  string
+		value string
+	}{
+		{limit: &c.rateLimit.Redirect, name: "RATE_LIMIT_REDIRECT", value: c.RateLimitRedirect},
+		{limit: &c.rateLimit.Anonymous, name: "RATE_LIMIT_ANONYMOUS", value: c.RateLimitAnonymous},
+		{limit: &c.rateLimit.API, name: "RATE_LIMIT_API", value: c.RateLimitAPI},
+		{limit: &c.rateLimit.Auth, name: "RATE_LIMIT_AUTH", value: c.RateLimitAuth},
+	}
+
+	for _, l := range limits {
+		limit, err := parseRequestLimit(l.value)
+		if err != nil {
+			return fmt.Errorf("%w: %s %q: %w", errInvalidRateLimit, l.name, l.value, err)
+		}
+
+		*l.limit = limit
+	}
+
+	c.trustedProxies = make([]netip.Prefix, 0, len(c.TrustedProxies))
+
+	for _, proxy := range c.TrustedProxies {
+		if proxy == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return fmt.Errorf("%w: TRUSTED_PROXIES %q: %w", errInvalidRateLimit, proxy, err)
+		}
+
+		c.trustedProxies = append(c.trustedProxies, prefix)
+	}
+
+	return nil
+}
+
+var errInvalidRequestLimit = errors.New("limit must look like 600/1m")
+
+func parseRequestLimit(value string) (service.RequestLimit, error) {
+	if value == "" {
+		return service.RequestLimit{}, nil
+	}
+
+	rawBurst, rawPeriod, found := strings.Cut(value, "/")
+	if !found {
+		return service.RequestLimit{}, errInvalidRequestLimit
+	}
+
+	const (
+		burstBase    = 10
+		burstBitSize = 32
+	)
+
+	burst, err := strconv.ParseUint(rawBurst, burstBase, burstBitSize)
+	if err != nil {
+		return service.RequestLimit{}, fmt.Errorf("parse requests: %w", err)
+	}
+
+	period, err := time.ParseDuration(rawPeriod)
+	if err != nil {
+		return service.RequestLimit{}, fmt.Errorf("parse period: %w", err)
+	}
+
+	if burst == 0 || period <= 0 {
+		return service.RequestLimit{}, errInvalidRequestLimit
+	}
+
+	return service.RequestLimit{
+		Period: period,
+		Burst:  uint32(burst),
+	}, nil
+}
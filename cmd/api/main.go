@@ -12,6 +12,7 @@ import (
 	"github.com/truewebber/gopkg/signal"
 	"github.com/truewebber/gopkg/starter"
 
+	"github.com/truewebber/link-shortener/app"
 	"github.com/truewebber/link-shortener/port/httprest"
 	"github.com/truewebber/link-shortener/port/httprest/handler"
 	"github.com/truewebber/link-shortener/service"
@@ -31,24 +32,9 @@ func run(logger log.Logger) {
 	cfg := mustLoadConfig()
 	appConfig := newAppConfig(cfg)
 
-	app := service.NewAPIApp(appConfig, logger)
+	app, backgroundServers := service.NewAPIApp(appConfig, logger)
 
-	linkHandler := handler.NewLinkHandler(app, cfg.BaseHost, logger)
-	authHandler := handler.NewAuthHandler(app, extractDomainFromHost(cfg.BaseHost), logger)
-	healthHandler := handler.NewHealthHandler()
-
-	const recorderName = "link-shortener"
-	latencyRecorder := metrics.NewLatencyRecorder(recorderName)
-
-	routerHandler := httprest.NewRouterHandler(
-		linkHandler,
-		authHandler,
-		healthHandler,
-		latencyRecorder,
-		app.Query.AuthUser,
-		app.Command.ValidateCaptcha,
-		logger,
-	)
+	routerHandler := newRouterHandler(cfg, app, logger)
 
 	logger.Info("starting Link Shortener API server", "address", cfg.AppHostPort)
 
@@ -59,10 +45,17 @@ func run(logger log.Logger) {
 
 	metricsServer := metrics.NewMetricsServer(cfg.MetricsHostPort)
 
+	apiServer := newNotifyingServer(starter.WrapHTTP(server))
+
 	str := starter.NewStarter()
-	str.RegisterServer(starter.WrapHTTP(server))
+	str.RegisterServer(apiServer)
 	str.RegisterServer(starter.WrapHTTP(metricsServer))
 
+	// background servers such as the stats recorder take work from requests until the API server drains them
+	for _, backgroundServer := range backgroundServers {
+		str.RegisterServer(newDependentServer(backgroundServer, apiServer.stopped))
+	}
+
 	shutdownCtx := signal.ContextClosableOnSignals(syscall.SIGINT, syscall.SIGTERM)
 
 	if err := str.StartServers(shutdownCtx); err != nil {
@@ -72,6 +65,36 @@ func run(logger log.Logger) {
 	logger.Info("server stopped")
 }
 
+func newRouterHandler(cfg *config, app *app.APIApp, logger log.Logger) http.Handler {
+	linkHandler := handler.NewLinkHandler(app, cfg.BaseHost, logger)
+	authHandler := handler.NewAuthHandler(app, extractDomainFromHost(cfg.BaseHost), cfg.oidcProviderNames(), logger)
+	personalTokenHandler := handler.NewPersonalTokenHandler(app, logger)
+	accountHandler := handler.NewAccountHandler(app, authHandler, linkHandler, logger)
+	captchaHandler := handler.NewCaptchaHandler(app, logger)
+	adminHandler := handler.NewAdminHandler(app, linkHandler, logger)
+	healthHandler := handler.NewHealthHandler()
+
+	const recorderName = "link-shortener"
+	latencyRecorder := metrics.NewLatencyRecorder(recorderName)
+
+	return httprest.NewRouterHandler(
+		linkHandler,
+		authHandler,
+		personalTokenHandler,
+		accountHandler,
+		captchaHandler,
+		adminHandler,
+		healthHandler,
+		latencyRecorder,
+		app.Query.AuthUser,
+		app.Query.AuthPersonalToken,
+		app.Command.ValidateCaptcha,
+		app.Command.TakeRateLimit,
+		cfg.trustedProxies,
+		logger,
+	)
+}
+
 func extractDomainFromHost(host string) string {
 	if !strings.Contains(host, ":") {
 		return host
@@ -101,40 +124,128 @@ func newHTTPServer(hostPort string) *http.Server {
 
 func newAppConfig(cfg *config) *service.Config {
 	const (
-		googleCallbackPath = "/api/auth/google/callback"
-		githubCallbackPath = "/api/auth/github/callback"
-		appleCallbackPath  = "/api/auth/apple/callback"
+		statsBufferSize    = 10000
+		statsBatchSize     = 500
+		statsFlushInterval = 5 * time.Second
 	)
 
-	const createUnAuthShortURL = "create_unauthorized_short_url"
-
 	return &service.Config{
 		PostgresConnectionString: cfg.PostgresConnectionString,
+		TokenHashSecret:          cfg.TokenHashSecret,
+		ClientHashSecret:         cfg.ClientHashSecret,
+		OAuth:                    newOAuthConfig(cfg),
+		Captcha:                  newCaptchaConfig(cfg),
+		Hash: service.Hash{
+			Alphabet:     cfg.HashAlphabet,
+			Blocklist:    cfg.HashBlocklist,
+			LegacyMaxID:  cfg.HashLegacyMaxID,
+			MinLength:    cfg.HashMinLength,
+			Strategy:     service.HashStrategy(cfg.HashStrategy),
+			RandomLength: cfg.HashRandomLength,
+		},
+		DeletedUserLinks: service.DeletedUserLinks(cfg.DeletedUserLinks),
+		RateLimit:        cfg.rateLimit,
+		Safety: service.Safety{
+			DomainsFile:    cfg.SafetyDomainsFile,
+			URLPrefixFile:  cfg.SafetyURLPrefixFile,
+			PatternsFile:   cfg.SafetyPatternsFile,
+			ReloadInterval: cfg.SafetyReloadInterval,
+		},
+		Reports: service.Reports{
+			AutoDisableThreshold: cfg.ReportDisableThreshold,
+		},
+		LinkCache: service.LinkCache{
+			Size:        cfg.LinkCacheSize,
+			TTL:         cfg.LinkCacheTTL,
+			NegativeTTL: cfg.LinkCacheNegativeTTL,
+		},
+		Stats: service.Stats{
+			BufferSize:    statsBufferSize,
+			BatchSize:     statsBatchSize,
+			FlushInterval: statsFlushInterval,
+		},
+	}
+}
+
+func newCaptchaConfig(cfg *config) service.Captcha {
+	const (
+		createUnAuthShortURL = "create_unauthorized_short_url"
+		reportLink           = "report_link"
+	)
+
+	return service.Captcha{
+		Provider: service.CaptchaProvider(cfg.CaptchaProvider),
 		GoogleCaptchaV3: service.GoogleCaptchaV3{
+			VerifyURL:      cfg.CaptchaVerifyURL,
 			Secret:         cfg.GoogleCaptchaSecretKey,
-			AllowedActions: []string{createUnAuthShortURL},
+			AllowedActions: []string{createUnAuthShortURL, reportLink},
 			Threshold:      cfg.GoogleCaptchaThreshold,
 		},
-		OAuth: service.OAuth{
-			Google: service.Standard{
-				ClientID:     cfg.GoogleClientID,
-				ClientSecret: cfg.GoogleClientSecret,
-				RedirectURL:  buildCallbackURL(cfg.BaseHost, googleCallbackPath),
-			},
-			Github: service.Standard{
-				ClientID:     cfg.GithubClientID,
-				ClientSecret: cfg.GithubClientSecret,
-				RedirectURL:  buildCallbackURL(cfg.BaseHost, githubCallbackPath),
-			},
-			Apple: service.Apple{
-				ClientID:    cfg.AppleClientID,
-				PrivateKey:  cfg.ApplePrivateKey,
-				KeyID:       cfg.AppleKeyID,
-				TeamID:      cfg.AppleTeamID,
-				RedirectURL: buildCallbackURL(cfg.BaseHost, appleCallbackPath),
-			},
+		HCaptcha: service.HCaptcha{
+			VerifyURL: cfg.CaptchaVerifyURL,
+			Secret:    cfg.HCaptchaSecretKey,
+		},
+		Turnstile: service.Turnstile{
+			VerifyURL:      cfg.CaptchaVerifyURL,
+			Secret:         cfg.TurnstileSecretKey,
+			AllowedActions: cfg.TurnstileAllowedActions,
+		},
+		Pow: service.PowCaptcha{
+			Secret:        cfg.PowCaptchaSecret,
+			TTL:           cfg.PowCaptchaTTL,
+			MinDifficulty: cfg.PowCaptchaMinDifficulty,
+			MaxDifficulty: cfg.PowCaptchaMaxDifficulty,
+			LoadStep:      cfg.PowCaptchaLoadStep,
+		},
+	}
+}
+
+func newOAuthConfig(cfg *config) service.OAuth {
+	const (
+		googleCallbackPath = "/api/auth/google/callback"
+		githubCallbackPath = "/api/auth/github/callback"
+		appleCallbackPath  = "/api/auth/apple/callback"
+	)
+
+	return service.OAuth{
+		Google: service.Standard{
+			ClientID:     cfg.GoogleClientID,
+			ClientSecret: cfg.GoogleClientSecret,
+			RedirectURL:  buildCallbackURL(cfg.BaseHost, googleCallbackPath),
 		},
+		Github: service.Standard{
+			ClientID:     cfg.GithubClientID,
+			ClientSecret: cfg.GithubClientSecret,
+			RedirectURL:  buildCallbackURL(cfg.BaseHost, githubCallbackPath),
+		},
+		Apple: service.Apple{
+			ClientID:    cfg.AppleClientID,
+			PrivateKey:  cfg.ApplePrivateKey,
+			KeyID:       cfg.AppleKeyID,
+			TeamID:      cfg.AppleTeamID,
+			RedirectURL: buildCallbackURL(cfg.BaseHost, appleCallbackPath),
+		},
+		OIDC: newOIDCConfig(cfg),
+	}
+}
+
+func newOIDCConfig(cfg *config) []service.OIDC {
+	providers := make([]service.OIDC, 0, len(cfg.oidc))
+
+	for i := range cfg.oidc {
+		oidc := &cfg.oidc[i]
+
+		providers = append(providers, service.OIDC{
+			IssuerURL:    oidc.IssuerURL,
+			ClientID:     oidc.ClientID,
+			ClientSecret: oidc.ClientSecret,
+			Scopes:       oidc.Scopes,
+			RedirectURL:  buildCallbackURL(cfg.BaseHost, "/api/auth/"+oidc.Name+"/callback"),
+			Slot:         oidc.slot,
+		})
 	}
+
+	return providers
 }
 
 func buildCallbackURL(baseHost, path string) string {
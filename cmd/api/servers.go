@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/truewebber/gopkg/starter"
+)
+
+// notifyingServer closes stopped once the wrapped server has shut down.
+type notifyingServer struct {
+	starter.Server
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+func newNotifyingServer(server starter.Server) *notifyingServer {
+	return &notifyingServer{
+		Server:  server,
+		stopped: make(chan struct{}),
+	}
+}
+
+func (s *notifyingServer) Shutdown() error {
+	defer s.stopOnce.Do(func() {
+		close(s.stopped)
+	})
+
+	if err := s.Server.Shutdown(); err != nil {
+		return fmt.Errorf("notifying server shutdown: %w", err)
+	}
+
+	return nil
+}
+
+// dependentServer shuts down only after the server it serves has stopped,
+// the starter shuts every server down at once and requests in flight still use it.
+type dependentServer struct {
+	starter.Server
+	dependsOn <-chan struct{}
+}
+
+func newDependentServer(server starter.Server, dependsOn <-chan struct{}) starter.Server {
+	return &dependentServer{
+		Server:    server,
+		dependsOn: dependsOn,
+	}
+}
+
+func (s *dependentServer) Shutdown() error {
+	<-s.dependsOn
+
+	if err := s.Server.Shutdown(); err != nil {
+		return fmt.Errorf("dependent server shutdown: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Netflix/go-env"
+)
+
+type config struct {
+	PostgresConnectionString string        `env:"POSTGRES_CONNECTION_STRING,required=true"`
+	MetricsHostPort          string        `env:"METRICS_HOST_PORT,required=true"`
+	SafetyDomainsFile        string        `env:"SAFETY_DOMAINS_FILE"`
+	SafetyURLPrefixFile      string        `env:"SAFETY_URL_PREFIX_FILE"`
+	SafetyPatternsFile       string        `env:"SAFETY_PATTERNS_FILE"`
+	Interval                 time.Duration `env:"CLEANER_INTERVAL,default=1h"`
+	ScannerInterval          time.Duration `env:"SCANNER_INTERVAL,default=6h"`
+	SafetyReloadInterval     time.Duration `env:"SAFETY_RELOAD_INTERVAL,default=1m"`
+	BatchSize                uint32        `env:"CLEANER_BATCH_SIZE,default=1000"`
+	OneShot                  bool          `env:"CLEANER_ONE_SHOT,default=false"`
+}
+
+func mustLoadConfig() *config {
+	cfg, err := loadConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	return cfg
+}
+
+func loadConfig() (*config, error) {
+	c := &config{}
+
+	if _, err := env.UnmarshalFromEnviron(c); err != nil {
+		return nil, fmt.Errorf("config unmarshal: %w", err)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"os"
+	"syscall"
+
+	"github.com/truewebber/gopkg/log"
+	"github.com/truewebber/gopkg/metrics"
+	"github.com/truewebber/gopkg/signal"
+	"github.com/truewebber/gopkg/starter"
+
+	"github.com/truewebber/link-shortener/port/worker"
+	"github.com/truewebber/link-shortener/service"
+)
+
+func main() {
+	logger := log.NewLogger()
+
+	ok := run(logger)
+
+	if err := logger.Close(); err != nil {
+		panic(err)
+	}
+
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+func run(logger log.Logger) bool {
+	cfg := mustLoadConfig()
+
+	app, backgroundServers := service.NewCleanerApp(&service.CleanerConfig{
+		PostgresConnectionString: cfg.PostgresConnectionString,
+		Safety: service.Safety{
+			DomainsFile:    cfg.SafetyDomainsFile,
+			URLPrefixFile:  cfg.SafetyURLPrefixFile,
+			PatternsFile:   cfg.SafetyPatternsFile,
+			ReloadInterval: cfg.SafetyReloadInterval,
+		},
+	}, logger)
+
+	cleaner := worker.NewCleaner(app, cfg.Interval, cfg.BatchSize, logger)
+	scanner := worker.NewScanner(app, cfg.ScannerInterval, cfg.BatchSize, logger)
+
+	shutdownCtx := signal.ContextClosableOnSignals(syscall.SIGINT, syscall.SIGTERM)
+
+	if cfg.OneShot {
+		return runOnce(shutdownCtx, cleaner, scanner, logger)
+	}
+
+	logger.Info("starting cleaner", "interval", cfg.Interval.String(), "batch_size", cfg.BatchSize)
+	logger.Info("starting Metrics server", "address", cfg.MetricsHostPort)
+
+	metricsServer := metrics.NewMetricsServer(cfg.MetricsHostPort)
+
+	str := starter.NewStarter()
+	str.RegisterServer(cleaner)
+	str.RegisterServer(starter.WrapHTTP(metricsServer))
+
+	// a zero scanner interval leaves existing links unscanned
+	if cfg.ScannerInterval > 0 {
+		logger.Info("starting scanner", "interval", cfg.ScannerInterval.String())
+
+		str.RegisterServer(scanner)
+	}
+
+	for _, backgroundServer := range backgroundServers {
+		str.RegisterServer(backgroundServer)
+	}
+
+	if err := str.StartServers(shutdownCtx); err != nil {
+		logger.Error("server error", "error", err)
+
+		return false
+	}
+
+	logger.Info("cleaner stopped")
+
+	return true
+}
+
+func runOnce(ctx context.Context, cleaner *worker.Cleaner, scanner *worker.Scanner, logger log.Logger) bool {
+	logger.Info("running cleaner once")
+
+	if err := cleaner.RunOnce(ctx); err != nil {
+		logger.Error("cleaner error", "error", err)
+
+		return false
+	}
+
+	logger.Info("running scanner once")
+
+	if err := scanner.RunOnce(ctx); err != nil {
+		logger.Error("scanner error", "error", err)
+
+		return false
+	}
+
+	return true
+}
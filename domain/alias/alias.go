@@ -0,0 +1,75 @@
+package alias
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+type Alias struct {
+	CreatedAt time.Time
+	Value     string
+	ID        uint64
+	LinkID    uint64
+}
+
+var (
+	ErrNotFound      = errors.New("alias not found")
+	ErrAlreadyExists = errors.New("alias already exists")
+	ErrInvalid       = errors.New("alias invalid")
+	ErrReserved      = errors.New("alias reserved")
+)
+
+type Storage interface {
+	// CreateWithLink creates the link and its alias at once, a taken alias leaves no link behind.
+	CreateWithLink(ctx context.Context, l *link.Link, alias *Alias) error
+	ByValue(ctx context.Context, value string) (*Alias, error)
+}
+
+// New leaves LinkID to the storage, which creates the link together with the alias.
+func New(value string) (*Alias, error) {
+	if err := Validate(value); err != nil {
+		return nil, fmt.Errorf("validate alias: %w", err)
+	}
+
+	return &Alias{
+		CreatedAt: time.Now(),
+		Value:     value,
+	}, nil
+}
+
+const (
+	minLength = 4
+	maxLength = 32
+)
+
+var valueRegexp = regexp.MustCompile(`^[0-9a-zA-Z_-]+$`)
+
+//nolint:gochecknoglobals // static value
+var reserved = []string{
+	"about", "admin", "api", "app", "apps", "assets", "auth", "dashboard", "favicon", "health", "help",
+	"index", "login", "logout", "manifest", "metrics", "privacy", "profile", "report", "robots",
+	"settings", "signin", "signup", "sitemap", "static", "status", "support", "terms", "www",
+}
+
+func Validate(value string) error {
+	if len(value) < minLength || len(value) > maxLength {
+		return fmt.Errorf("%w: length must be between %d and %d", ErrInvalid, minLength, maxLength)
+	}
+
+	if !valueRegexp.MatchString(value) {
+		return fmt.Errorf("%w: only latin letters, digits, '-' and '_' are allowed", ErrInvalid)
+	}
+
+	if slices.Contains(reserved, strings.ToLower(value)) {
+		return ErrReserved
+	}
+
+	return nil
+}
@@ -3,14 +3,42 @@ package captcha
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 var (
 	ErrUnsuccessful  = errors.New("unsuccessful")
 	ErrNotHuman      = errors.New("not human")
 	ErrActionInvalid = errors.New("action invalid")
+	ErrExpired       = errors.New("challenge expired")
+	ErrAlreadySpent  = errors.New("challenge already spent")
 )
 
 type Validator interface {
 	Validate(ctx context.Context, response string) error
 }
+
+// Challenge is a proof-of-work puzzle, the client looks for the number
+// up to MaxNumber that hashes together with Salt into Challenge.
+type Challenge struct {
+	Algorithm string
+	Salt      string
+	Challenge string
+	Signature string
+	MaxNumber uint64
+}
+
+type ChallengeIssuer interface {
+	Issue(ctx context.Context) (*Challenge, error)
+}
+
+type ChallengeValidator interface {
+	Validator
+	ChallengeIssuer
+}
+
+// SpentStorage remembers solved challenges until they expire, so a solution is accepted only once.
+type SpentStorage interface {
+	Spend(ctx context.Context, challenge string, expiresAt time.Time) error
+	PurgeExpired(ctx context.Context, limit uint32) (uint32, error)
+}
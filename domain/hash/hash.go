@@ -4,3 +4,8 @@ type Generator interface {
 	ToHash(id uint64) (string, error)
 	FromHash(hash string) (uint64, error)
 }
+
+// CodeGenerator mints codes that are not derived from the link id, they are stored with the link instead.
+type CodeGenerator interface {
+	Generate() (string, error)
+}
@@ -0,0 +1,65 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/truewebber/link-shortener/domain/user"
+)
+
+// Identity is an OAuth account a user signs in with, one user may link several of them.
+type Identity struct {
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	ProviderID string
+	Email      string
+	Name       string
+	AvatarURL  string
+	ID         uint64
+	UserID     uint64
+	Provider   user.Provider
+}
+
+var (
+	ErrNotFound      = errors.New("identity not found")
+	ErrAlreadyExists = errors.New("identity already exists")
+	ErrLastIdentity  = errors.New("last identity of the user")
+)
+
+type Storage interface {
+	Create(ctx context.Context, identity *Identity) error
+	ByProviderID(ctx context.Context, provider user.Provider, providerID string) (*Identity, error)
+	ByUserID(ctx context.Context, userID uint64) ([]Identity, error)
+	Update(ctx context.Context, identity *Identity) error
+	// Delete returns ErrLastIdentity instead of leaving the user without a way to sign in.
+	Delete(ctx context.Context, id, userID uint64) error
+}
+
+func FromOAuthInfo(userID uint64, info *user.OAuthInfo) *Identity {
+	return &Identity{
+		ProviderID: info.ProviderID,
+		Email:      info.Email,
+		Name:       info.Name,
+		AvatarURL:  info.AvatarURL,
+		UserID:     userID,
+		Provider:   info.Provider,
+	}
+}
+
+func (i *Identity) IsOwnedBy(userID uint64) bool {
+	return i.UserID == userID
+}
+
+// Refresh copies the profile the provider returned on sign in, it reports whether anything changed.
+func (i *Identity) Refresh(info *user.OAuthInfo) bool {
+	if i.Email == info.Email && i.Name == info.Name && i.AvatarURL == info.AvatarURL {
+		return false
+	}
+
+	i.Email = info.Email
+	i.Name = info.Name
+	i.AvatarURL = info.AvatarURL
+
+	return true
+}
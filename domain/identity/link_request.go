@@ -0,0 +1,41 @@
+package identity
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+)
+
+// LinkRequest lets the OAuth callback attach an identity to the user who started linking it,
+// the browser only carries the Nonce.
+type LinkRequest struct {
+	ExpiresAt time.Time
+	Nonce     string
+	UserID    uint64
+}
+
+var ErrLinkRequestNotFound = errors.New("link request not found")
+
+type LinkRequestStorage interface {
+	Create(ctx context.Context, request *LinkRequest) error
+	// Consume returns the user of an unexpired request and deletes it, so a nonce links one identity at most.
+	Consume(ctx context.Context, nonce string) (uint64, error)
+	PurgeExpired(ctx context.Context, limit uint32) (uint32, error)
+}
+
+const linkNonceBytesLen = 32
+
+func NewLinkRequest(userID uint64, ttl time.Duration) *LinkRequest {
+	nonce := make([]byte, linkNonceBytesLen)
+
+	//nolint:errcheck // redundant check, rand.Read panic on err inside
+	rand.Read(nonce)
+
+	return &LinkRequest{
+		ExpiresAt: time.Now().Add(ttl),
+		Nonce:     base64.RawURLEncoding.EncodeToString(nonce),
+		UserID:    userID,
+	}
+}
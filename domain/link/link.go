@@ -5,16 +5,22 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/truewebber/link-shortener/domain/moderation"
 )
 
 type Link struct {
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	ExpiresAt   *time.Time
-	RedirectURL string
-	ID          uint64
-	UserID      uint64
-	ExpiresType ExpiresType
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	ExpiresAt     *time.Time
+	DeletedAt     *time.Time
+	BlockedAt     *time.Time
+	RedirectURL   string
+	Code          string
+	BlockedReason string
+	ID            uint64
+	UserID        uint64
+	ExpiresType   ExpiresType
 }
 
 type List struct {
@@ -22,6 +28,15 @@ type List struct {
 	Count uint32
 }
 
+// SearchFilter matches the domain against the host of the destination and its parent domains,
+// zero fields are left out and CreatedTo is exclusive.
+type SearchFilter struct {
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Domain      string
+	UserID      uint64
+}
+
 type ExpiresType uint8
 
 const (
@@ -31,21 +46,68 @@ const (
 	ExpiresTypeNever
 )
 
-var ErrNotFound = errors.New("link not found")
+var (
+	ErrNotFound           = errors.New("link not found")
+	ErrAlreadyExists      = errors.New("link already exists")
+	ErrCodeAlreadyExists  = errors.New("link code already exists")
+	ErrAliasAlreadyExists = errors.New("link alias already exists")
+	ErrExpiresInPast      = errors.New("link ttl already elapsed")
+)
 
 type Storage interface {
 	ByID(ctx context.Context, id uint64) (*Link, error)
+	IDByCode(ctx context.Context, code string) (uint64, error)
+	// CodeByID returns an empty code for links addressed by the hash derived from their id, deleted ones included.
+	CodeByID(ctx context.Context, id uint64) (string, error)
 	ByUserID(ctx context.Context, userID uint64, limit, offset uint32) (List, error)
+	// ByUserIDAfter pages through the links ByUserID lists in id order, links added meanwhile never shift a page.
+	ByUserIDAfter(ctx context.Context, userID, afterID uint64, limit uint32) ([]Link, error)
 	Create(ctx context.Context, link *Link) error
 	Update(ctx context.Context, link *Link) error
+	// Delete, DeleteExpired and DeleteByUserID free the aliases of the links, Restore takes them back
+	// and returns ErrAliasAlreadyExists when another link took an alias meanwhile.
 	Delete(ctx context.Context, id uint64) error
-	DeleteAllExpired(ctx context.Context) error
+	DeletedByID(ctx context.Context, id uint64) (*Link, error)
+	Restore(ctx context.Context, id uint64) error
+	DeleteExpired(ctx context.Context, limit uint32) (uint32, error)
+	// ReassignByUserID skips links the new owner already has for the same URL.
+	ReassignByUserID(ctx context.Context, fromUserID, toUserID uint64) error
+	DeleteByUserID(ctx context.Context, userID uint64) ([]uint64, error)
+	// Live pages through links that are neither deleted, expired nor blocked in id order.
+	Live(ctx context.Context, afterID uint64, limit uint32) ([]Link, error)
+	Block(ctx context.Context, id uint64, reason string) error
+	// Disable blocks the link the action targets for the reason of the action, Enable lifts the block,
+	// both append the action in the same transaction and return ErrNotFound when there is nothing to change.
+	Disable(ctx context.Context, action *moderation.Action) error
+	Enable(ctx context.Context, action *moderation.Action) error
+	// Search pages through links that are not deleted, expired and blocked ones included, newest first.
+	Search(ctx context.Context, filter SearchFilter, limit, offset uint32) (List, error)
+}
+
+func (l *Link) IsOwnedBy(userID uint64) bool {
+	return l.UserID == userID
+}
+
+func (l *Link) IsExpired() bool {
+	return l.ExpiresAt != nil && !time.Now().Before(*l.ExpiresAt)
+}
+
+func (l *Link) IsBlocked() bool {
+	return l.BlockedAt != nil
+}
+
+func (l *Link) CanBeRestored(gracePeriod time.Duration) bool {
+	if l.DeletedAt == nil || l.IsExpired() {
+		return false
+	}
+
+	return time.Now().Before(l.DeletedAt.Add(gracePeriod))
 }
 
 func New(userID uint64, redirectURL string, expiresType ExpiresType) (*Link, error) {
 	now := time.Now()
 
-	linkExpiresAt, err := expiresAt(expiresType)
+	linkExpiresAt, err := expiresAt(now, expiresType)
 	if err != nil {
 		return nil, fmt.Errorf("link expires at: %w", err)
 	}
@@ -61,6 +123,25 @@ func New(userID uint64, redirectURL string, expiresType ExpiresType) (*Link, err
 	}, nil
 }
 
+// ChangeExpiresType counts the new ttl from the creation of the link, not from now,
+// and returns ErrExpiresInPast when that ttl has already elapsed.
+func (l *Link) ChangeExpiresType(expiresType ExpiresType) error {
+	linkExpiresAt, err := expiresAt(l.CreatedAt, expiresType)
+	if err != nil {
+		return fmt.Errorf("link expires at: %w", err)
+	}
+
+	if linkExpiresAt != nil && !time.Now().Before(*linkExpiresAt) {
+		return ErrExpiresInPast
+	}
+
+	l.ExpiresType = expiresType
+	l.ExpiresAt = linkExpiresAt
+	l.UpdatedAt = time.Now()
+
+	return nil
+}
+
 const (
 	threeMonths  = 3
 	sixMonths    = 6
@@ -69,8 +150,8 @@ const (
 
 var errUnknownExpiresType = errors.New("unknown expires type")
 
-func expiresAt(expiresType ExpiresType) (*time.Time, error) {
-	at := time.Now()
+func expiresAt(from time.Time, expiresType ExpiresType) (*time.Time, error) {
+	at := from
 
 	switch expiresType {
 	case ExpiresType3Months:
@@ -0,0 +1,16 @@
+package lock
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNotAcquired = errors.New("lock not acquired")
+
+type Lock interface {
+	Release(ctx context.Context) error
+}
+
+type Locker interface {
+	TryAcquire(ctx context.Context, key string) (Lock, error)
+}
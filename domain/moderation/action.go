@@ -0,0 +1,46 @@
+package moderation
+
+import (
+	"context"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindDisableLink Kind = "link.disable"
+	KindEnableLink  Kind = "link.enable"
+	KindBanUser     Kind = "user.ban"
+	KindUnbanUser   Kind = "user.unban"
+)
+
+// Action is an audit record of a moderator acting on a link or a user, the kind tells which one TargetID is.
+type Action struct {
+	CreatedAt   time.Time
+	Kind        Kind
+	Reason      string
+	ID          uint64
+	ModeratorID uint64
+	TargetID    uint64
+}
+
+type List struct {
+	Actions []Action
+	Count   uint32
+}
+
+// Storage is append only, List returns the newest actions first.
+type Storage interface {
+	Create(ctx context.Context, action *Action) error
+	List(ctx context.Context, limit, offset uint32) (List, error)
+}
+
+func New(moderatorID uint64, kind Kind, targetID uint64, reason string) *Action {
+	return &Action{
+		CreatedAt:   time.Now(),
+		Kind:        kind,
+		Reason:      reason,
+		ModeratorID: moderatorID,
+		TargetID:    targetID,
+	}
+}
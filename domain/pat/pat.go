@@ -0,0 +1,121 @@
+package pat
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/truewebber/link-shortener/domain/token"
+)
+
+type Scope string
+
+const (
+	ScopeLinksRead  Scope = "links:read"
+	ScopeLinksWrite Scope = "links:write"
+)
+
+// Token is a long-lived personal access token, only the hash of its secret is kept.
+type Token struct {
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	Name       string
+	SecretHash string
+	Scopes     []Scope
+	ID         uint64
+	UserID     uint64
+}
+
+var (
+	ErrNotFound     = errors.New("personal access token not found")
+	ErrInvalidName  = errors.New("invalid personal access token name")
+	ErrInvalidScope = errors.New("invalid personal access token scope")
+)
+
+type Storage interface {
+	Create(ctx context.Context, pat *Token) error
+	ByID(ctx context.Context, id uint64) (*Token, error)
+	BySecretHash(ctx context.Context, secretHash string) (*Token, error)
+	ByUserID(ctx context.Context, userID uint64) ([]Token, error)
+	Delete(ctx context.Context, id uint64) error
+	DeleteByUserID(ctx context.Context, userID uint64) error
+	Touch(ctx context.Context, id uint64, usedAt time.Time) error
+}
+
+// SecretPrefix tells personal access tokens apart from session access tokens.
+const SecretPrefix = "lsp_"
+
+func IsSecret(value string) bool {
+	return strings.HasPrefix(value, SecretPrefix)
+}
+
+const maxNameLength = 64
+
+func New(userID uint64, name string, scopes []Scope, expiresAt *time.Time) (*Token, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxNameLength {
+		return nil, "", ErrInvalidName
+	}
+
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("%w: no scopes", ErrInvalidScope)
+	}
+
+	for _, scope := range scopes {
+		if scope != ScopeLinksRead && scope != ScopeLinksWrite {
+			return nil, "", fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+	}
+
+	secret := generateSecret()
+
+	return &Token{
+		CreatedAt:  time.Now(),
+		ExpiresAt:  expiresAt,
+		Name:       name,
+		SecretHash: HashSecret(secret),
+		Scopes:     slices.Compact(slices.Sorted(slices.Values(scopes))),
+		UserID:     userID,
+	}, secret, nil
+}
+
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
+
+const secretBytesLen = 32
+
+func generateSecret() string {
+	secretBytes := make([]byte, secretBytesLen)
+
+	//nolint:errcheck // redundant check, rand.Read panic on err inside
+	rand.Read(secretBytes)
+
+	return SecretPrefix + base64.RawURLEncoding.EncodeToString(secretBytes)
+}
+
+func (t *Token) IsOwnedBy(userID uint64) bool {
+	return t.UserID == userID
+}
+
+func (t *Token) IsExpired() bool {
+	return t.ExpiresAt != nil && !time.Now().Before(*t.ExpiresAt)
+}
+
+func (t *Token) HasScope(scope Scope) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+func (t *Token) NeedsTouch() bool {
+	return token.NeedsTouch(t.LastUsedAt)
+}
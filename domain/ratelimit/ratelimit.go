@@ -0,0 +1,43 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit is a token bucket holding up to Burst requests that refills Burst requests every Period.
+type Limit struct {
+	Period time.Duration
+	Burst  uint32
+}
+
+// Interval is the time one token takes to refill.
+func (l Limit) Interval() time.Duration {
+	return l.Period / time.Duration(l.Burst)
+}
+
+// Decision carries RetryAfter only for requests over the limit.
+type Decision struct {
+	RetryAfter time.Duration
+	Allowed    bool
+}
+
+// Store keeps buckets as the time they become full again (GCRA), one timestamp per key.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Decision, error)
+	PurgeExpired(ctx context.Context, limit uint32) (uint32, error)
+}
+
+// Allow takes a token from the bucket that becomes full at fullAt, it returns the new fullAt when allowed.
+func Allow(now, fullAt time.Time, limit Limit) (time.Time, Decision) {
+	if fullAt.Before(now) {
+		fullAt = now
+	}
+
+	next := fullAt.Add(limit.Interval())
+	if excess := next.Sub(now) - limit.Period; excess > 0 {
+		return fullAt, Decision{RetryAfter: excess}
+	}
+
+	return next, Decision{Allowed: true}
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Report is an abuse report of a short link, only the hash of the reporter is kept.
+type Report struct {
+	CreatedAt  time.Time
+	Reason     string
+	Contact    string
+	ReporterID string
+	ID         uint64
+	LinkID     uint64
+}
+
+// LinkReports aggregates the open reports of one link, there is one per reporter and Latest holds the newest ones.
+type LinkReports struct {
+	FirstReportedAt time.Time
+	LastReportedAt  time.Time
+	BlockedAt       *time.Time
+	RedirectURL     string
+	Latest          []Report
+	LinkID          uint64
+	Reporters       uint32
+}
+
+type Queue struct {
+	Links []LinkReports
+	Count uint32
+}
+
+var (
+	ErrInvalidReason   = errors.New("invalid report reason")
+	ErrInvalidContact  = errors.New("invalid report contact")
+	ErrAlreadyReported = errors.New("link already reported by the reporter")
+)
+
+// Storage keeps a single open report per link and reporter, Resolve closes every open report of the link.
+type Storage interface {
+	Create(ctx context.Context, report *Report) error
+	Reporters(ctx context.Context, linkID uint64) (uint32, error)
+	Open(ctx context.Context, limit, offset uint32) (Queue, error)
+	Resolve(ctx context.Context, linkID uint64) error
+}
+
+const (
+	maxReasonLength  = 1000
+	maxContactLength = 200
+)
+
+// New keeps the reporter as a keyed hash, the key does not rotate so a reporter is told apart on every report.
+func New(linkID uint64, reporter, reason, contact string, reporterKey []byte) (*Report, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > maxReasonLength {
+		return nil, ErrInvalidReason
+	}
+
+	contact = strings.TrimSpace(contact)
+	if utf8.RuneCountInString(contact) > maxContactLength {
+		return nil, ErrInvalidContact
+	}
+
+	return &Report{
+		CreatedAt:  time.Now().UTC(),
+		Reason:     reason,
+		Contact:    contact,
+		ReporterID: reporterID(reporterKey, reporter),
+		LinkID:     linkID,
+	}, nil
+}
+
+func reporterID(reporterKey []byte, reporter string) string {
+	mac := hmac.New(sha256.New, reporterKey)
+	mac.Write([]byte(reporter))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,22 @@
+package safety
+
+import "context"
+
+// Verdict carries the Reason only for destinations that are not safe.
+type Verdict struct {
+	Reason string
+	Safe   bool
+}
+
+// Checker screens a destination before anyone is sent to it.
+type Checker interface {
+	Check(ctx context.Context, rawURL string) (Verdict, error)
+}
+
+func Safe() Verdict {
+	return Verdict{Safe: true}
+}
+
+func Unsafe(reason string) Verdict {
+	return Verdict{Reason: reason}
+}
@@ -0,0 +1,58 @@
+package stats
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+type Visit struct {
+	VisitedAt time.Time
+	UserAgent string
+	VisitorID string
+	LinkID    uint64
+}
+
+func NewVisit(linkID uint64, clientIP, userAgent string, visitorKey []byte) Visit {
+	visitedAt := time.Now().UTC()
+
+	return Visit{
+		VisitedAt: visitedAt,
+		UserAgent: userAgent,
+		VisitorID: visitorID(visitorKey, visitedAt, clientIP, userAgent),
+		LinkID:    linkID,
+	}
+}
+
+// visitorID mixes the day into the keyed hash, so the salt rotates daily and a visitor
+// is neither recoverable from url_stats nor traceable from one day to the next,
+// visitors are therefore unique within a day only.
+func visitorID(visitorKey []byte, visitedAt time.Time, clientIP, userAgent string) string {
+	mac := hmac.New(sha256.New, visitorKey)
+	mac.Write([]byte(visitedAt.Format(time.DateOnly) + "|" + clientIP + "|" + userAgent))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type DailyClicks struct {
+	Day            time.Time
+	Clicks         uint64
+	UniqueVisitors uint64
+}
+
+type Summary struct {
+	LastVisitAt *time.Time
+	TotalClicks uint64
+}
+
+type Recorder interface {
+	Record(visit Visit)
+}
+
+type Storage interface {
+	CreateBatch(ctx context.Context, visits []Visit) error
+	Summary(ctx context.Context, linkID uint64) (Summary, error)
+	DailyClicks(ctx context.Context, linkID uint64, from, to time.Time) ([]DailyClicks, error)
+}
@@ -5,18 +5,62 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
+// Token is a session, rotations of one login share FamilyID, the id of the first token of the login.
 type Token struct {
 	AccessTokenExpiresAt  time.Time
 	RefreshTokenExpiresAt time.Time
 	CreatedAt             time.Time
 	UpdatedAt             time.Time
+	LastUsedAt            *time.Time
+	RotatedAt             *time.Time
+	Device                Device
 	AccessToken           string
 	RefreshToken          string
 	ID                    uint64
 	UserID                uint64
+	FamilyID              uint64
+	ParentID              uint64
+}
+
+// Device describes where a session was opened, it lets users recognize their sessions.
+type Device struct {
+	UserAgent string
+	IP        string
+}
+
+const maxUserAgentLength = 512
+
+func NewDevice(userAgent, ip string) Device {
+	if len(userAgent) > maxUserAgentLength {
+		// cutting may split a multibyte rune, postgres rejects invalid UTF-8
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+	}
+
+	return Device{
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+}
+
+func (t *Token) IsOwnedBy(userID uint64) bool {
+	return t.UserID == userID
+}
+
+// lastUsedPrecision keeps authorized requests from writing the token row every time.
+const lastUsedPrecision = time.Minute
+
+// NeedsTouch reports whether the last use of any kind of token is due to be written again.
+func NeedsTouch(lastUsedAt *time.Time) bool {
+	return lastUsedAt == nil || time.Since(*lastUsedAt) >= lastUsedPrecision
+}
+
+func (t *Token) NeedsTouch() bool {
+	return NeedsTouch(t.LastUsedAt)
 }
 
 func (t *Token) CanBeAuthorized() bool {
@@ -27,7 +71,26 @@ func (t *Token) CanBeRefreshed() bool {
 	return time.Now().Before(t.RefreshTokenExpiresAt)
 }
 
-func GenerateNewToken(userID uint64, accessTokenDuration, refreshTokenDuration time.Duration) (*Token, error) {
+func (t *Token) IsRotated() bool {
+	return t.RotatedAt != nil
+}
+
+// Rotate generates the pair that replaces t in its family.
+func (t *Token) Rotate(device Device, accessTokenDuration, refreshTokenDuration time.Duration) (*Token, error) {
+	token, err := GenerateNewToken(t.UserID, device, accessTokenDuration, refreshTokenDuration)
+	if err != nil {
+		return nil, fmt.Errorf("generate new token: %w", err)
+	}
+
+	token.FamilyID = t.FamilyID
+	token.ParentID = t.ID
+
+	return token, nil
+}
+
+func GenerateNewToken(
+	userID uint64, device Device, accessTokenDuration, refreshTokenDuration time.Duration,
+) (*Token, error) {
 	accessToken := generateTokenString()
 	refreshToken := generateTokenString()
 
@@ -35,6 +98,7 @@ func GenerateNewToken(userID uint64, accessTokenDuration, refreshTokenDuration t
 
 	return &Token{
 		UserID:                userID,
+		Device:                device,
 		AccessToken:           accessToken,
 		RefreshToken:          refreshToken,
 		AccessTokenExpiresAt:  now.Add(accessTokenDuration),
@@ -53,12 +117,24 @@ func generateTokenString() string {
 	return base64.URLEncoding.EncodeToString(tokenBytes)
 }
 
-var ErrTokenNotFound = errors.New("token not found")
+var (
+	ErrTokenNotFound       = errors.New("token not found")
+	ErrTokenAlreadyRotated = errors.New("token already rotated")
+)
 
 type Storage interface {
 	Create(ctx context.Context, token *Token) error
 	ByAccessToken(ctx context.Context, value string) (*Token, error)
+	// ByRefreshToken also finds rotated tokens, so that their reuse can be detected.
 	ByRefreshToken(ctx context.Context, value string) (*Token, error)
+	ByID(ctx context.Context, id uint64) (*Token, error)
+	ActiveByUserID(ctx context.Context, userID uint64) ([]Token, error)
+	Touch(ctx context.Context, id uint64, usedAt time.Time) error
 	DeleteByID(ctx context.Context, id uint64) error
 	DeleteByUserID(ctx context.Context, userID uint64) error
+	DeleteByFamilyID(ctx context.Context, familyID uint64) error
+	// Rotate creates token and marks its parent rotated atomically,
+	// ErrTokenAlreadyRotated is returned when the parent was rotated or deleted meanwhile.
+	Rotate(ctx context.Context, token *Token) error
+	PurgeExpired(ctx context.Context, limit uint32) (uint32, error)
 }
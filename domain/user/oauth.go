@@ -10,7 +10,8 @@ type OAuthInfo struct {
 	Provider   Provider
 }
 
+// OAuthProvider receives the PKCE code verifier of the login, providers without PKCE support ignore it.
 type OAuthProvider interface {
-	GetAuthURL(state string) (string, error)
-	ExchangeCode(ctx context.Context, code string) (*OAuthInfo, error)
+	GetAuthURL(ctx context.Context, state, codeVerifier string) (string, error)
+	ExchangeCode(ctx context.Context, code, codeVerifier string) (*OAuthInfo, error)
 }
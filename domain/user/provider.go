@@ -7,4 +7,25 @@ const (
 	ProviderGoogle
 	ProviderApple
 	ProviderGithub
+	// ProviderOIDC is the OpenID Connect provider of slot 0, the next MaxOIDCProviders-1 values belong to the
+	// other slots.
+	ProviderOIDC
 )
+
+// MaxOIDCProviders is the number of OpenID Connect providers that may be configured side by side.
+const MaxOIDCProviders = 8
+
+// OIDCProvider returns the provider of an OpenID Connect slot, identities keep it, so a slot must not be reused
+// for another issuer.
+func OIDCProvider(slot uint8) Provider {
+	return ProviderOIDC + Provider(slot)
+}
+
+// OIDCSlot reports the slot of an OpenID Connect provider.
+func (p Provider) OIDCSlot() (uint8, bool) {
+	if p < ProviderOIDC || p >= ProviderOIDC+MaxOIDCProviders {
+		return 0, false
+	}
+
+	return uint8(p - ProviderOIDC), true
+}
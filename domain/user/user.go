@@ -4,28 +4,53 @@ import (
 	"context"
 	"errors"
 	"time"
+
+	"github.com/truewebber/link-shortener/domain/moderation"
 )
 
 type User struct {
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+	BannedAt   *time.Time
 	Name       string
 	Email      string
 	ProviderID string
 	AvatarURL  string
 	ID         uint64
 	Provider   Provider
+	Role       Role
 }
 
+type Role uint8
+
+const (
+	RoleUser Role = iota + 1
+	RoleAdmin
+)
+
 var (
 	ErrUserNotFound  = errors.New("user not found")
 	ErrAlreadyExists = errors.New("user already exists")
 )
 
+// Storage keeps the profile of the identity the user signed in with last,
+// Create records that identity as the first identity of the user,
+// Delete anonymizes the profile and drops the identities, so they can sign up again.
 type Storage interface {
 	Create(ctx context.Context, user *User) error
 	ByID(ctx context.Context, id uint64) (*User, error)
-	ByProviderID(ctx context.Context, provider Provider, providerID string) (*User, error)
 	Delete(ctx context.Context, id uint64) error
 	Update(ctx context.Context, user *User) error
+	// Ban keeps the time of the first ban of the user the action targets, Unban lifts it,
+	// both append the action in the same transaction and return ErrUserNotFound for deleted users.
+	Ban(ctx context.Context, action *moderation.Action) error
+	Unban(ctx context.Context, action *moderation.Action) error
+}
+
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+func (u *User) IsBanned() bool {
+	return u.BannedAt != nil
 }
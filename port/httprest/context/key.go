@@ -5,4 +5,6 @@ type key uint8
 const (
 	KeyToken key = iota
 	KeyUser
+	KeyScopes
+	KeyClientIP
 )
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/app"
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/query"
+	apptypes "github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/port/httprest/context"
+)
+
+// AccountHandler builds its responses the same way the auth and link endpoints do.
+type AccountHandler struct {
+	logger      log.Logger
+	app         *app.APIApp
+	authHandler *AuthHandler
+	linkHandler *LinkHandler
+}
+
+func NewAccountHandler(
+	app *app.APIApp,
+	authHandler *AuthHandler,
+	linkHandler *LinkHandler,
+	logger log.Logger,
+) *AccountHandler {
+	return &AccountHandler{
+		logger:      logger,
+		app:         app,
+		authHandler: authHandler,
+		linkHandler: linkHandler,
+	}
+}
+
+type LinkExportResponse struct {
+	Stats *LinkStatsResponse `json:"stats"`
+	LinkResponse
+}
+
+// AccountExportResponse is followed by a "links" array of LinkExportResponse, written as the links are read.
+type AccountExportResponse struct {
+	User         *userInfo          `json:"user"`
+	Identities   []IdentityResponse `json:"identities"`
+	ExportedAtMS int64              `json:"exported_at_ms"`
+}
+
+func (h *AccountHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	err := h.app.Command.DeleteAccount.Handle(r.Context(), user.ID)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		h.logger.Error("failed to delete account", "user_id", user.ID, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+const exportFileName = "link-shortener-export.json"
+
+func (h *AccountHandler) ExportAccount(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	export, err := h.app.Query.ExportAccount.Handle(r.Context(), user.ID)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		h.logger.Error("failed to export account", "user_id", user.ID, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	resp, err := h.buildAccountExportResponse(export)
+	if err != nil {
+		h.logger.Error("failed to build account export response", "user_id", user.ID, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	head, err := json.Marshal(resp)
+	if err != nil {
+		h.logger.Error("failed to encode account export", "user_id", user.ID, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+exportFileName+`"`)
+
+	// headers are gone by the time the export fails half way, the download is cut short instead
+	if streamErr := h.streamAccountExport(r.Context(), w, head, user.ID); streamErr != nil {
+		h.logger.Error("failed to stream account export", "user_id", user.ID, "error", streamErr)
+
+		return
+	}
+}
+
+// streamAccountExport writes head with the links appended to it.
+func (h *AccountHandler) streamAccountExport(ctx stdcontext.Context, w io.Writer, head []byte, userID uint64) error {
+	// head is a JSON object, its closing brace comes after the links
+	if _, err := w.Write(append(head[:len(head)-1], `,"links":[`...)); err != nil {
+		return fmt.Errorf("write head: %w", err)
+	}
+
+	encoder := json.NewEncoder(w)
+	separator := []byte{}
+
+	err := h.app.Query.ExportAccount.ExportLinks(ctx, userID, func(linkExport *query.LinkExport) error {
+		linkResp, err := h.linkHandler.buildLinkResponse(&linkExport.Link)
+		if err != nil {
+			return fmt.Errorf("build link response: %w", err)
+		}
+
+		if _, err = w.Write(separator); err != nil {
+			return fmt.Errorf("write separator: %w", err)
+		}
+
+		separator = []byte{','}
+
+		if err = encoder.Encode(LinkExportResponse{
+			Stats:        h.linkHandler.buildLinkStatsResponse(&linkExport.Stats),
+			LinkResponse: linkResp,
+		}); err != nil {
+			return fmt.Errorf("encode link: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("export links: %w", err)
+	}
+
+	if _, err = io.WriteString(w, "]}\n"); err != nil {
+		return fmt.Errorf("write tail: %w", err)
+	}
+
+	return nil
+}
+
+func (h *AccountHandler) buildAccountExportResponse(export *query.AccountExport) (*AccountExportResponse, error) {
+	user, err := h.authHandler.buildUserInfo(export.User)
+	if err != nil {
+		return nil, fmt.Errorf("build user info: %w", err)
+	}
+
+	resp := &AccountExportResponse{
+		User:         user,
+		Identities:   make([]IdentityResponse, 0, len(export.Identities)),
+		ExportedAtMS: time.Now().UnixMilli(),
+	}
+
+	for i := range export.Identities {
+		identity, buildErr := h.authHandler.buildIdentityResponse(&export.Identities[i])
+		if buildErr != nil {
+			return nil, fmt.Errorf("build identity response: %w", buildErr)
+		}
+
+		resp.Identities = append(resp.Identities, identity)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,399 @@
+package handler
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/app"
+	"github.com/truewebber/link-shortener/app/command"
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/query"
+	apptypes "github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/port/httprest/context"
+)
+
+// AdminHandler serves moderators, links are addressed by id here since search results may span owners.
+type AdminHandler struct {
+	logger      log.Logger
+	app         *app.APIApp
+	linkHandler *LinkHandler
+}
+
+func NewAdminHandler(app *app.APIApp, linkHandler *LinkHandler, logger log.Logger) *AdminHandler {
+	return &AdminHandler{
+		logger:      logger,
+		app:         app,
+		linkHandler: linkHandler,
+	}
+}
+
+type AdminLinkResponse struct {
+	LinkResponse
+	ID     uint64 `json:"id"`
+	UserID uint64 `json:"user_id"`
+}
+
+type AdminListLinksResponse struct {
+	Links  []AdminLinkResponse `json:"links"`
+	Total  uint32              `json:"total"`
+	Limit  uint32              `json:"limit"`
+	Offset uint32              `json:"offset"`
+}
+
+type ModerationActionResponse struct {
+	Action      string `json:"action"`
+	Reason      string `json:"reason"`
+	ID          uint64 `json:"id"`
+	ModeratorID uint64 `json:"moderator_id"`
+	TargetID    uint64 `json:"target_id"`
+	CreatedAtMS int64  `json:"created_at_ms"`
+}
+
+type ListModerationActionsResponse struct {
+	Actions []ModerationActionResponse `json:"actions"`
+	Total   uint32                     `json:"total"`
+	Limit   uint32                     `json:"limit"`
+	Offset  uint32                     `json:"offset"`
+}
+
+type ReportResponse struct {
+	Reason      string `json:"reason"`
+	Contact     string `json:"contact,omitempty"`
+	ID          uint64 `json:"id"`
+	CreatedAtMS int64  `json:"created_at_ms"`
+}
+
+type LinkReportsResponse struct {
+	BlockedAtMS       *int64           `json:"blocked_at_ms,omitempty"`
+	URL               string           `json:"url"`
+	Latest            []ReportResponse `json:"latest"`
+	LinkID            uint64           `json:"link_id"`
+	FirstReportedAtMS int64            `json:"first_reported_at_ms"`
+	LastReportedAtMS  int64            `json:"last_reported_at_ms"`
+	Reporters         uint32           `json:"reporters"`
+}
+
+type ListReportsResponse struct {
+	Links  []LinkReportsResponse `json:"links"`
+	Total  uint32                `json:"total"`
+	Limit  uint32                `json:"limit"`
+	Offset uint32                `json:"offset"`
+}
+
+type ModerationRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *AdminHandler) SearchLinks(w http.ResponseWriter, r *http.Request) {
+	params, err := buildSearchLinksParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	list, err := h.app.Query.SearchLinks.Handle(r.Context(), params)
+	if err != nil {
+		h.logger.Error("failed to search links", "params", params, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	resp := &AdminListLinksResponse{
+		Links:  make([]AdminLinkResponse, 0, len(list.Links)),
+		Total:  list.Count,
+		Limit:  params.Limit,
+		Offset: params.Offset,
+	}
+
+	for i := range list.Links {
+		linkResp, buildErr := h.linkHandler.buildLinkResponse(&list.Links[i])
+		if buildErr != nil {
+			h.logger.Error("failed to build link response", "params", params, "error", buildErr)
+			http.Error(w, "internal", http.StatusInternalServerError)
+
+			return
+		}
+
+		resp.Links = append(resp.Links, AdminLinkResponse{
+			LinkResponse: linkResp,
+			ID:           list.Links[i].ID,
+			UserID:       list.Links[i].UserID,
+		})
+	}
+
+	h.writeJSON(w, resp)
+}
+
+func (h *AdminHandler) DisableLink(w http.ResponseWriter, r *http.Request) {
+	h.moderateLink(w, r, true, h.app.Command.DisableLink.Handle)
+}
+
+func (h *AdminHandler) EnableLink(w http.ResponseWriter, r *http.Request) {
+	h.moderateLink(w, r, false, h.app.Command.EnableLink.Handle)
+}
+
+func (h *AdminHandler) BanUser(w http.ResponseWriter, r *http.Request) {
+	h.moderateUser(w, r, true, h.app.Command.BanUser.Handle)
+}
+
+func (h *AdminHandler) UnbanUser(w http.ResponseWriter, r *http.Request) {
+	h.moderateUser(w, r, false, h.app.Command.UnbanUser.Handle)
+}
+
+func (h *AdminHandler) ListModerationActions(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r.URL.Query())
+	if err != nil {
+		http.Error(w, "invalid pagination", http.StatusBadRequest)
+
+		return
+	}
+
+	params := query.ListModerationActionsParams{Limit: limit, Offset: offset}
+
+	list, err := h.app.Query.ListModerationActions.Handle(r.Context(), params)
+	if err != nil {
+		h.logger.Error("failed to list moderation actions", "params", params, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	resp := &ListModerationActionsResponse{
+		Actions: make([]ModerationActionResponse, 0, len(list.Actions)),
+		Total:   list.Count,
+		Limit:   limit,
+		Offset:  offset,
+	}
+
+	for _, action := range list.Actions {
+		resp.Actions = append(resp.Actions, ModerationActionResponse{
+			Action:      action.Kind,
+			Reason:      action.Reason,
+			ID:          action.ID,
+			ModeratorID: action.ModeratorID,
+			TargetID:    action.TargetID,
+			CreatedAtMS: action.CreatedAt.UnixMilli(),
+		})
+	}
+
+	h.writeJSON(w, resp)
+}
+
+// ListReports shows the open report queue, disabling or enabling a link resolves its reports.
+func (h *AdminHandler) ListReports(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r.URL.Query())
+	if err != nil {
+		http.Error(w, "invalid pagination", http.StatusBadRequest)
+
+		return
+	}
+
+	params := query.ListOpenReportsParams{Limit: limit, Offset: offset}
+
+	queue, err := h.app.Query.ListOpenReports.Handle(r.Context(), params)
+	if err != nil {
+		h.logger.Error("failed to list open reports", "params", params, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	resp := &ListReportsResponse{
+		Links:  make([]LinkReportsResponse, 0, len(queue.Links)),
+		Total:  queue.Count,
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	for i := range queue.Links {
+		resp.Links = append(resp.Links, buildLinkReportsResponse(&queue.Links[i]))
+	}
+
+	h.writeJSON(w, resp)
+}
+
+func buildLinkReportsResponse(linkReports *apptypes.LinkReports) LinkReportsResponse {
+	resp := LinkReportsResponse{
+		URL:               linkReports.RedirectURL,
+		Latest:            make([]ReportResponse, 0, len(linkReports.Latest)),
+		LinkID:            linkReports.LinkID,
+		FirstReportedAtMS: linkReports.FirstReportedAt.UnixMilli(),
+		LastReportedAtMS:  linkReports.LastReportedAt.UnixMilli(),
+		Reporters:         linkReports.Reporters,
+	}
+
+	if linkReports.BlockedAt != nil {
+		blockedAtMS := linkReports.BlockedAt.UnixMilli()
+		resp.BlockedAtMS = &blockedAtMS
+	}
+
+	for _, r := range linkReports.Latest {
+		resp.Latest = append(resp.Latest, ReportResponse{
+			Reason:      r.Reason,
+			Contact:     r.Contact,
+			ID:          r.ID,
+			CreatedAtMS: r.CreatedAt.UnixMilli(),
+		})
+	}
+
+	return resp
+}
+
+func (h *AdminHandler) moderateLink(
+	w http.ResponseWriter, r *http.Request, reasonRequired bool,
+	handle func(ctx stdcontext.Context, params command.ModerateLinkParams) error,
+) {
+	moderatorID, targetID, reason, ok := h.readModeration(w, r, reasonRequired)
+	if !ok {
+		return
+	}
+
+	params := command.ModerateLinkParams{Reason: reason, ModeratorID: moderatorID, LinkID: targetID}
+
+	err := handle(r.Context(), params)
+
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrLinkAlreadyBlocked):
+		http.Error(w, "link already disabled", http.StatusConflict)
+	case errors.Is(err, apperrors.ErrLinkNotBlocked):
+		http.Error(w, "link not disabled", http.StatusConflict)
+	default:
+		h.logger.Error("failed to moderate link", "params", params, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+	}
+}
+
+func (h *AdminHandler) moderateUser(
+	w http.ResponseWriter, r *http.Request, reasonRequired bool,
+	handle func(ctx stdcontext.Context, params command.ModerateUserParams) error,
+) {
+	moderatorID, targetID, reason, ok := h.readModeration(w, r, reasonRequired)
+	if !ok {
+		return
+	}
+
+	params := command.ModerateUserParams{Reason: reason, ModeratorID: moderatorID, UserID: targetID}
+
+	err := handle(r.Context(), params)
+
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, apperrors.ErrUserNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrUserNotBannable):
+		http.Error(w, "user can not be banned", http.StatusConflict)
+	default:
+		h.logger.Error("failed to moderate user", "params", params, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+	}
+}
+
+const maxModerationReasonLength = 500
+
+// readModeration writes the error response itself and reports whether the handler may go on.
+func (h *AdminHandler) readModeration(
+	w http.ResponseWriter, r *http.Request, reasonRequired bool,
+) (moderatorID, targetID uint64, reason string, ok bool) {
+	moderator, isUser := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !isUser {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return 0, 0, "", false
+	}
+
+	targetID, err := strconv.ParseUint(mux.Vars(r)["id"], decimalBase, uint64BitSize)
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return 0, 0, "", false
+	}
+
+	var req ModerationRequest
+
+	if r.ContentLength != 0 {
+		if decodeErr := json.NewDecoder(r.Body).Decode(&req); decodeErr != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+
+			return 0, 0, "", false
+		}
+	}
+
+	reason = strings.TrimSpace(req.Reason)
+
+	if (reasonRequired && reason == "") || utf8.RuneCountInString(reason) > maxModerationReasonLength {
+		http.Error(w, "reason is required, up to 500 characters", http.StatusBadRequest)
+
+		return 0, 0, "", false
+	}
+
+	return moderator.ID, targetID, reason, true
+}
+
+func (h *AdminHandler) writeJSON(w http.ResponseWriter, resp any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if encodeErr := json.NewEncoder(w).Encode(resp); encodeErr != nil {
+		h.logger.Error("failed to encode response", "response", resp, "error", encodeErr)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+var errInvalidSearch = errors.New("invalid search")
+
+// buildSearchLinksParams takes the creation window as RFC 3339 times in UTC, created_to is exclusive.
+func buildSearchLinksParams(values url.Values) (query.SearchLinksParams, error) {
+	limit, offset, err := parsePagination(values)
+	if err != nil {
+		return query.SearchLinksParams{}, fmt.Errorf("%w: pagination", errInvalidSearch)
+	}
+
+	params := query.SearchLinksParams{
+		Domain: strings.TrimSuffix(strings.ToLower(strings.TrimSpace(values.Get("domain"))), "."),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	if rawUserID := values.Get("user_id"); rawUserID != "" {
+		if params.UserID, err = strconv.ParseUint(rawUserID, decimalBase, uint64BitSize); err != nil {
+			return query.SearchLinksParams{}, fmt.Errorf("%w: user_id", errInvalidSearch)
+		}
+	}
+
+	if rawFrom := values.Get("created_from"); rawFrom != "" {
+		if params.CreatedFrom, err = time.Parse(time.RFC3339, rawFrom); err != nil {
+			return query.SearchLinksParams{}, fmt.Errorf("%w: created_from", errInvalidSearch)
+		}
+
+		params.CreatedFrom = params.CreatedFrom.UTC()
+	}
+
+	if rawTo := values.Get("created_to"); rawTo != "" {
+		if params.CreatedTo, err = time.Parse(time.RFC3339, rawTo); err != nil {
+			return query.SearchLinksParams{}, fmt.Errorf("%w: created_to", errInvalidSearch)
+		}
+
+		params.CreatedTo = params.CreatedTo.UTC()
+	}
+
+	return params, nil
+}
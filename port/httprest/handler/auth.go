@@ -1,6 +1,7 @@
 package handler
 
 import (
+	stdcontext "context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
@@ -22,20 +23,64 @@ import (
 )
 
 type AuthHandler struct {
-	app          *app.APIApp
-	logger       log.Logger
-	cookieDomain string
+	app               *app.APIApp
+	logger            log.Logger
+	oidcProviderNames map[uint8]string
+	cookieDomain      string
 }
 
+// NewAuthHandler serves every OpenID Connect provider under its name in oidcProviderNames, keyed by slot.
 func NewAuthHandler(
 	app *app.APIApp,
 	cookieDomain string,
+	oidcProviderNames map[uint8]string,
 	logger log.Logger,
 ) *AuthHandler {
 	return &AuthHandler{
-		app:          app,
-		logger:       logger,
-		cookieDomain: cookieDomain,
+		app:               app,
+		logger:            logger,
+		oidcProviderNames: oidcProviderNames,
+		cookieDomain:      cookieDomain,
+	}
+}
+
+type providersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// ProviderNames returns the path segments of the enabled OAuth providers.
+func (h *AuthHandler) ProviderNames(ctx stdcontext.Context) []string {
+	providers := h.app.Query.ListProviders.Handle(ctx)
+
+	names := make([]string, 0, len(providers))
+
+	for _, provider := range providers {
+		name, err := h.buildFromUseCaseProvider(provider)
+		if err != nil {
+			h.logger.Error("skipping provider without route", "provider", provider, "error", err)
+
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func (h *AuthHandler) Providers(w http.ResponseWriter, r *http.Request) {
+	response := providersResponse{
+		Providers: h.ProviderNames(r.Context()),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("failed to json encode response", "response", response, "error", err)
+
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
 	}
 }
 
@@ -52,6 +97,7 @@ type userInfo struct {
 	AvatarURL string `json:"avatar_url,omitempty"`
 	Provider  string `json:"provider"`
 	ID        uint64 `json:"id"`
+	IsAdmin   bool   `json:"is_admin,omitempty"`
 }
 
 type refreshRequest struct {
@@ -59,51 +105,70 @@ type refreshRequest struct {
 }
 
 func (h *AuthHandler) StartOAuth(w http.ResponseWriter, r *http.Request) {
-	pathVars := mux.Vars(r)
+	authURL, err := h.beginOAuth(w, r)
+	if errors.Is(err, errUnsupportedProvider) || errors.Is(err, apperrors.ErrProviderNotFound) {
+		http.Error(w, "unknown provider", http.StatusNotFound)
+
+		return
+	}
 
-	provider, err := h.buildToUseCaseProvider(pathVars["provider"])
 	if err != nil {
-		http.Error(w, "invalid provider", http.StatusBadRequest)
+		h.logger.Error("failed to get auth url", "provider", mux.Vars(r)["provider"], "error", err)
+
+		http.Error(w, "internal", http.StatusInternalServerError)
 
 		return
 	}
 
-	state := h.generateState()
+	// a link started earlier and abandoned must not turn this sign in into linking
+	http.SetCookie(w, h.buildExpiredOAuthCookie(linkCookieName))
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
 
-	response, err := h.app.Query.GetAuthURL.Handle(r.Context(), provider, state)
+// beginOAuth sets the cookies the callback checks and returns the provider URL to send the browser to.
+func (h *AuthHandler) beginOAuth(w http.ResponseWriter, r *http.Request) (string, error) {
+	provider, err := h.buildToUseCaseProvider(mux.Vars(r)["provider"])
 	if err != nil {
-		h.logger.Error("failed to get auth url", "provider", provider, "error", err)
+		return "", fmt.Errorf("build provider: %w", err)
+	}
 
-		http.Error(w, "internal", http.StatusInternalServerError)
+	state := h.generateState()
+	codeVerifier := h.generateState()
 
-		return
+	response, err := h.app.Query.GetAuthURL.Handle(r.Context(), provider, state, codeVerifier)
+	if err != nil {
+		return "", fmt.Errorf("get auth url: %w", err)
 	}
 
-	cookie := h.buildStateCooke(state)
-	http.SetCookie(w, cookie)
+	http.SetCookie(w, h.buildOAuthCookie(stateCookieName, state))
+	http.SetCookie(w, h.buildOAuthCookie(codeVerifierCookieName, codeVerifier))
 
-	http.Redirect(w, r, response.URL, http.StatusFound)
+	return response.URL, nil
 }
 
 const stateBytesLen = 32
 
+// generateState is also used for PKCE code verifiers, 32 bytes encode to the 43 characters RFC 7636 requires.
 func (h *AuthHandler) generateState() string {
 	b := make([]byte, stateBytesLen)
 	//nolint:errcheck // redundant check, rand.Read panic on err inside
 	rand.Read(b)
 
-	return base64.StdEncoding.EncodeToString(b)
+	return base64.RawURLEncoding.EncodeToString(b)
 }
 
 const (
-	stateCookieName   = "oauth_state"
-	stateCookieMaxAge = 300
+	stateCookieName        = "oauth_state"
+	codeVerifierCookieName = "oauth_code_verifier"
+	linkCookieName         = "oauth_link"
+	stateCookieMaxAge      = 300
 )
 
-func (h *AuthHandler) buildStateCooke(state string) *http.Cookie {
+func (h *AuthHandler) buildOAuthCookie(name, value string) *http.Cookie {
 	return &http.Cookie{
-		Name:     stateCookieName,
-		Value:    state,
+		Name:     name,
+		Value:    value,
 		Path:     "/",
 		Secure:   true,
 		HttpOnly: true,
@@ -113,6 +178,13 @@ func (h *AuthHandler) buildStateCooke(state string) *http.Cookie {
 	}
 }
 
+func (h *AuthHandler) buildExpiredOAuthCookie(name string) *http.Cookie {
+	cookie := h.buildOAuthCookie(name, "")
+	cookie.MaxAge = -1
+
+	return cookie
+}
+
 func (h *AuthHandler) validateState(r *http.Request, state string) bool {
 	cookie, err := r.Cookie(stateCookieName)
 	if err != nil {
@@ -122,6 +194,15 @@ func (h *AuthHandler) validateState(r *http.Request, state string) bool {
 	return cookie.Value == state
 }
 
+func (h *AuthHandler) codeVerifier(r *http.Request) string {
+	cookie, err := r.Cookie(codeVerifierCookieName)
+	if err != nil {
+		return ""
+	}
+
+	return cookie.Value
+}
+
 func (h *AuthHandler) OAuthCallback(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
 		redirectURL := h.buildRedirectErrorURL("invalid request")
@@ -138,9 +219,9 @@ func (h *AuthHandler) OAuthCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pathVars := mux.Vars(r)
+	http.SetCookie(w, h.buildExpiredOAuthCookie(linkCookieName))
 
-	provider, err := h.buildToUseCaseProvider(pathVars["provider"])
+	params, err := h.buildFinishOAuthParams(r)
 	if err != nil {
 		redirectURL := h.buildRedirectErrorURL("invalid request")
 		http.Redirect(w, r, redirectURL, http.StatusFound)
@@ -148,23 +229,16 @@ func (h *AuthHandler) OAuthCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	code := r.FormValue("code")
-	userData := r.FormValue("user")
-	errorMsg := r.FormValue("error")
-
-	params := command.FinishOAuthParams{
-		Provider:     provider,
-		Code:         code,
-		ErrorMessage: errorMsg,
-		UserData:     []byte(userData),
-	}
-
 	oauthInfo, err := h.app.Command.FinishOAuth.Handle(r.Context(), params)
 	if err != nil {
-		h.logger.Error("failed handle FinishOAuth", "params", params, "error", err)
+		message := finishOAuthFailMessage(err)
+		if message == "" {
+			h.logger.Error("failed handle FinishOAuth", "params", params, "error", err)
 
-		redirectURL := h.buildRedirectErrorURL("unknown error")
-		http.Redirect(w, r, redirectURL, http.StatusFound)
+			message = "unknown error"
+		}
+
+		http.Redirect(w, r, h.buildRedirectErrorURL(message), http.StatusFound)
 
 		return
 	}
@@ -175,6 +249,48 @@ func (h *AuthHandler) OAuthCallback(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, redirectURL, http.StatusFound)
 }
 
+// finishOAuthFailMessage is empty for an unexpected error.
+func finishOAuthFailMessage(err error) string {
+	switch {
+	case errors.Is(err, apperrors.ErrIdentityAlreadyLinked):
+		return "account already linked to another user"
+	case errors.Is(err, apperrors.ErrUserBanned):
+		return "account banned"
+	case errors.Is(err, apperrors.ErrLinkRequestExpired):
+		return "account link expired"
+	}
+
+	return ""
+}
+
+func (h *AuthHandler) buildFinishOAuthParams(r *http.Request) (command.FinishOAuthParams, error) {
+	provider, err := h.buildToUseCaseProvider(mux.Vars(r)["provider"])
+	if err != nil {
+		return command.FinishOAuthParams{}, fmt.Errorf("build provider: %w", err)
+	}
+
+	return command.FinishOAuthParams{
+		Provider:     provider,
+		Code:         r.FormValue("code"),
+		CodeVerifier: h.codeVerifier(r),
+		ErrorMessage: r.FormValue("error"),
+		UserAgent:    r.UserAgent(),
+		ClientIP:     requestClientIP(r),
+		UserData:     []byte(r.FormValue("user")),
+		LinkNonce:    h.linkNonce(r),
+	}, nil
+}
+
+// linkNonce is empty for a plain sign in.
+func (h *AuthHandler) linkNonce(r *http.Request) string {
+	cookie, err := r.Cookie(linkCookieName)
+	if err != nil {
+		return ""
+	}
+
+	return cookie.Value
+}
+
 const decimalBase = 10
 
 func (h *AuthHandler) buildRedirectSuccessURL(
@@ -222,19 +338,9 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	auth, err := h.app.Command.RefreshToken.Handle(r.Context(), req.RefreshToken)
-	if errors.Is(err, apperrors.ErrInvalidCredentials) ||
-		errors.Is(err, apperrors.ErrTokenExpired) ||
-		errors.Is(err, apperrors.ErrUserNotFound) {
-		http.Error(w, "invalid or expired refresh token", http.StatusUnauthorized)
-
-		return
-	}
-
+	auth, err := h.app.Command.RefreshToken.Handle(r.Context(), h.buildRefreshTokenParams(r, &req))
 	if err != nil {
-		h.logger.Error("failed to refresh token", "request", req, "error", err)
-
-		http.Error(w, "internal", http.StatusInternalServerError)
+		h.writeRefreshTokenError(w, &req, err)
 
 		return
 	}
@@ -259,6 +365,28 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *AuthHandler) writeRefreshTokenError(w http.ResponseWriter, req *refreshRequest, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidCredentials),
+		errors.Is(err, apperrors.ErrTokenExpired),
+		errors.Is(err, apperrors.ErrUserNotFound):
+		http.Error(w, "invalid or expired refresh token", http.StatusUnauthorized)
+	case errors.Is(err, apperrors.ErrUserBanned):
+		http.Error(w, "account banned", http.StatusForbidden)
+	default:
+		h.logger.Error("failed to refresh token", "request", req, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+	}
+}
+
+func (h *AuthHandler) buildRefreshTokenParams(r *http.Request, req *refreshRequest) command.RefreshTokenParams {
+	return command.RefreshTokenParams{
+		RefreshToken: req.RefreshToken,
+		UserAgent:    r.UserAgent(),
+		ClientIP:     requestClientIP(r),
+	}
+}
+
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	accessToken, ok := r.Context().Value(context.KeyToken).(string)
 	if !ok {
@@ -323,6 +451,12 @@ const (
 var errUnsupportedProvider = errors.New("unsupported provider")
 
 func (h *AuthHandler) buildToUseCaseProvider(provider string) (apptypes.Provider, error) {
+	for slot, name := range h.oidcProviderNames {
+		if provider == name {
+			return apptypes.OIDCProvider(slot), nil
+		}
+	}
+
 	switch provider {
 	case providerAnonymous:
 		return apptypes.ProviderAnonymous, nil
@@ -338,6 +472,10 @@ func (h *AuthHandler) buildToUseCaseProvider(provider string) (apptypes.Provider
 }
 
 func (h *AuthHandler) buildFromUseCaseProvider(provider apptypes.Provider) (string, error) {
+	if slot, ok := provider.OIDCSlot(); ok {
+		return h.oidcProviderNames[slot], nil
+	}
+
 	switch provider {
 	case apptypes.ProviderAnonymous:
 		return providerAnonymous, nil
@@ -378,5 +516,6 @@ func (h *AuthHandler) buildUserInfo(user *apptypes.User) (*userInfo, error) {
 		Email:     user.Email,
 		AvatarURL: user.AvatarURL,
 		Provider:  responseProvider,
+		IsAdmin:   user.IsAdmin,
 	}, nil
 }
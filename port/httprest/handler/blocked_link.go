@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/truewebber/link-shortener/domain/link"
+)
+
+// blockedLinkPage names the destination without linking to it, the visitor has to copy it on purpose.
+var blockedLinkPage = template.Must(template.New("blocked").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Warning: this link has been blocked</title>
+<style>
+body{font-family:system-ui,sans-serif;max-width:36rem;margin:4rem auto;padding:0 1rem;color:#222}
+h1{color:#b00020;font-size:1.5rem}
+code{display:block;padding:.75rem;background:#f4f4f4;word-break:break-all}
+</style>
+</head>
+<body>
+<h1>This link has been blocked</h1>
+<p>The short link you followed points to a destination that was reported as harmful: {{.Reason}}.</p>
+<p>We stopped the redirect to keep you safe. The destination was:</p>
+<code>{{.URL}}</code>
+<p>If you do not trust where this link came from, close this page.</p>
+</body>
+</html>
+`))
+
+type blockedLinkPageData struct {
+	Reason string
+	URL    string
+}
+
+func (h *LinkHandler) writeBlockedLinkPage(w http.ResponseWriter, l *link.Link) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusForbidden)
+
+	data := blockedLinkPageData{
+		Reason: l.BlockedReason,
+		URL:    l.RedirectURL,
+	}
+
+	if err := blockedLinkPage.Execute(w, data); err != nil {
+		h.logger.Error("failed to render blocked link page", "link_id", l.ID, "error", err)
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/app"
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+)
+
+type CaptchaHandler struct {
+	logger log.Logger
+	app    *app.APIApp
+}
+
+func NewCaptchaHandler(app *app.APIApp, logger log.Logger) *CaptchaHandler {
+	return &CaptchaHandler{
+		logger: logger,
+		app:    app,
+	}
+}
+
+// CaptchaChallengeResponse is the challenge format the ALTCHA widget expects.
+type CaptchaChallengeResponse struct {
+	Algorithm string `json:"algorithm"`
+	Challenge string `json:"challenge"`
+	Salt      string `json:"salt"`
+	Signature string `json:"signature"`
+	MaxNumber uint64 `json:"maxnumber"`
+}
+
+func (h *CaptchaHandler) Challenge(w http.ResponseWriter, r *http.Request) {
+	challenge, err := h.app.Query.IssueCaptchaChallenge.Handle(r.Context())
+	if errors.Is(err, apperrors.ErrCaptchaChallengeNotSupported) {
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		h.logger.Error("failed to issue captcha challenge", "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	resp := CaptchaChallengeResponse{
+		Algorithm: challenge.Algorithm,
+		Challenge: challenge.Challenge,
+		Salt:      challenge.Salt,
+		Signature: challenge.Signature,
+		MaxNumber: challenge.MaxNumber,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if encodeErr := json.NewEncoder(w).Encode(resp); encodeErr != nil {
+		h.logger.Error("failed to encode response", "response", resp, "error", encodeErr)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/truewebber/link-shortener/app/command"
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	apptypes "github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/port/httprest/context"
+)
+
+type IdentityResponse struct {
+	Provider    string `json:"provider"`
+	Email       string `json:"email"`
+	Name        string `json:"name"`
+	AvatarURL   string `json:"avatar_url"`
+	ID          uint64 `json:"id"`
+	CreatedAtMS int64  `json:"created_at_ms"`
+}
+
+type ListIdentitiesResponse struct {
+	Identities []IdentityResponse `json:"identities"`
+}
+
+type LinkIdentityResponse struct {
+	URL string `json:"url"`
+}
+
+// LinkIdentity starts OAuth with another provider, the callback attaches it to the signed in user.
+func (h *AuthHandler) LinkIdentity(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	authURL, err := h.beginOAuth(w, r)
+	if errors.Is(err, errUnsupportedProvider) || errors.Is(err, apperrors.ErrProviderNotFound) {
+		http.Error(w, "unknown provider", http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		h.logger.Error("failed to get auth url", "provider", mux.Vars(r)["provider"], "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	linkNonce, err := h.app.Command.StartIdentityLink.Handle(r.Context(), user.ID)
+	if err != nil {
+		h.logger.Error("failed to start identity link", "user_id", user.ID, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	http.SetCookie(w, h.buildOAuthCookie(linkCookieName, linkNonce))
+
+	resp := LinkIdentityResponse{URL: authURL}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if encodeErr := json.NewEncoder(w).Encode(resp); encodeErr != nil {
+		h.logger.Error("failed to encode response", "response", resp, "error", encodeErr)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+func (h *AuthHandler) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	identities, err := h.app.Query.ListIdentities.Handle(r.Context(), user.ID)
+	if err != nil {
+		h.logger.Error("failed to list identities", "user_id", user.ID, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	resp := ListIdentitiesResponse{
+		Identities: make([]IdentityResponse, 0, len(identities)),
+	}
+
+	for i := range identities {
+		identity, buildErr := h.buildIdentityResponse(&identities[i])
+		if buildErr != nil {
+			h.logger.Error("failed to build identity response", "user_id", user.ID, "error", buildErr)
+			http.Error(w, "internal", http.StatusInternalServerError)
+
+			return
+		}
+
+		resp.Identities = append(resp.Identities, identity)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if encodeErr := json.NewEncoder(w).Encode(resp); encodeErr != nil {
+		h.logger.Error("failed to encode response", "response", resp, "error", encodeErr)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+func (h *AuthHandler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], decimalBase, uint64BitSize)
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	}
+
+	params := command.UnlinkIdentityParams{
+		ID:     id,
+		UserID: user.ID,
+	}
+
+	err = h.app.Command.UnlinkIdentity.Handle(r.Context(), params)
+	if errors.Is(err, apperrors.ErrIdentityNotFound) {
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	}
+
+	if errors.Is(err, apperrors.ErrLastIdentity) {
+		http.Error(w, "the last identity can not be unlinked", http.StatusConflict)
+
+		return
+	}
+
+	if err != nil {
+		h.logger.Error("failed to unlink identity", "params", params, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) buildIdentityResponse(identity *apptypes.Identity) (IdentityResponse, error) {
+	provider, err := h.buildFromUseCaseProvider(identity.Provider)
+	if err != nil {
+		return IdentityResponse{}, fmt.Errorf("build provider: %w", err)
+	}
+
+	return IdentityResponse{
+		Provider:    provider,
+		Email:       identity.Email,
+		Name:        identity.Name,
+		AvatarURL:   identity.AvatarURL,
+		ID:          identity.ID,
+		CreatedAtMS: identity.CreatedAt.UnixMilli(),
+	}, nil
+}
@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/truewebber/gopkg/log"
 
 	"github.com/truewebber/link-shortener/app"
 	"github.com/truewebber/link-shortener/app/command"
+	apperrors "github.com/truewebber/link-shortener/app/errors"
 	"github.com/truewebber/link-shortener/app/query"
 	apptypes "github.com/truewebber/link-shortener/app/types"
 	"github.com/truewebber/link-shortener/domain/link"
@@ -37,8 +40,9 @@ func NewLinkHandler(
 }
 
 type CreateLinkRequest struct {
-	URL string `json:"url"`
-	TTL string `json:"ttl"`
+	URL   string `json:"url"`
+	TTL   string `json:"ttl"`
+	Alias string `json:"alias,omitempty"`
 }
 
 type CreateLinkResponse struct {
@@ -55,6 +59,12 @@ func (h *LinkHandler) CreateAnonymousLink(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if req.Alias != "" {
+		http.Error(w, "custom alias requires authorization", http.StatusBadRequest)
+
+		return
+	}
+
 	params := &command.CreateLinkParams{
 		UserID:      apptypes.AnonymousUser().ID,
 		RedirectURL: req.URL,
@@ -63,12 +73,67 @@ func (h *LinkHandler) CreateAnonymousLink(w http.ResponseWriter, r *http.Request
 
 	hash, err := h.app.Command.CreateLink.Handle(r.Context(), params)
 	if err != nil {
-		h.logger.Error("failed to create link", "params", params, "error", err)
-		http.Error(w, "internal", http.StatusInternalServerError)
+		h.writeCreateLinkError(w, params, err)
+
+		return
+	}
+
+	h.writeCreatedLink(w, hash)
+}
+
+func (h *LinkHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
 
 		return
 	}
 
+	req := &CreateLinkRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		h.logger.Error("failed to decode request", "error", err)
+		http.Error(w, "invalid request", http.StatusBadRequest)
+
+		return
+	}
+
+	params, err := h.buildCreateLinkParams(req, user)
+	if err != nil {
+		h.logger.Error("failed to build create link params", "error", err)
+		http.Error(w, "invalid request", http.StatusBadRequest)
+
+		return
+	}
+
+	hash, err := h.app.Command.CreateLink.Handle(r.Context(), params)
+	if err != nil {
+		h.writeCreateLinkError(w, params, err)
+
+		return
+	}
+
+	h.writeCreatedLink(w, hash)
+}
+
+// writeCreateLinkError answers an unsafe destination with 422, other validation errors with 400.
+// The reason a destination is unsafe is only logged, it would tell which blocklist rule to get around.
+func (h *LinkHandler) writeCreateLinkError(w http.ResponseWriter, params *command.CreateLinkParams, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrUnsafeURL):
+		h.logger.Info("refused unsafe destination", "redirect_url", params.RedirectURL, "error", err)
+		http.Error(w, "unsafe destination url", http.StatusUnprocessableEntity)
+	case errors.Is(err, command.ErrValidation):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, apperrors.ErrAliasAlreadyExists):
+		http.Error(w, "alias already taken", http.StatusConflict)
+	default:
+		h.logger.Error("failed to create link", "params", params, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+	}
+}
+
+func (h *LinkHandler) writeCreatedLink(w http.ResponseWriter, hash string) {
 	resp := CreateLinkResponse{
 		ShortURL: h.buildShortenURL(hash).String(),
 	}
@@ -84,7 +149,25 @@ func (h *LinkHandler) CreateAnonymousLink(w http.ResponseWriter, r *http.Request
 	}
 }
 
-func (h *LinkHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
+type LinkResponse struct {
+	ExpiresAtMS   *int64 `json:"expires_at_ms,omitempty"`
+	BlockedAtMS   *int64 `json:"blocked_at_ms,omitempty"`
+	ShortURL      string `json:"short_url"`
+	Hash          string `json:"hash"`
+	URL           string `json:"url"`
+	TTL           string `json:"ttl"`
+	BlockedReason string `json:"blocked_reason,omitempty"`
+	CreatedAtMS   int64  `json:"created_at_ms"`
+}
+
+type ListLinksResponse struct {
+	Links  []LinkResponse `json:"links"`
+	Total  uint32         `json:"total"`
+	Limit  uint32         `json:"limit"`
+	Offset uint32         `json:"offset"`
+}
+
+func (h *LinkHandler) ListLinks(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
 	if !ok {
 		http.Error(w, "authorization required", http.StatusUnauthorized)
@@ -92,7 +175,52 @@ func (h *LinkHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	req := &CreateLinkRequest{}
+	params, err := h.buildListUserLinksParams(r.URL.Query(), user)
+	if err != nil {
+		http.Error(w, "invalid pagination", http.StatusBadRequest)
+
+		return
+	}
+
+	list, err := h.app.Query.ListUserLinks.Handle(r.Context(), params)
+	if err != nil {
+		h.logger.Error("failed to list links", "params", params, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	resp, err := h.buildListLinksResponse(list, params)
+	if err != nil {
+		h.logger.Error("failed to build list links response", "params", params, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if encodeErr := json.NewEncoder(w).Encode(resp); encodeErr != nil {
+		h.logger.Error("failed to encode response", "response", resp, "error", encodeErr)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+type UpdateLinkRequest struct {
+	TTL string `json:"ttl"`
+}
+
+func (h *LinkHandler) UpdateLink(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	req := &UpdateLinkRequest{}
 
 	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
 		h.logger.Error("failed to decode request", "error", err)
@@ -101,28 +229,181 @@ func (h *LinkHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	params, err := h.buildCreateLinkParams(req, user)
+	expiresType, err := h.buildExpiresType(req.TTL)
 	if err != nil {
-		h.logger.Error("failed to build create link params", "error", err)
-		http.Error(w, "invalid request", http.StatusBadRequest)
+		http.Error(w, "invalid ttl", http.StatusBadRequest)
+
+		return
+	}
+
+	params := command.UpdateLinkTTLParams{
+		Hash:        mux.Vars(r)["hash"],
+		UserID:      user.ID,
+		ExpiresType: expiresType,
+	}
+
+	l, err := h.app.Command.UpdateLinkTTL.Handle(r.Context(), params)
+	if errors.Is(err, apperrors.ErrLinkNotFound) {
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	}
+
+	if errors.Is(err, command.ErrValidation) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 
 		return
 	}
 
-	hash, err := h.app.Command.CreateLink.Handle(r.Context(), params)
 	if err != nil {
-		h.logger.Error("failed to create link", "params", params, "error", err)
+		h.logger.Error("failed to update link ttl", "params", params, "error", err)
 		http.Error(w, "internal", http.StatusInternalServerError)
 
 		return
 	}
 
-	resp := CreateLinkResponse{
-		ShortURL: h.buildShortenURL(hash).String(),
+	h.writeLink(w, l)
+}
+
+func (h *LinkHandler) DeleteLink(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	params := command.DeleteLinkParams{
+		Hash:   mux.Vars(r)["hash"],
+		UserID: user.ID,
+	}
+
+	err := h.app.Command.DeleteLink.Handle(r.Context(), params)
+	if errors.Is(err, apperrors.ErrLinkNotFound) {
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		h.logger.Error("failed to delete link", "params", params, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *LinkHandler) RestoreLink(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	params := command.RestoreLinkParams{
+		Hash:   mux.Vars(r)["hash"],
+		UserID: user.ID,
+	}
+
+	l, err := h.app.Command.RestoreLink.Handle(r.Context(), params)
+
+	switch {
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	case errors.Is(err, apperrors.ErrLinkNotRestorable):
+		http.Error(w, "restore period is over", http.StatusGone)
+
+		return
+	case errors.Is(err, apperrors.ErrLinkAlreadyExists):
+		http.Error(w, "link with the same url already exists", http.StatusConflict)
+
+		return
+	case errors.Is(err, apperrors.ErrAliasAlreadyExists):
+		http.Error(w, "alias already taken", http.StatusConflict)
+
+		return
+	case err != nil:
+		h.logger.Error("failed to restore link", "params", params, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	h.writeLink(w, l)
+}
+
+func (h *LinkHandler) writeLink(w http.ResponseWriter, l *apptypes.Link) {
+	resp, err := h.buildLinkResponse(l)
+	if err != nil {
+		h.logger.Error("failed to build link response", "link", l, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+
+	if encodeErr := json.NewEncoder(w).Encode(resp); encodeErr != nil {
+		h.logger.Error("failed to encode response", "response", resp, "error", encodeErr)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+type DailyClicksResponse struct {
+	Date           string `json:"date"`
+	Clicks         uint64 `json:"clicks"`
+	UniqueVisitors uint64 `json:"unique_visitors"`
+}
+
+type LinkStatsResponse struct {
+	LastVisitAtMS *int64                `json:"last_visit_at_ms,omitempty"`
+	Daily         []DailyClicksResponse `json:"daily"`
+	TotalClicks   uint64                `json:"total_clicks"`
+}
+
+func (h *LinkHandler) LinkStats(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	params, err := h.buildGetLinkStatsParams(r.URL.Query(), mux.Vars(r)["hash"], user)
+	if err != nil {
+		http.Error(w, "invalid date range", http.StatusBadRequest)
+
+		return
+	}
+
+	linkStats, err := h.app.Query.GetLinkStats.Handle(r.Context(), params)
+
+	switch {
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	case errors.Is(err, apperrors.ErrInvalidDateRange):
+		http.Error(w, "invalid date range", http.StatusBadRequest)
+
+		return
+	case err != nil:
+		h.logger.Error("failed to get link stats", "params", params, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	resp := h.buildLinkStatsResponse(linkStats)
+
+	w.Header().Set("Content-Type", "application/json")
 
 	if encodeErr := json.NewEncoder(w).Encode(resp); encodeErr != nil {
 		h.logger.Error("failed to encode response", "response", resp, "error", encodeErr)
@@ -155,6 +436,18 @@ func (h *LinkHandler) Redirect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if l.IsBlocked() {
+		h.writeBlockedLinkPage(w, l)
+
+		return
+	}
+
+	h.app.Command.RecordVisit.Handle(command.RecordVisitParams{
+		LinkID:    l.ID,
+		ClientIP:  requestClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+
 	http.Redirect(w, r, l.RedirectURL, http.StatusFound)
 }
 
@@ -192,6 +485,191 @@ func (h *LinkHandler) buildExpiresType(ttl string) (link.ExpiresType, error) {
 	return 0, errUnknownLinkExpiresType
 }
 
+func (h *LinkHandler) buildTTL(expiresType link.ExpiresType) (string, error) {
+	switch expiresType {
+	case link.ExpiresType3Months:
+		return linkExpiresType3Months, nil
+	case link.ExpiresType6Months:
+		return linkExpiresType6Months, nil
+	case link.ExpiresType12Months:
+		return linkExpiresType12Months, nil
+	case link.ExpiresTypeNever:
+		return linkExpiresTypeNever, nil
+	}
+
+	return "", errUnknownLinkExpiresType
+}
+
+const (
+	defaultListLinksLimit = 20
+	maxListLinksLimit     = 100
+	uint32BitSize         = 32
+)
+
+var errInvalidPagination = errors.New("invalid pagination")
+
+func (h *LinkHandler) buildListUserLinksParams(
+	values url.Values, user *apptypes.User,
+) (query.ListUserLinksParams, error) {
+	limit, offset, err := parsePagination(values)
+	if err != nil {
+		return query.ListUserLinksParams{}, err
+	}
+
+	return query.ListUserLinksParams{
+		UserID: user.ID,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+func parsePagination(values url.Values) (limit, offset uint32, err error) {
+	limit = defaultListLinksLimit
+
+	if rawLimit := values.Get("limit"); rawLimit != "" {
+		parsed, parseErr := strconv.ParseUint(rawLimit, decimalBase, uint32BitSize)
+		if parseErr != nil {
+			return 0, 0, fmt.Errorf("parse limit: %w", parseErr)
+		}
+
+		if parsed == 0 || parsed > maxListLinksLimit {
+			return 0, 0, fmt.Errorf("%w: limit %d", errInvalidPagination, parsed)
+		}
+
+		limit = uint32(parsed)
+	}
+
+	if rawOffset := values.Get("offset"); rawOffset != "" {
+		parsed, parseErr := strconv.ParseUint(rawOffset, decimalBase, uint32BitSize)
+		if parseErr != nil {
+			return 0, 0, fmt.Errorf("parse offset: %w", parseErr)
+		}
+
+		offset = uint32(parsed)
+	}
+
+	return limit, offset, nil
+}
+
+func (h *LinkHandler) buildLinkResponse(l *apptypes.Link) (LinkResponse, error) {
+	ttl, err := h.buildTTL(l.ExpiresType)
+	if err != nil {
+		return LinkResponse{}, fmt.Errorf("build ttl: %w", err)
+	}
+
+	resp := LinkResponse{
+		ShortURL:      h.buildShortenURL(l.Hash).String(),
+		Hash:          l.Hash,
+		URL:           l.RedirectURL,
+		TTL:           ttl,
+		BlockedReason: l.BlockedReason,
+		CreatedAtMS:   l.CreatedAt.UnixMilli(),
+	}
+
+	if l.ExpiresAt != nil {
+		expiresAtMS := l.ExpiresAt.UnixMilli()
+		resp.ExpiresAtMS = &expiresAtMS
+	}
+
+	if l.BlockedAt != nil {
+		blockedAtMS := l.BlockedAt.UnixMilli()
+		resp.BlockedAtMS = &blockedAtMS
+	}
+
+	return resp, nil
+}
+
+func (h *LinkHandler) buildListLinksResponse(
+	list *apptypes.LinkList, params query.ListUserLinksParams,
+) (*ListLinksResponse, error) {
+	links := make([]LinkResponse, 0, len(list.Links))
+
+	for i := range list.Links {
+		resp, err := h.buildLinkResponse(&list.Links[i])
+		if err != nil {
+			return nil, fmt.Errorf("build link response: %w", err)
+		}
+
+		links = append(links, resp)
+	}
+
+	return &ListLinksResponse{
+		Links:  links,
+		Total:  list.Count,
+		Limit:  params.Limit,
+		Offset: params.Offset,
+	}, nil
+}
+
+const defaultStatsDays = 30
+
+func (h *LinkHandler) buildGetLinkStatsParams(
+	values url.Values, hash string, user *apptypes.User,
+) (query.GetLinkStatsParams, error) {
+	to := time.Now().UTC()
+
+	if rawTo := values.Get("to"); rawTo != "" {
+		parsed, err := time.Parse(time.DateOnly, rawTo)
+		if err != nil {
+			return query.GetLinkStatsParams{}, fmt.Errorf("parse to: %w", err)
+		}
+
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -(defaultStatsDays - 1))
+
+	if rawFrom := values.Get("from"); rawFrom != "" {
+		parsed, err := time.Parse(time.DateOnly, rawFrom)
+		if err != nil {
+			return query.GetLinkStatsParams{}, fmt.Errorf("parse from: %w", err)
+		}
+
+		from = parsed
+	}
+
+	return query.GetLinkStatsParams{
+		From:   from,
+		To:     to,
+		Hash:   hash,
+		UserID: user.ID,
+	}, nil
+}
+
+func (h *LinkHandler) buildLinkStatsResponse(linkStats *query.LinkStats) *LinkStatsResponse {
+	daily := make([]DailyClicksResponse, 0, len(linkStats.Daily))
+
+	for _, d := range linkStats.Daily {
+		daily = append(daily, DailyClicksResponse{
+			Date:           d.Day.Format(time.DateOnly),
+			Clicks:         d.Clicks,
+			UniqueVisitors: d.UniqueVisitors,
+		})
+	}
+
+	resp := &LinkStatsResponse{
+		Daily:       daily,
+		TotalClicks: linkStats.TotalClicks,
+	}
+
+	if linkStats.LastVisitAt != nil {
+		lastVisitAtMS := linkStats.LastVisitAt.UnixMilli()
+		resp.LastVisitAtMS = &lastVisitAtMS
+	}
+
+	return resp
+}
+
+// requestClientIP returns the address resolved by the ClientIP middleware, client headers are never read here.
+func requestClientIP(r *http.Request) string {
+	ip, ok := r.Context().Value(context.KeyClientIP).(string)
+	if !ok {
+		return ""
+	}
+
+	return ip
+}
+
 func (h *LinkHandler) buildCreateLinkParams(
 	req *CreateLinkRequest, user *apptypes.User,
 ) (*command.CreateLinkParams, error) {
@@ -203,6 +681,7 @@ func (h *LinkHandler) buildCreateLinkParams(
 	return &command.CreateLinkParams{
 		UserID:      user.ID,
 		RedirectURL: req.URL,
+		Alias:       req.Alias,
 		ExpiresType: expiresType,
 	}, nil
 }
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/app"
+	"github.com/truewebber/link-shortener/app/command"
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	apptypes "github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/port/httprest/context"
+)
+
+type PersonalTokenHandler struct {
+	app    *app.APIApp
+	logger log.Logger
+}
+
+func NewPersonalTokenHandler(app *app.APIApp, logger log.Logger) *PersonalTokenHandler {
+	return &PersonalTokenHandler{
+		app:    app,
+		logger: logger,
+	}
+}
+
+type CreatePersonalTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays uint32   `json:"expires_in_days,omitempty"`
+}
+
+type PersonalTokenResponse struct {
+	ExpiresAtMS  *int64   `json:"expires_at_ms,omitempty"`
+	LastUsedAtMS *int64   `json:"last_used_at_ms,omitempty"`
+	Name         string   `json:"name"`
+	Token        string   `json:"token,omitempty"`
+	Scopes       []string `json:"scopes"`
+	ID           uint64   `json:"id"`
+	CreatedAtMS  int64    `json:"created_at_ms"`
+}
+
+type ListPersonalTokensResponse struct {
+	Tokens []PersonalTokenResponse `json:"tokens"`
+}
+
+func (h *PersonalTokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	req := &CreatePersonalTokenRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		h.logger.Error("failed to decode request", "error", err)
+		http.Error(w, "invalid request", http.StatusBadRequest)
+
+		return
+	}
+
+	params, err := h.buildCreatePersonalTokenParams(req, user)
+	if err != nil {
+		http.Error(w, "invalid expiry", http.StatusBadRequest)
+
+		return
+	}
+
+	token, err := h.app.Command.CreatePersonalToken.Handle(r.Context(), params)
+
+	switch {
+	case errors.Is(err, command.ErrValidation):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	case errors.Is(err, apperrors.ErrTooManyPersonalTokens):
+		http.Error(w, "too many tokens", http.StatusConflict)
+
+		return
+	case err != nil:
+		h.logger.Error("failed to create personal token", "user_id", user.ID, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	resp := h.buildPersonalTokenResponse(token)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if encodeErr := json.NewEncoder(w).Encode(resp); encodeErr != nil {
+		h.logger.Error("failed to encode response", "error", encodeErr)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+func (h *PersonalTokenHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	tokens, err := h.app.Query.ListPersonalTokens.Handle(r.Context(), user.ID)
+	if err != nil {
+		h.logger.Error("failed to list personal tokens", "user_id", user.ID, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	resp := ListPersonalTokensResponse{
+		Tokens: make([]PersonalTokenResponse, 0, len(tokens)),
+	}
+
+	for i := range tokens {
+		resp.Tokens = append(resp.Tokens, h.buildPersonalTokenResponse(&tokens[i]))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if encodeErr := json.NewEncoder(w).Encode(resp); encodeErr != nil {
+		h.logger.Error("failed to encode response", "response", resp, "error", encodeErr)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+func (h *PersonalTokenHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], decimalBase, uint64BitSize)
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	}
+
+	params := command.RevokePersonalTokenParams{
+		ID:     id,
+		UserID: user.ID,
+	}
+
+	err = h.app.Command.RevokePersonalToken.Handle(r.Context(), params)
+	if errors.Is(err, apperrors.ErrPersonalTokenNotFound) {
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		h.logger.Error("failed to revoke personal token", "params", params, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+const (
+	uint64BitSize              = 64
+	maxPersonalTokenExpiryDays = 3650
+)
+
+var errInvalidExpiry = errors.New("invalid expiry")
+
+func (h *PersonalTokenHandler) buildCreatePersonalTokenParams(
+	req *CreatePersonalTokenRequest, user *apptypes.User,
+) (command.CreatePersonalTokenParams, error) {
+	params := command.CreatePersonalTokenParams{
+		Name:   req.Name,
+		Scopes: req.Scopes,
+		UserID: user.ID,
+	}
+
+	if req.ExpiresInDays > maxPersonalTokenExpiryDays {
+		return command.CreatePersonalTokenParams{}, errInvalidExpiry
+	}
+
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, int(req.ExpiresInDays))
+		params.ExpiresAt = &expiresAt
+	}
+
+	return params, nil
+}
+
+func (h *PersonalTokenHandler) buildPersonalTokenResponse(token *apptypes.PersonalToken) PersonalTokenResponse {
+	resp := PersonalTokenResponse{
+		Name:        token.Name,
+		Token:       token.Secret,
+		Scopes:      token.Scopes,
+		ID:          token.ID,
+		CreatedAtMS: token.CreatedAt.UnixMilli(),
+	}
+
+	if token.ExpiresAt != nil {
+		expiresAtMS := token.ExpiresAt.UnixMilli()
+		resp.ExpiresAtMS = &expiresAtMS
+	}
+
+	if token.LastUsedAt != nil {
+		lastUsedAtMS := token.LastUsedAt.UnixMilli()
+		resp.LastUsedAtMS = &lastUsedAtMS
+	}
+
+	return resp
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/truewebber/link-shortener/app/command"
+	"github.com/truewebber/link-shortener/app/query"
+)
+
+type ReportLinkRequest struct {
+	Reason  string `json:"reason"`
+	Contact string `json:"contact"`
+}
+
+// ReportLink takes abuse reports from anyone holding the short link, reporters are told apart by client IP.
+func (h *LinkHandler) ReportLink(w http.ResponseWriter, r *http.Request) {
+	var req ReportLinkRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	l, err := h.app.Query.GetLinkByHash.Handle(r.Context(), query.GetLinkByHashParams{Hash: mux.Vars(r)["hash"]})
+	if err != nil {
+		http.Error(w, "not found or expired", http.StatusNotFound)
+
+		return
+	}
+
+	params := command.ReportLinkParams{
+		ClientIP: requestClientIP(r),
+		Reason:   req.Reason,
+		Contact:  req.Contact,
+		LinkID:   l.ID,
+	}
+
+	err = h.app.Command.ReportLink.Handle(r.Context(), params)
+	if errors.Is(err, command.ErrValidation) {
+		http.Error(w, "reason is required, contact is optional", http.StatusBadRequest)
+
+		return
+	}
+
+	if err != nil {
+		h.logger.Error("failed to report link", "link_id", l.ID, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/truewebber/link-shortener/app/command"
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+	"github.com/truewebber/link-shortener/app/query"
+	apptypes "github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/port/httprest/context"
+)
+
+type SessionResponse struct {
+	LastUsedAtMS *int64 `json:"last_used_at_ms,omitempty"`
+	UserAgent    string `json:"user_agent"`
+	IP           string `json:"ip"`
+	ID           uint64 `json:"id"`
+	CreatedAtMS  int64  `json:"created_at_ms"`
+	Current      bool   `json:"current"`
+}
+
+type ListSessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	accessToken, ok := r.Context().Value(context.KeyToken).(string)
+	if !ok {
+		http.Error(w, "authorization token required", http.StatusUnauthorized)
+
+		return
+	}
+
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	params := query.ListSessionsParams{
+		AccessToken: accessToken,
+		UserID:      user.ID,
+	}
+
+	sessions, err := h.app.Query.ListSessions.Handle(r.Context(), params)
+	if err != nil {
+		h.logger.Error("failed to list sessions", "user_id", user.ID, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	resp := ListSessionsResponse{
+		Sessions: make([]SessionResponse, 0, len(sessions)),
+	}
+
+	for i := range sessions {
+		resp.Sessions = append(resp.Sessions, h.buildSessionResponse(&sessions[i]))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if encodeErr := json.NewEncoder(w).Encode(resp); encodeErr != nil {
+		h.logger.Error("failed to encode response", "response", resp, "error", encodeErr)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], decimalBase, uint64BitSize)
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	}
+
+	params := command.RevokeSessionParams{
+		ID:     id,
+		UserID: user.ID,
+	}
+
+	err = h.app.Command.RevokeSession.Handle(r.Context(), params)
+	if errors.Is(err, apperrors.ErrSessionNotFound) {
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		h.logger.Error("failed to revoke session", "params", params, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) LogoutEverywhere(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(context.KeyUser).(*apptypes.User)
+	if !ok {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+
+		return
+	}
+
+	if err := h.app.Command.LogoutEverywhere.Handle(r.Context(), user.ID); err != nil {
+		h.logger.Error("failed to logout everywhere", "user_id", user.ID, "error", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) buildSessionResponse(session *apptypes.Session) SessionResponse {
+	resp := SessionResponse{
+		UserAgent:   session.UserAgent,
+		IP:          session.IP,
+		ID:          session.ID,
+		CreatedAtMS: session.CreatedAt.UnixMilli(),
+		Current:     session.Current,
+	}
+
+	if session.LastUsedAt != nil {
+		lastUsedAtMS := session.LastUsedAt.UnixMilli()
+		resp.LastUsedAtMS = &lastUsedAtMS
+	}
+
+	return resp
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	apptypes "github.com/truewebber/link-shortener/app/types"
+	httpcontext "github.com/truewebber/link-shortener/port/httprest/context"
+)
+
+// RequireAdmin goes after Auth, the role is read from the user it put into the context.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := r.Context().Value(httpcontext.KeyUser).(*apptypes.User)
+		if !ok || !user.IsAdmin {
+			http.Error(w, "admin role required", http.StatusForbidden)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
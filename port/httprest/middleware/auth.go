@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -10,10 +11,15 @@ import (
 
 	apperrors "github.com/truewebber/link-shortener/app/errors"
 	"github.com/truewebber/link-shortener/app/query"
+	apptypes "github.com/truewebber/link-shortener/app/types"
 	httpcontext "github.com/truewebber/link-shortener/port/httprest/context"
 )
 
-func Auth(authUser *query.AuthUserHandler, logger log.Logger) func(http.Handler) http.Handler {
+func Auth(
+	authUser *query.AuthUserHandler,
+	authPersonalToken *query.AuthPersonalTokenHandler,
+	logger log.Logger,
+) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			token := extractToken(r)
@@ -23,7 +29,7 @@ func Auth(authUser *query.AuthUserHandler, logger log.Logger) func(http.Handler)
 				return
 			}
 
-			user, err := authUser.Handle(r.Context(), token)
+			outboundCtx, err := authenticate(r.Context(), authUser, authPersonalToken, token)
 			if errors.Is(err, apperrors.ErrInvalidCredentials) ||
 				errors.Is(err, apperrors.ErrTokenExpired) ||
 				errors.Is(err, apperrors.ErrUserNotFound) {
@@ -32,6 +38,12 @@ func Auth(authUser *query.AuthUserHandler, logger log.Logger) func(http.Handler)
 				return
 			}
 
+			if errors.Is(err, apperrors.ErrUserBanned) {
+				http.Error(w, "account banned", http.StatusForbidden)
+
+				return
+			}
+
 			if err != nil {
 				logger.Error("access token verification failed", "token", token, "error", err)
 
@@ -40,14 +52,39 @@ func Auth(authUser *query.AuthUserHandler, logger log.Logger) func(http.Handler)
 				return
 			}
 
-			outboundCtx := context.WithValue(r.Context(), httpcontext.KeyUser, user)
-			outboundCtx = context.WithValue(outboundCtx, httpcontext.KeyToken, token)
-
 			next.ServeHTTP(w, r.WithContext(outboundCtx))
 		})
 	}
 }
 
+// authenticate puts scopes into the context only for personal access tokens, session tokens are not limited.
+func authenticate(
+	ctx context.Context,
+	authUser *query.AuthUserHandler,
+	authPersonalToken *query.AuthPersonalTokenHandler,
+	token string,
+) (context.Context, error) {
+	outboundCtx := context.WithValue(ctx, httpcontext.KeyToken, token)
+
+	if !apptypes.IsPersonalTokenSecret(token) {
+		user, err := authUser.Handle(ctx, token)
+		if err != nil {
+			return nil, fmt.Errorf("auth user: %w", err)
+		}
+
+		return context.WithValue(outboundCtx, httpcontext.KeyUser, user), nil
+	}
+
+	auth, err := authPersonalToken.Handle(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("auth personal token: %w", err)
+	}
+
+	outboundCtx = context.WithValue(outboundCtx, httpcontext.KeyUser, auth.User)
+
+	return context.WithValue(outboundCtx, httpcontext.KeyScopes, auth.Scopes), nil
+}
+
 func OptionalAuth(authUser *query.AuthUserHandler, logger log.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -66,7 +103,8 @@ func OptionalAuth(authUser *query.AuthUserHandler, logger log.Logger) func(http.
 			if err != nil {
 				if !errors.Is(err, apperrors.ErrInvalidCredentials) &&
 					!errors.Is(err, apperrors.ErrTokenExpired) &&
-					!errors.Is(err, apperrors.ErrUserNotFound) {
+					!errors.Is(err, apperrors.ErrUserNotFound) &&
+					!errors.Is(err, apperrors.ErrUserBanned) {
 					logger.Error("access token verification failed", "token", token, "error", err)
 				}
 
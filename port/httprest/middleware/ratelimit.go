@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/truewebber/link-shortener/app/command"
+	apptypes "github.com/truewebber/link-shortener/app/types"
+	httpcontext "github.com/truewebber/link-shortener/port/httprest/context"
+)
+
+const (
+	forwardedForHeader = "X-Forwarded-For"
+	retryAfterHeader   = "Retry-After"
+	decimalBase        = 10
+)
+
+// ClientIP puts the client address into the context, X-Forwarded-For is only
+// believed as far as the hops that appended to it are trusted proxies.
+func ClientIP(trustedProxies []netip.Prefix) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolveClientIP(r, trustedProxies)
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), httpcontext.KeyClientIP, ip)))
+		})
+	}
+}
+
+func resolveClientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if !isTrustedProxy(host, trustedProxies) {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values(forwardedForHeader), ","), ",")
+
+	// the rightmost hop was appended by the closest proxy, the ones left of it can be forged
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+
+		if !isTrustedProxy(hop, trustedProxies) {
+			return hop
+		}
+
+		host = hop
+	}
+
+	return host
+}
+
+func isTrustedProxy(host string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RateLimit keys signed in users by their id and everyone else by client IP, IPv6 clients by their /64,
+// so it goes after Auth on routes that require a user and after ClientIP everywhere.
+func RateLimit(takeRateLimit *command.TakeRateLimitHandler, class apptypes.RateLimitClass) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result := takeRateLimit.Handle(r.Context(), command.TakeRateLimitParams{
+				Class: class,
+				Key:   rateLimitKey(r),
+			})
+
+			if !result.Allowed {
+				retryAfter := int64(math.Ceil(result.RetryAfter.Seconds()))
+				w.Header().Set(retryAfterHeader, strconv.FormatInt(max(retryAfter, 1), decimalBase))
+				http.Error(w, "too many requests", http.StatusTooManyRequests)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func rateLimitKey(r *http.Request) string {
+	if user, ok := r.Context().Value(httpcontext.KeyUser).(*apptypes.User); ok {
+		return "user:" + strconv.FormatUint(user.ID, decimalBase)
+	}
+
+	ip, ok := r.Context().Value(httpcontext.KeyClientIP).(string)
+	if !ok {
+		ip = r.RemoteAddr
+	}
+
+	return "ip:" + apptypes.ClientNetwork(ip)
+}
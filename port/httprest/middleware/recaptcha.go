@@ -10,12 +10,20 @@ import (
 	apperrors "github.com/truewebber/link-shortener/app/errors"
 )
 
-const captchaHeader = "X-Recaptcha-Token"
+const (
+	captchaHeader = "X-Captcha-Token"
+	// legacyCaptchaHeader is still sent by clients built for reCAPTCHA only.
+	legacyCaptchaHeader = "X-Recaptcha-Token"
+)
 
 func ValidateCaptcha(validator *command.ValidateCaptchaHandler, logger log.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			captchaToken := r.Header.Get(captchaHeader)
+			if captchaToken == "" {
+				captchaToken = r.Header.Get(legacyCaptchaHeader)
+			}
+
 			if captchaToken == "" {
 				logger.Error("captcha header not found")
 
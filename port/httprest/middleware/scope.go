@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	httpcontext "github.com/truewebber/link-shortener/port/httprest/context"
+)
+
+// RequireScope lets session tokens through, personal access tokens must carry the scope.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes, isPersonalToken := r.Context().Value(httpcontext.KeyScopes).([]string)
+			if isPersonalToken && !slices.Contains(scopes, scope) {
+				http.Error(w, "insufficient token scope", http.StatusForbidden)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SessionOnly keeps personal access tokens away from account management.
+func SessionOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, isPersonalToken := r.Context().Value(httpcontext.KeyScopes).([]string); isPersonalToken {
+			http.Error(w, "personal access tokens are not allowed here", http.StatusForbidden)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -1,7 +1,11 @@
 package httprest
 
 import (
+	"context"
 	"net/http"
+	"net/netip"
+	"regexp"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/truewebber/gopkg/log"
@@ -9,17 +13,28 @@ import (
 
 	"github.com/truewebber/link-shortener/app/command"
 	"github.com/truewebber/link-shortener/app/query"
+	apptypes "github.com/truewebber/link-shortener/app/types"
 	"github.com/truewebber/link-shortener/port/httprest/handler"
 	"github.com/truewebber/link-shortener/port/httprest/middleware"
 )
 
+// hashPathVariable matches derived hashes, random codes and custom aliases alike.
+const hashPathVariable = "{hash:[0-9a-zA-Z_-]+}"
+
 func NewRouterHandler(
 	linkHandler *handler.LinkHandler,
 	authHandler *handler.AuthHandler,
+	personalTokenHandler *handler.PersonalTokenHandler,
+	accountHandler *handler.AccountHandler,
+	captchaHandler *handler.CaptchaHandler,
+	adminHandler *handler.AdminHandler,
 	healthHandler *handler.HealthHandler,
 	latencyRecorder metrics.LatencyRecorder,
 	authUser *query.AuthUserHandler,
+	authPersonalToken *query.AuthPersonalTokenHandler,
 	validateCaptcha *command.ValidateCaptchaHandler,
+	takeRateLimit *command.TakeRateLimitHandler,
+	trustedProxies []netip.Prefix,
 	logger log.Logger,
 ) http.Handler {
 	router := mux.NewRouter()
@@ -27,39 +42,119 @@ func NewRouterHandler(
 	router.Use(
 		middleware.Logging(logger),
 		middleware.Metrics(latencyRecorder),
+		middleware.ClientIP(trustedProxies),
 	)
 
 	// Public health check endpoint
 	router.HandleFunc("/health", healthHandler.Health).Methods(http.MethodGet)
 
+	publicAuthRouter := router.NewRoute().Subrouter()
+	publicAuthRouter.Use(middleware.RateLimit(takeRateLimit, apptypes.RateLimitClassAuth))
+
 	// Public auth endpoint
-	router.HandleFunc("/api/auth/refresh", authHandler.RefreshToken).Methods(http.MethodPost)
+	publicAuthRouter.HandleFunc("/api/auth/refresh", authHandler.RefreshToken).Methods(http.MethodPost)
 
 	// OAuth provider endpoints
-	router.HandleFunc(
-		"/api/auth/{provider:(?:google|apple|github)}",
-		authHandler.StartOAuth,
-	).Methods(http.MethodGet)
-	router.HandleFunc(
-		"/api/auth/{provider:(?:google|apple|github)}/callback",
-		authHandler.OAuthCallback,
-	)
+	publicAuthRouter.HandleFunc("/api/auth/providers", authHandler.Providers).Methods(http.MethodGet)
 
-	// Protected auth endpoints
-	authRouter := router.PathPrefix("/api").Subrouter()
-	authRouter.Use(middleware.Auth(authUser, logger))
-	authRouter.HandleFunc("/auth/logout", authHandler.Logout).Methods(http.MethodPost)
-	authRouter.HandleFunc("/auth/me", authHandler.Me).Methods(http.MethodGet)
+	if providerNames := authHandler.ProviderNames(context.Background()); len(providerNames) > 0 {
+		providerPath := "/api/auth/" + providerPathVariable(providerNames)
+		publicAuthRouter.HandleFunc(providerPath, authHandler.StartOAuth).Methods(http.MethodGet)
+		publicAuthRouter.HandleFunc(providerPath+"/callback", authHandler.OAuthCallback)
+	}
 
-	authRouter.HandleFunc("/urls", linkHandler.CreateLink).Methods(http.MethodPost)
+	publicAuthRouter.HandleFunc("/api/captcha/challenge", captchaHandler.Challenge).Methods(http.MethodGet)
 
-	// URL shortening endpoint for public usage
+	auth := middleware.Auth(authUser, authPersonalToken, logger)
+	apiRateLimit := middleware.RateLimit(takeRateLimit, apptypes.RateLimitClassAPI)
+
+	registerAccountRoutes(router, auth, apiRateLimit, authHandler, personalTokenHandler, accountHandler)
+	registerLinkRoutes(router, auth, apiRateLimit, linkHandler)
+	registerAdminRoutes(router, auth, apiRateLimit, adminHandler)
+
+	// URL shortening and abuse report endpoints for public usage, limited before the captcha is verified
 	captchaRouter := router.NewRoute().Subrouter()
-	captchaRouter.Use(middleware.ValidateCaptcha(validateCaptcha, logger))
+	captchaRouter.Use(
+		middleware.RateLimit(takeRateLimit, apptypes.RateLimitClassAnonymous),
+		middleware.ValidateCaptcha(validateCaptcha, logger),
+	)
 	captchaRouter.HandleFunc("/api/restricted_urls", linkHandler.CreateAnonymousLink).Methods(http.MethodPost)
+	captchaRouter.HandleFunc("/"+hashPathVariable+"/report", linkHandler.ReportLink).Methods(http.MethodPost)
 
 	// Redirect handler for shortened URLs
-	router.HandleFunc("/{hash:[0-9a-zA-Z]+}", linkHandler.Redirect).Methods(http.MethodGet)
+	redirectRouter := router.NewRoute().Subrouter()
+	redirectRouter.Use(middleware.RateLimit(takeRateLimit, apptypes.RateLimitClassRedirect))
+	redirectRouter.HandleFunc("/"+hashPathVariable, linkHandler.Redirect).Methods(http.MethodGet)
 
 	return router
 }
+
+// registerAccountRoutes registers endpoints available to browser sessions only.
+func registerAccountRoutes(
+	router *mux.Router,
+	auth, rateLimit mux.MiddlewareFunc,
+	authHandler *handler.AuthHandler,
+	personalTokenHandler *handler.PersonalTokenHandler,
+	accountHandler *handler.AccountHandler,
+) {
+	accountRouter := router.PathPrefix("/api/auth").Subrouter()
+	accountRouter.Use(auth, middleware.SessionOnly, rateLimit)
+	accountRouter.HandleFunc("/logout", authHandler.Logout).Methods(http.MethodPost)
+	accountRouter.HandleFunc("/me", authHandler.Me).Methods(http.MethodGet)
+	accountRouter.HandleFunc("/me", accountHandler.DeleteAccount).Methods(http.MethodDelete)
+	accountRouter.HandleFunc("/me/export", accountHandler.ExportAccount).Methods(http.MethodGet)
+
+	accountRouter.HandleFunc("/tokens", personalTokenHandler.CreateToken).Methods(http.MethodPost)
+	accountRouter.HandleFunc("/tokens", personalTokenHandler.ListTokens).Methods(http.MethodGet)
+	accountRouter.HandleFunc("/tokens/{id:[0-9]+}", personalTokenHandler.RevokeToken).Methods(http.MethodDelete)
+
+	accountRouter.HandleFunc("/sessions", authHandler.ListSessions).Methods(http.MethodGet)
+	accountRouter.HandleFunc("/sessions", authHandler.LogoutEverywhere).Methods(http.MethodDelete)
+	accountRouter.HandleFunc("/sessions/{id:[0-9]+}", authHandler.RevokeSession).Methods(http.MethodDelete)
+
+	accountRouter.HandleFunc("/identities", authHandler.ListIdentities).Methods(http.MethodGet)
+	accountRouter.HandleFunc("/identities/{id:[0-9]+}", authHandler.UnlinkIdentity).Methods(http.MethodDelete)
+
+	if providerNames := authHandler.ProviderNames(context.Background()); len(providerNames) > 0 {
+		identityPath := "/identities/" + providerPathVariable(providerNames)
+		accountRouter.HandleFunc(identityPath, authHandler.LinkIdentity).Methods(http.MethodPost)
+	}
+}
+
+// registerLinkRoutes registers endpoints open to personal access tokens with the matching scope.
+func registerLinkRoutes(router *mux.Router, auth, rateLimit mux.MiddlewareFunc, linkHandler *handler.LinkHandler) {
+	readRouter := router.PathPrefix("/api").Subrouter()
+	readRouter.Use(auth, middleware.RequireScope(apptypes.ScopeLinksRead), rateLimit)
+	readRouter.HandleFunc("/urls", linkHandler.ListLinks).Methods(http.MethodGet)
+	readRouter.HandleFunc("/urls/"+hashPathVariable+"/stats", linkHandler.LinkStats).Methods(http.MethodGet)
+
+	writeRouter := router.PathPrefix("/api").Subrouter()
+	writeRouter.Use(auth, middleware.RequireScope(apptypes.ScopeLinksWrite), rateLimit)
+	writeRouter.HandleFunc("/urls", linkHandler.CreateLink).Methods(http.MethodPost)
+	writeRouter.HandleFunc("/urls/"+hashPathVariable, linkHandler.UpdateLink).Methods(http.MethodPatch)
+	writeRouter.HandleFunc("/urls/"+hashPathVariable, linkHandler.DeleteLink).Methods(http.MethodDelete)
+	writeRouter.HandleFunc("/urls/"+hashPathVariable+"/restore", linkHandler.RestoreLink).Methods(http.MethodPost)
+}
+
+// registerAdminRoutes registers moderation endpoints, admins are signed in with a session like everyone else.
+func registerAdminRoutes(router *mux.Router, auth, rateLimit mux.MiddlewareFunc, adminHandler *handler.AdminHandler) {
+	adminRouter := router.PathPrefix("/api/admin").Subrouter()
+	adminRouter.Use(auth, middleware.SessionOnly, middleware.RequireAdmin, rateLimit)
+	adminRouter.HandleFunc("/links", adminHandler.SearchLinks).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/links/{id:[0-9]+}/disable", adminHandler.DisableLink).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/links/{id:[0-9]+}/enable", adminHandler.EnableLink).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/ban", adminHandler.BanUser).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/unban", adminHandler.UnbanUser).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/audit", adminHandler.ListModerationActions).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/reports", adminHandler.ListReports).Methods(http.MethodGet)
+}
+
+// providerPathVariable matches only the configured provider names.
+func providerPathVariable(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, regexp.QuoteMeta(name))
+	}
+
+	return "{provider:(?:" + strings.Join(quoted, "|") + ")}"
+}
@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	gokitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	nativeprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/app"
+	"github.com/truewebber/link-shortener/app/command"
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+)
+
+type Cleaner struct {
+	app       *app.CleanerApp
+	purged    gokitmetrics.Counter
+	logger    log.Logger
+	stop      chan struct{}
+	stopOnce  sync.Once
+	interval  time.Duration
+	batchSize uint32
+}
+
+const (
+	kindLabel             = "kind"
+	kindLinks             = "links"
+	kindTokens            = "tokens"
+	kindCaptchaChallenges = "captcha_challenges"
+	kindRateLimits        = "rate_limits"
+	kindLinkRequests      = "identity_link_requests"
+)
+
+func NewCleaner(
+	app *app.CleanerApp,
+	interval time.Duration,
+	batchSize uint32,
+	logger log.Logger,
+) *Cleaner {
+	return &Cleaner{
+		app:       app,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logger,
+		stop:      make(chan struct{}),
+		purged: gokitprometheus.NewCounterFrom(
+			nativeprometheus.CounterOpts{
+				Namespace: "truewebber",
+				Subsystem: "cleaner",
+				Name:      "purged_rows_total",
+				Help:      "Rows purged by the expiry cleaner.",
+			},
+			[]string{kindLabel},
+		),
+	}
+}
+
+func (c *Cleaner) Serve() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	go func() {
+		<-c.stop
+		cancel()
+	}()
+
+	for {
+		if err := c.RunOnce(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			c.logger.Error("clean expired failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Cleaner) Shutdown() error {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+
+	return nil
+}
+
+func (c *Cleaner) RunOnce(ctx context.Context) error {
+	start := time.Now()
+
+	result, err := c.app.Command.CleanExpired.Handle(ctx, command.CleanExpiredParams{
+		BatchSize: c.batchSize,
+	})
+
+	c.purged.With(kindLabel, kindLinks).Add(float64(result.Links))
+	c.purged.With(kindLabel, kindTokens).Add(float64(result.Tokens))
+	c.purged.With(kindLabel, kindCaptchaChallenges).Add(float64(result.CaptchaChallenges))
+	c.purged.With(kindLabel, kindRateLimits).Add(float64(result.RateLimits))
+	c.purged.With(kindLabel, kindLinkRequests).Add(float64(result.LinkRequests))
+
+	if errors.Is(err, apperrors.ErrLocked) {
+		c.logger.Info("clean expired skipped, another replica holds the lock")
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("clean expired: %w", err)
+	}
+
+	c.logger.Info(
+		"clean expired finished",
+		"links", result.Links,
+		"tokens", result.Tokens,
+		"captcha_challenges", result.CaptchaChallenges,
+		"rate_limits", result.RateLimits,
+		"identity_link_requests", result.LinkRequests,
+		"duration_seconds", time.Since(start).Seconds(),
+	)
+
+	return nil
+}
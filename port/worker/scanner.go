@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	gokitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	nativeprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/truewebber/gopkg/log"
+
+	"github.com/truewebber/link-shortener/app"
+	"github.com/truewebber/link-shortener/app/command"
+	apperrors "github.com/truewebber/link-shortener/app/errors"
+)
+
+// Scanner walks all live links every interval and blocks the ones that turned malicious.
+type Scanner struct {
+	app       *app.CleanerApp
+	links     gokitmetrics.Counter
+	logger    log.Logger
+	stop      chan struct{}
+	stopOnce  sync.Once
+	interval  time.Duration
+	batchSize uint32
+}
+
+const (
+	resultLabel   = "result"
+	resultScanned = "scanned"
+	resultBlocked = "blocked"
+	resultFailed  = "failed"
+)
+
+func NewScanner(
+	app *app.CleanerApp,
+	interval time.Duration,
+	batchSize uint32,
+	logger log.Logger,
+) *Scanner {
+	return &Scanner{
+		app:       app,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logger,
+		stop:      make(chan struct{}),
+		links: gokitprometheus.NewCounterFrom(
+			nativeprometheus.CounterOpts{
+				Namespace: "truewebber",
+				Subsystem: "scanner",
+				Name:      "links_total",
+				Help:      "Links rescanned against the safety blocklists, by result.",
+			},
+			[]string{resultLabel},
+		),
+	}
+}
+
+func (s *Scanner) Serve() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	go func() {
+		<-s.stop
+		cancel()
+	}()
+
+	for {
+		if err := s.RunOnce(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			s.logger.Error("rescan links failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scanner) Shutdown() error {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+
+	return nil
+}
+
+func (s *Scanner) RunOnce(ctx context.Context) error {
+	start := time.Now()
+
+	result, err := s.app.Command.RescanLinks.Handle(ctx, command.RescanLinksParams{
+		BatchSize: s.batchSize,
+	})
+
+	s.links.With(resultLabel, resultScanned).Add(float64(result.Scanned))
+	s.links.With(resultLabel, resultBlocked).Add(float64(result.Blocked))
+	s.links.With(resultLabel, resultFailed).Add(float64(result.Failed))
+
+	if errors.Is(err, apperrors.ErrLocked) {
+		s.logger.Info("rescan links skipped, another replica holds the lock")
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("rescan links: %w", err)
+	}
+
+	s.logger.Info(
+		"rescan links finished",
+		"scanned", result.Scanned,
+		"blocked", result.Blocked,
+		"failed", result.Failed,
+		"duration_seconds", time.Since(start).Seconds(),
+	)
+
+	return nil
+}
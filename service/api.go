@@ -2,87 +2,362 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	gokitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/jackc/pgx/v5/pgxpool"
+	nativeprometheus "github.com/prometheus/client_golang/prometheus"
 	"github.com/truewebber/gopkg/log"
+	"github.com/truewebber/gopkg/starter"
 
 	"github.com/truewebber/link-shortener/adapter"
 	"github.com/truewebber/link-shortener/app"
 	"github.com/truewebber/link-shortener/app/command"
+	"github.com/truewebber/link-shortener/app/linkhash"
 	"github.com/truewebber/link-shortener/app/query"
 	"github.com/truewebber/link-shortener/app/types"
+	"github.com/truewebber/link-shortener/domain/alias"
+	"github.com/truewebber/link-shortener/domain/captcha"
+	"github.com/truewebber/link-shortener/domain/hash"
+	"github.com/truewebber/link-shortener/domain/identity"
+	"github.com/truewebber/link-shortener/domain/link"
+	"github.com/truewebber/link-shortener/domain/moderation"
+	"github.com/truewebber/link-shortener/domain/pat"
+	"github.com/truewebber/link-shortener/domain/ratelimit"
+	"github.com/truewebber/link-shortener/domain/report"
+	"github.com/truewebber/link-shortener/domain/stats"
+	tokendomain "github.com/truewebber/link-shortener/domain/token"
 	userdomain "github.com/truewebber/link-shortener/domain/user"
 )
 
-func NewAPIApp(config *Config, logger log.Logger) *app.APIApp {
+func NewAPIApp(config *Config, logger log.Logger) (*app.APIApp, []starter.Server) {
 	pool := adapter.MustNewPgxPool(context.Background(), config.PostgresConnectionString)
+	s := buildStorages(pool, config)
 
-	hashGen := adapter.MustNewHashGenerator()
-	linkStorage := adapter.NewLinkStoragePgx(pool)
-	userStorage := adapter.NewUserStoragePgx(pool)
-	tokenStorage := adapter.NewTokenStoragePgx(pool)
+	statsRecorder := adapter.NewBufferedStatsRecorder(
+		s.stats, config.Stats.BufferSize, config.Stats.BatchSize, config.Stats.FlushInterval, logger,
+	)
+
+	safetyChecker := buildSafetyChecker(&config.Safety, logger)
 
 	oauthProviders := buildProviders(&config.OAuth, logger)
-	captchaValidator := adapter.NewGoogleCaptchaV3Validator(
-		config.GoogleCaptchaV3.Secret,
-		config.GoogleCaptchaV3.AllowedActions,
-		config.GoogleCaptchaV3.Threshold,
-		logger,
-	)
+	captchaValidator, challengeIssuer := buildCaptcha(&config.Captcha, s.captchaSpent, logger)
 
-	return &app.APIApp{
+	apiApp := &app.APIApp{
 		Command: app.APICommand{
-			CreateLink:      command.NewCreateLinkHandler(linkStorage, hashGen, logger),
-			FinishOAuth:     command.NewFinishOAuthHandler(userStorage, tokenStorage, oauthProviders, logger),
-			RefreshToken:    command.NewRefreshTokenHandler(userStorage, tokenStorage),
-			Logout:          command.NewLogoutHandler(userStorage, tokenStorage),
-			ValidateCaptcha: command.NewValidateCaptchaHandler(captchaValidator),
-		},
-		Query: app.APIQuery{
-			GetLinkByHash: query.NewGetLinkByHashHandler(linkStorage, hashGen, logger),
-			AuthUser:      query.NewAuthUserHandler(userStorage, tokenStorage),
-			GetAuthURL:    query.NewGetAuthURLHandler(oauthProviders),
+			CreateLink: command.NewCreateLinkHandler(
+				s.link, s.alias, s.hashResolver, s.codeGenerator, safetyChecker, logger,
+			),
+			DeleteLink:    command.NewDeleteLinkHandler(s.link, s.hashResolver),
+			RestoreLink:   command.NewRestoreLinkHandler(s.link, s.hashResolver),
+			UpdateLinkTTL: command.NewUpdateLinkTTLHandler(s.link, s.hashResolver),
+			FinishOAuth: command.NewFinishOAuthHandler(
+				s.user, s.identity, s.linkRequest, s.token, oauthProviders, logger,
+			),
+			RefreshToken:        command.NewRefreshTokenHandler(s.user, s.token, logger),
+			Logout:              command.NewLogoutHandler(s.user, s.token),
+			RecordVisit:         command.NewRecordVisitHandler(statsRecorder, []byte(config.ClientHashSecret)),
+			ValidateCaptcha:     command.NewValidateCaptchaHandler(captchaValidator),
+			CreatePersonalToken: command.NewCreatePersonalTokenHandler(s.pat),
+			RevokePersonalToken: command.NewRevokePersonalTokenHandler(s.pat),
+			RevokeSession:       command.NewRevokeSessionHandler(s.token),
+			LogoutEverywhere:    command.NewLogoutEverywhereHandler(s.token),
+			StartIdentityLink:   command.NewStartIdentityLinkHandler(s.linkRequest),
+			UnlinkIdentity:      command.NewUnlinkIdentityHandler(s.user, s.identity),
+			DeleteAccount: command.NewDeleteAccountHandler(
+				s.user, s.token, s.pat, s.link, reassignDeletedUserLinks(config.DeletedUserLinks),
+			),
+			TakeRateLimit: buildTakeRateLimit(&config.RateLimit, pool, logger),
+			DisableLink:   command.NewDisableLinkHandler(s.link, s.moderation, s.report),
+			EnableLink:    command.NewEnableLinkHandler(s.link, s.report),
+			BanUser:       command.NewBanUserHandler(s.user, s.token, s.pat),
+			UnbanUser:     command.NewUnbanUserHandler(s.user),
+			ReportLink: command.NewReportLinkHandler(
+				s.link, s.report, []byte(config.ClientHashSecret), config.Reports.AutoDisableThreshold, logger,
+			),
 		},
+		Query: buildAPIQuery(s, oauthProviders, challengeIssuer, logger),
 	}
+
+	return apiApp, []starter.Server{statsRecorder, safetyChecker}
 }
 
-func buildProviders(oauthConfig *OAuth, logger log.Logger) map[types.Provider]userdomain.OAuthProvider {
-	googleProvider := adapter.NewGoogleOAuthProvider(
-		oauthConfig.Google.ClientID,
-		oauthConfig.Google.ClientSecret,
-		oauthConfig.Google.RedirectURL,
-		logger,
+type storages struct {
+	link          link.Storage
+	alias         alias.Storage
+	user          userdomain.Storage
+	identity      identity.Storage
+	linkRequest   identity.LinkRequestStorage
+	token         tokendomain.Storage
+	pat           pat.Storage
+	stats         stats.Storage
+	captchaSpent  captcha.SpentStorage
+	moderation    moderation.Storage
+	report        report.Storage
+	codeGenerator hash.CodeGenerator
+	hashResolver  *linkhash.Resolver
+}
+
+func buildStorages(pool *pgxpool.Pool, config *Config) *storages {
+	linkStorage, aliasStorage := buildLinkStorages(pool, &config.LinkCache)
+	hashResolver := linkhash.NewResolver(
+		linkStorage, aliasStorage, buildHashGenerator(&config.Hash), config.Hash.Strategy == HashStrategyRandom,
 	)
-	githubProvider := adapter.NewGitHubOAuthProvider(
-		oauthConfig.Github.ClientID,
-		oauthConfig.Github.ClientSecret,
-		oauthConfig.Github.RedirectURL,
-		logger,
+
+	return &storages{
+		link:          linkStorage,
+		alias:         aliasStorage,
+		user:          adapter.NewUserStoragePgx(pool),
+		identity:      adapter.NewIdentityStoragePgx(pool),
+		linkRequest:   adapter.NewIdentityLinkRequestStoragePgx(pool),
+		token:         adapter.NewTokenStoragePgx(pool, []byte(config.TokenHashSecret)),
+		pat:           adapter.NewPersonalTokenStoragePgx(pool),
+		stats:         adapter.NewStatsStoragePgx(pool),
+		captchaSpent:  adapter.NewCaptchaSpentStoragePgx(pool),
+		moderation:    adapter.NewModerationStoragePgx(pool),
+		report:        adapter.NewReportStoragePgx(pool),
+		codeGenerator: buildCodeGenerator(&config.Hash),
+		hashResolver:  hashResolver,
+	}
+}
+
+func buildAPIQuery(
+	s *storages,
+	oauthProviders map[types.Provider]userdomain.OAuthProvider,
+	challengeIssuer captcha.ChallengeIssuer,
+	logger log.Logger,
+) app.APIQuery {
+	return app.APIQuery{
+		GetLinkByHash:         query.NewGetLinkByHashHandler(s.link, s.hashResolver, logger),
+		AuthUser:              query.NewAuthUserHandler(s.user, s.token, logger),
+		GetAuthURL:            query.NewGetAuthURLHandler(oauthProviders),
+		ListProviders:         query.NewListProvidersHandler(oauthProviders),
+		GetLinkStats:          query.NewGetLinkStatsHandler(s.link, s.stats, s.hashResolver),
+		ListUserLinks:         query.NewListUserLinksHandler(s.link, s.hashResolver),
+		AuthPersonalToken:     query.NewAuthPersonalTokenHandler(s.user, s.pat, logger),
+		ListPersonalTokens:    query.NewListPersonalTokensHandler(s.pat),
+		ListSessions:          query.NewListSessionsHandler(s.token),
+		ListIdentities:        query.NewListIdentitiesHandler(s.identity),
+		ExportAccount:         query.NewExportAccountHandler(s.user, s.identity, s.link, s.stats, s.hashResolver),
+		IssueCaptchaChallenge: query.NewIssueCaptchaChallengeHandler(challengeIssuer),
+		SearchLinks:           query.NewSearchLinksHandler(s.link, s.hashResolver),
+		ListModerationActions: query.NewListModerationActionsHandler(s.moderation),
+		ListOpenReports:       query.NewListOpenReportsHandler(s.report),
+	}
+}
+
+func buildHashGenerator(hashConfig *Hash) hash.Generator {
+	current := adapter.MustNewHashGenerator(adapter.HashOptions{
+		Alphabet:  hashConfig.Alphabet,
+		Blocklist: hashConfig.Blocklist,
+		MinLength: hashConfig.MinLength,
+	})
+
+	if hashConfig.LegacyMaxID == 0 {
+		return current
+	}
+
+	legacy := adapter.MustNewHashGenerator(adapter.LegacyHashOptions())
+
+	return adapter.NewMigratingHashGenerator(current, legacy, hashConfig.LegacyMaxID)
+}
+
+func buildLinkStorages(pool *pgxpool.Pool, cacheConfig *LinkCache) (link.Storage, alias.Storage) {
+	linkStorage := adapter.NewLinkStoragePgx(pool)
+	aliasStorage := adapter.NewAliasStoragePgx(pool)
+
+	if cacheConfig.Size == 0 {
+		return linkStorage, aliasStorage
+	}
+
+	lookups := gokitprometheus.NewCounterFrom(
+		nativeprometheus.CounterOpts{
+			Namespace: "truewebber",
+			Subsystem: "cache",
+			Name:      "lookups_total",
+			Help:      "Link cache lookups by cache and result.",
+		},
+		[]string{"cache", "result"},
 	)
-	appleProvider := adapter.NewAppleOAuthProvider(
-		oauthConfig.Apple.ClientID,
-		oauthConfig.Apple.RedirectURL,
-		oauthConfig.Apple.KeyID,
-		oauthConfig.Apple.TeamID,
-		oauthConfig.Apple.PrivateKey,
-		logger,
+
+	options := adapter.CacheOptions{
+		Size:        cacheConfig.Size,
+		TTL:         cacheConfig.TTL,
+		NegativeTTL: cacheConfig.NegativeTTL,
+	}
+
+	return adapter.NewCachedLinkStorage(linkStorage, options, lookups.With("cache", "links")),
+		adapter.NewCachedAliasStorage(aliasStorage, options, lookups.With("cache", "aliases"))
+}
+
+// buildCodeGenerator returns nil when short hashes are derived from link ids.
+func buildCodeGenerator(hashConfig *Hash) hash.CodeGenerator {
+	switch hashConfig.Strategy {
+	case HashStrategySqids:
+		return nil
+	case HashStrategyRandom:
+		return adapter.MustNewRandomCodeGenerator(hashConfig.RandomLength)
+	}
+
+	panic(fmt.Sprintf("unknown hash strategy: %q", hashConfig.Strategy))
+}
+
+// buildCaptcha returns a nil issuer for captchas that need no challenge from the server.
+func buildCaptcha(
+	config *Captcha, spentStorage captcha.SpentStorage, logger log.Logger,
+) (captcha.Validator, captcha.ChallengeIssuer) {
+	switch config.Provider {
+	case CaptchaProviderRecaptcha:
+		return adapter.NewGoogleCaptchaV3Validator(
+			config.GoogleCaptchaV3.VerifyURL,
+			config.GoogleCaptchaV3.Secret,
+			config.GoogleCaptchaV3.AllowedActions,
+			config.GoogleCaptchaV3.Threshold,
+			logger,
+		), nil
+	case CaptchaProviderHCaptcha:
+		return adapter.NewHCaptchaValidator(config.HCaptcha.VerifyURL, config.HCaptcha.Secret, logger), nil
+	case CaptchaProviderTurnstile:
+		return adapter.NewTurnstileValidator(
+			config.Turnstile.VerifyURL, config.Turnstile.Secret, config.Turnstile.AllowedActions, logger,
+		), nil
+	case CaptchaProviderPow:
+		powCaptcha := adapter.NewPowCaptcha(adapter.PowCaptchaOptions{
+			Secret:        []byte(config.Pow.Secret),
+			TTL:           config.Pow.TTL,
+			MinDifficulty: config.Pow.MinDifficulty,
+			MaxDifficulty: config.Pow.MaxDifficulty,
+			LoadStep:      config.Pow.LoadStep,
+		}, spentStorage)
+
+		return powCaptcha, powCaptcha
+	}
+
+	panic(fmt.Sprintf("unknown captcha provider: %q", config.Provider))
+}
+
+func buildSafetyChecker(config *Safety, logger log.Logger) adapter.SafetyCheckerServer {
+	return adapter.MustNewBlocklistChecker(adapter.BlocklistOptions{
+		DomainsFile:    config.DomainsFile,
+		URLPrefixFile:  config.URLPrefixFile,
+		PatternsFile:   config.PatternsFile,
+		ReloadInterval: config.ReloadInterval,
+	}, logger)
+}
+
+// buildTakeRateLimit keeps buckets in Postgres only when replicas have to share them.
+func buildTakeRateLimit(config *RateLimit, pool *pgxpool.Pool, logger log.Logger) *command.TakeRateLimitHandler {
+	var store ratelimit.Store
+
+	switch config.Store {
+	case RateLimitStoreMemory:
+		store = adapter.NewMemoryRateLimitStore()
+	case RateLimitStorePostgres:
+		store = adapter.NewRateLimitStorePgx(pool)
+	default:
+		panic(fmt.Sprintf("unknown rate limit store: %q", config.Store))
+	}
+
+	requests := gokitprometheus.NewCounterFrom(
+		nativeprometheus.CounterOpts{
+			Namespace: "truewebber",
+			Subsystem: "rate_limit",
+			Name:      "requests_total",
+			Help:      "Rate limited requests by route class and result.",
+		},
+		[]string{"class", "result"},
 	)
 
-	return map[types.Provider]userdomain.OAuthProvider{
-		types.ProviderApple:  appleProvider,
-		types.ProviderGoogle: googleProvider,
-		types.ProviderGithub: githubProvider,
+	limits := map[types.RateLimitClass]ratelimit.Limit{
+		types.RateLimitClassRedirect:  ratelimit.Limit(config.Redirect),
+		types.RateLimitClassAnonymous: ratelimit.Limit(config.Anonymous),
+		types.RateLimitClassAPI:       ratelimit.Limit(config.API),
+		types.RateLimitClassAuth:      ratelimit.Limit(config.Auth),
+	}
+
+	return command.NewTakeRateLimitHandler(store, limits, requests, logger)
+}
+
+func reassignDeletedUserLinks(policy DeletedUserLinks) bool {
+	switch policy {
+	case DeletedUserLinksDelete:
+		return false
+	case DeletedUserLinksReassign:
+		return true
 	}
+
+	panic(fmt.Sprintf("unknown deleted user links policy: %q", policy))
+}
+
+// buildProviders enables only the providers whose client id is configured.
+func buildProviders(oauthConfig *OAuth, logger log.Logger) map[types.Provider]userdomain.OAuthProvider {
+	providers := make(map[types.Provider]userdomain.OAuthProvider)
+
+	if oauthConfig.Google.ClientID != "" {
+		providers[types.ProviderGoogle] = adapter.NewGoogleOAuthProvider(
+			oauthConfig.Google.ClientID,
+			oauthConfig.Google.ClientSecret,
+			oauthConfig.Google.RedirectURL,
+			logger,
+		)
+	}
+
+	if oauthConfig.Github.ClientID != "" {
+		providers[types.ProviderGithub] = adapter.NewGitHubOAuthProvider(
+			oauthConfig.Github.ClientID,
+			oauthConfig.Github.ClientSecret,
+			oauthConfig.Github.RedirectURL,
+			logger,
+		)
+	}
+
+	if oauthConfig.Apple.ClientID != "" {
+		providers[types.ProviderApple] = adapter.NewAppleOAuthProvider(
+			oauthConfig.Apple.ClientID,
+			oauthConfig.Apple.RedirectURL,
+			oauthConfig.Apple.KeyID,
+			oauthConfig.Apple.TeamID,
+			oauthConfig.Apple.PrivateKey,
+			logger,
+		)
+	}
+
+	for i := range oauthConfig.OIDC {
+		oidc := &oauthConfig.OIDC[i]
+
+		providers[types.OIDCProvider(oidc.Slot)] = adapter.NewOIDCOAuthProvider(adapter.OIDCOptions{
+			IssuerURL:    oidc.IssuerURL,
+			ClientID:     oidc.ClientID,
+			ClientSecret: oidc.ClientSecret,
+			RedirectURL:  oidc.RedirectURL,
+			Scopes:       oidc.Scopes,
+			Provider:     userdomain.OIDCProvider(oidc.Slot),
+		}, logger)
+	}
+
+	return providers
 }
 
 type Config struct {
 	PostgresConnectionString string
+	TokenHashSecret          string
+	ClientHashSecret         string
 	OAuth                    OAuth
-	GoogleCaptchaV3          GoogleCaptchaV3
+	Captcha                  Captcha
+	Stats                    Stats
+	Hash                     Hash
+	LinkCache                LinkCache
+	DeletedUserLinks         DeletedUserLinks
+	RateLimit                RateLimit
+	Safety                   Safety
+	Reports                  Reports
 }
 
 type OAuth struct {
 	Google, Github Standard
 	Apple          Apple
+	OIDC           []OIDC
 }
 
 type Apple struct {
@@ -93,8 +368,114 @@ type Standard struct {
 	ClientID, ClientSecret, RedirectURL string
 }
 
+// MaxOIDCProviders bounds the Slot of an OIDC provider.
+const MaxOIDCProviders = userdomain.MaxOIDCProviders
+
+// OIDC is a configured OpenID Connect provider, Slot is stored with its identities and must stay with the issuer.
+type OIDC struct {
+	IssuerURL, ClientID, ClientSecret, RedirectURL string
+	Scopes                                         []string
+	Slot                                           uint8
+}
+
+// GoogleCaptchaV3, HCaptcha and Turnstile use the public verify endpoint when VerifyURL is empty.
 type GoogleCaptchaV3 struct {
+	VerifyURL      string
 	Secret         string
 	AllowedActions []string
 	Threshold      float32
 }
+
+type HCaptcha struct {
+	VerifyURL, Secret string
+}
+
+type Turnstile struct {
+	VerifyURL, Secret string
+	AllowedActions    []string
+}
+
+type Captcha struct {
+	Provider        CaptchaProvider
+	GoogleCaptchaV3 GoogleCaptchaV3
+	HCaptcha        HCaptcha
+	Turnstile       Turnstile
+	Pow             PowCaptcha
+}
+
+type CaptchaProvider string
+
+const (
+	CaptchaProviderRecaptcha CaptchaProvider = "recaptcha"
+	CaptchaProviderHCaptcha  CaptchaProvider = "hcaptcha"
+	CaptchaProviderTurnstile CaptchaProvider = "turnstile"
+	CaptchaProviderPow       CaptchaProvider = "pow"
+)
+
+type PowCaptcha struct {
+	Secret                                 string
+	TTL                                    time.Duration
+	MinDifficulty, MaxDifficulty, LoadStep uint64
+}
+
+type HashStrategy string
+
+const (
+	HashStrategySqids  HashStrategy = "sqids"
+	HashStrategyRandom HashStrategy = "random"
+)
+
+type DeletedUserLinks string
+
+const (
+	DeletedUserLinksDelete   DeletedUserLinks = "delete"
+	DeletedUserLinksReassign DeletedUserLinks = "reassign"
+)
+
+type RateLimitStore string
+
+const (
+	RateLimitStoreMemory   RateLimitStore = "memory"
+	RateLimitStorePostgres RateLimitStore = "postgres"
+)
+
+// RequestLimit lets Burst requests through every Period, a zero Burst turns the limit off.
+type RequestLimit struct {
+	Period time.Duration
+	Burst  uint32
+}
+
+type RateLimit struct {
+	Store                          RateLimitStore
+	Redirect, Anonymous, API, Auth RequestLimit
+}
+
+// Safety leaves a blocklist out when its file is empty.
+type Safety struct {
+	DomainsFile, URLPrefixFile, PatternsFile string
+	ReloadInterval                           time.Duration
+}
+
+// Reports disables a link once AutoDisableThreshold distinct reporters flag it, zero keeps it to the moderators.
+type Reports struct {
+	AutoDisableThreshold uint32
+}
+
+type Hash struct {
+	Alphabet     string
+	Strategy     HashStrategy
+	Blocklist    []string
+	LegacyMaxID  uint64
+	RandomLength int
+	MinLength    uint8
+}
+
+type LinkCache struct {
+	Size             int
+	TTL, NegativeTTL time.Duration
+}
+
+type Stats struct {
+	BufferSize, BatchSize int
+	FlushInterval         time.Duration
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+
+	"github.com/truewebber/gopkg/log"
+	"github.com/truewebber/gopkg/starter"
+
+	"github.com/truewebber/link-shortener/adapter"
+	"github.com/truewebber/link-shortener/app"
+	"github.com/truewebber/link-shortener/app/command"
+)
+
+func NewCleanerApp(config *CleanerConfig, logger log.Logger) (*app.CleanerApp, []starter.Server) {
+	pool := adapter.MustNewPgxPool(context.Background(), config.PostgresConnectionString)
+
+	linkStorage := adapter.NewLinkStoragePgx(pool)
+	// the cleaner never looks tokens up by value, so it needs no hash key
+	tokenStorage := adapter.NewTokenStoragePgx(pool, nil)
+	captchaSpentStorage := adapter.NewCaptchaSpentStoragePgx(pool)
+	rateLimitStore := adapter.NewRateLimitStorePgx(pool)
+	linkRequestStorage := adapter.NewIdentityLinkRequestStoragePgx(pool)
+	locker := adapter.NewAdvisoryLockerPgx(pool)
+	safetyChecker := buildSafetyChecker(&config.Safety, logger)
+
+	cleanerApp := &app.CleanerApp{
+		Command: app.CleanerCommand{
+			CleanExpired: command.NewCleanExpiredHandler(
+				linkStorage, tokenStorage, captchaSpentStorage, rateLimitStore, linkRequestStorage, locker, logger,
+			),
+			RescanLinks: command.NewRescanLinksHandler(linkStorage, safetyChecker, locker, logger),
+		},
+	}
+
+	return cleanerApp, []starter.Server{safetyChecker}
+}
+
+type CleanerConfig struct {
+	PostgresConnectionString string
+	Safety                   Safety
+}
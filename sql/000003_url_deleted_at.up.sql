@@ -0,0 +1,4 @@
+ALTER TABLE urls
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+UPDATE urls SET deleted_at = updated_at WHERE deleted AND deleted_at IS NULL;
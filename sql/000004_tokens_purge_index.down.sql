@@ -0,0 +1 @@
+DROP INDEX IF EXISTS tokens__refresh_token_expires_at__all__idx;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS tokens__refresh_token_expires_at__all__idx
+    ON tokens (refresh_token_expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE url_stats
+    DROP COLUMN IF EXISTS visitor_hash;
@@ -0,0 +1,2 @@
+ALTER TABLE url_stats
+    ADD COLUMN IF NOT EXISTS visitor_hash VARCHAR NOT NULL DEFAULT '';
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS url_aliases
+(
+    id         BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    url_id     BIGINT    NOT NULL REFERENCES urls (id),
+    alias      VARCHAR   NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted    BOOLEAN   NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS url_aliases__alias__udx
+    ON url_aliases (alias)
+    WHERE NOT deleted;
+
+CREATE INDEX IF NOT EXISTS url_aliases__url_id__idx
+    ON url_aliases (url_id)
+    WHERE NOT deleted;
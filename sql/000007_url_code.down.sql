@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS urls__code__udx;
+
+ALTER TABLE urls
+    DROP COLUMN IF EXISTS code;
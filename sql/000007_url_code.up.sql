@@ -0,0 +1,6 @@
+ALTER TABLE urls
+    ADD COLUMN IF NOT EXISTS code VARCHAR(32);
+
+CREATE UNIQUE INDEX IF NOT EXISTS urls__code__udx
+    ON urls (code)
+    WHERE code IS NOT NULL;
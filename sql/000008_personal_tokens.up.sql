@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS personal_tokens
+(
+    id           BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id      BIGINT    NOT NULL REFERENCES users (id),
+    name         VARCHAR   NOT NULL,
+    secret_hash  VARCHAR   NOT NULL,
+    scopes       VARCHAR[] NOT NULL,
+    expires_at   TIMESTAMP,
+    last_used_at TIMESTAMP,
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted      BOOLEAN   NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS personal_tokens__secret_hash__udx
+    ON personal_tokens (secret_hash);
+
+CREATE INDEX IF NOT EXISTS personal_tokens__user_id__idx
+    ON personal_tokens (user_id)
+    WHERE NOT deleted;
@@ -0,0 +1,2 @@
+-- plaintext tokens can not be recovered, revoked sessions stay revoked.
+SELECT 1;
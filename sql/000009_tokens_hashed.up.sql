@@ -0,0 +1,7 @@
+-- tokens are stored as HMAC-SHA256 from now on and the key is not known here,
+-- so existing sessions are revoked and their plaintext values are overwritten.
+UPDATE tokens
+SET access_token  = encode(sha256(convert_to(access_token, 'UTF8')), 'hex'),
+    refresh_token = encode(sha256(convert_to(refresh_token, 'UTF8')), 'hex'),
+    deleted       = true,
+    updated_at    = CURRENT_TIMESTAMP;
@@ -0,0 +1,4 @@
+ALTER TABLE tokens
+    DROP COLUMN IF EXISTS last_used_at,
+    DROP COLUMN IF EXISTS ip,
+    DROP COLUMN IF EXISTS user_agent;
@@ -0,0 +1,4 @@
+ALTER TABLE tokens
+    ADD COLUMN IF NOT EXISTS user_agent   VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS ip           VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP;
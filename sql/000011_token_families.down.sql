@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS tokens__family_id__idx;
+
+ALTER TABLE tokens
+    DROP COLUMN IF EXISTS rotated_at,
+    DROP COLUMN IF EXISTS parent_id,
+    DROP COLUMN IF EXISTS family_id;
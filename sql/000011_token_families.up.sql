@@ -0,0 +1,9 @@
+-- family_id and parent_id stay NULL for the first token of a login, its family is its own id.
+ALTER TABLE tokens
+    ADD COLUMN IF NOT EXISTS family_id  BIGINT,
+    ADD COLUMN IF NOT EXISTS parent_id  BIGINT,
+    ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS tokens__family_id__idx
+    ON tokens (family_id)
+    WHERE family_id IS NOT NULL;
@@ -0,0 +1,18 @@
+-- every user signs in with a single identity again, the oldest one it still has; identities are unique,
+-- so are the rebuilt users columns, the other linked identities are lost.
+UPDATE users AS u
+SET provider_type       = i.provider_type,
+    provider_user_id    = i.provider_user_id,
+    provider_user_email = i.email,
+    provider_user_name  = i.name,
+    provider_avatar_url = i.avatar_url
+FROM (SELECT DISTINCT ON (user_id) user_id, provider_type, provider_user_id, email, name, avatar_url
+      FROM identities
+      ORDER BY user_id, created_at, id) AS i
+WHERE i.user_id = u.id
+  AND NOT u.deleted;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users__provider_type__provider_user_id__udx
+    ON users (provider_type, provider_user_id);
+
+DROP TABLE IF EXISTS identities;
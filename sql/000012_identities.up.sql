@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS identities
+(
+    id               BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id          BIGINT    NOT NULL REFERENCES users (id),
+    provider_type    SMALLINT  NOT NULL,
+    provider_user_id VARCHAR   NOT NULL,
+    email            VARCHAR   NOT NULL,
+    name             VARCHAR   NOT NULL,
+    avatar_url       VARCHAR   NOT NULL,
+    created_at       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS identities__provider_type__provider_user_id__udx
+    ON identities (provider_type, provider_user_id);
+
+CREATE INDEX IF NOT EXISTS identities__user_id__idx
+    ON identities (user_id);
+
+-- the anonymous user can not sign in, every other user keeps the identity it was created with.
+INSERT INTO identities (user_id, provider_type, provider_user_id, email, name, avatar_url, created_at, updated_at)
+SELECT id,
+       provider_type,
+       provider_user_id,
+       provider_user_email,
+       provider_user_name,
+       provider_avatar_url,
+       created_at,
+       updated_at
+FROM users
+WHERE NOT deleted
+  AND provider_type <> 1;
+
+-- users.provider_* keep the profile of one identity of the user, identities are unique instead.
+DROP INDEX IF EXISTS users__provider_type__provider_user_id__udx;
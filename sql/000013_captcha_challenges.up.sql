@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS captcha_challenges
+(
+    challenge  VARCHAR   PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS captcha_challenges__expires_at__idx
+    ON captcha_challenges (expires_at);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS rate_limits
+(
+    key     VARCHAR   PRIMARY KEY,
+    full_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS rate_limits__full_at__idx
+    ON rate_limits (full_at);
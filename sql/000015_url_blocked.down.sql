@@ -0,0 +1,3 @@
+ALTER TABLE urls
+    DROP COLUMN IF EXISTS blocked_reason,
+    DROP COLUMN IF EXISTS blocked_at;
@@ -0,0 +1,3 @@
+ALTER TABLE urls
+    ADD COLUMN IF NOT EXISTS blocked_at     TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS blocked_reason VARCHAR;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS moderation_actions;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS banned_at,
+    DROP COLUMN IF EXISTS role;
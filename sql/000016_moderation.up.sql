@@ -0,0 +1,17 @@
+-- admins are granted by hand: UPDATE users SET role = 'admin' WHERE id = ...;
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role      VARCHAR NOT NULL DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS banned_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS moderation_actions
+(
+    id           BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    moderator_id BIGINT    NOT NULL REFERENCES users (id),
+    action       VARCHAR   NOT NULL,
+    target_id    BIGINT    NOT NULL,
+    reason       VARCHAR   NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS moderation_actions__created_at__idx
+    ON moderation_actions (created_at);
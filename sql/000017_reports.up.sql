@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS reports
+(
+    id          BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    link_id     BIGINT    NOT NULL REFERENCES urls (id),
+    reporter_id VARCHAR   NOT NULL,
+    reason      VARCHAR   NOT NULL,
+    contact     VARCHAR   NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS reports__link_id__reporter_id__udx
+    ON reports (link_id, reporter_id)
+    WHERE resolved_at IS NULL;
@@ -0,0 +1,2 @@
+-- freed aliases may have been taken again, they stay freed.
+SELECT 1;
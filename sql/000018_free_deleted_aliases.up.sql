@@ -0,0 +1,4 @@
+UPDATE url_aliases
+SET deleted = true
+WHERE NOT deleted
+  AND url_id IN (SELECT id FROM urls WHERE deleted);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS identity_link_requests
+(
+    nonce_hash VARCHAR   PRIMARY KEY,
+    user_id    BIGINT    NOT NULL REFERENCES users (id),
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS identity_link_requests__expires_at__idx
+    ON identity_link_requests (expires_at);